@@ -0,0 +1,101 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/leejonesio/ttyhop/internal/hyprland"
+	"github.com/leejonesio/ttyhop/internal/i3ipc"
+	"github.com/leejonesio/ttyhop/internal/niri"
+)
+
+// backend is a window backend compiled into this binary. The per-platform
+// backends list holds them in detection order.
+type backend struct {
+	name string
+	// detect says why the environment points at this backend, or returns
+	// "" when it doesn't.
+	detect func() string
+	open   func() Hopper
+}
+
+var (
+	swayBackend = backend{"sway", func() string {
+		switch {
+		case os.Getenv("SWAYSOCK") != "":
+			return "SWAYSOCK is set"
+		case i3ipc.SocketPath() != "":
+			return "I3SOCK is set"
+		}
+		return ""
+	}, func() Hopper { return newSwayHopper() }}
+
+	hyprlandBackend = backend{"hyprland", func() string {
+		if hyprland.SocketPath() != "" {
+			return "HYPRLAND_INSTANCE_SIGNATURE is set"
+		}
+		return ""
+	}, func() Hopper { return newHyprlandHopper() }}
+
+	niriBackend = backend{"niri", func() string {
+		if niri.SocketPath() != "" {
+			return "NIRI_SOCKET is set"
+		}
+		return ""
+	}, func() Hopper { return newNiriHopper() }}
+
+	// x11Backend also covers Xwayland when a Wayland compositor has no
+	// backend of its own; only its X clients are visible then.
+	x11Backend = backend{"x11", func() string {
+		switch {
+		case os.Getenv("DISPLAY") == "":
+			return ""
+		case os.Getenv("WAYLAND_DISPLAY") != "":
+			return "WAYLAND_DISPLAY is set without a supported compositor socket; using Xwayland on DISPLAY"
+		}
+		return "DISPLAY is set"
+	}, func() Hopper { return newX11Hopper() }}
+
+	tmuxBackend = backend{"tmux", func() string {
+		if os.Getenv("WAYLAND_DISPLAY") != "" {
+			return "WAYLAND_DISPLAY is set without a supported compositor socket or DISPLAY; tmux panes only"
+		}
+		return "no display server found; tmux panes only"
+	}, func() Hopper { return &tmuxHopper{} }}
+
+	// simBackend replays a --layout file; it is never detected.
+	simBackend = backend{"sim", func() string { return "" }, func() Hopper { return newSimHopper() }}
+)
+
+// backendNames lists the compiled-in backends in detection order.
+func backendNames() string {
+	names := make([]string, len(backends))
+	for i, b := range backends {
+		names[i] = b.name
+	}
+	return strings.Join(names, ", ")
+}
+
+// pickBackend returns the backend called name along with why it was
+// chosen. An empty name or "auto" picks the first backend whose detect
+// fires.
+func pickBackend(name string) (backend, string, error) {
+	if name == "" || name == "auto" {
+		for _, b := range backends {
+			if reason := b.detect(); reason != "" {
+				return b, reason, nil
+			}
+		}
+		return backend{}, "", fmt.Errorf("no backend matches this environment (have: %s)", backendNames())
+	}
+	for _, b := range backends {
+		if b.name == name {
+			return b, "requested", nil
+		}
+	}
+	return backend{}, "", fmt.Errorf("unknown backend %q (have: %s)", name, backendNames())
+}
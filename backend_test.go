@@ -0,0 +1,65 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package main
+
+import (
+	"testing"
+)
+
+func TestPickBackend(t *testing.T) {
+	// Pin the Linux order so the test reads the same on macOS.
+	originalBackends := backends
+	defer func() { backends = originalBackends }()
+	backends = []backend{swayBackend, hyprlandBackend, niriBackend, x11Backend, tmuxBackend, simBackend}
+
+	vars := []string{"SWAYSOCK", "I3SOCK", "HYPRLAND_INSTANCE_SIGNATURE", "NIRI_SOCKET", "WAYLAND_DISPLAY", "DISPLAY"}
+	tests := []struct {
+		name   string
+		env    map[string]string
+		flag   string
+		want   string
+		reason string
+	}{
+		{"Sway", map[string]string{"SWAYSOCK": "/run/sway.sock", "WAYLAND_DISPLAY": "wayland-1", "DISPLAY": ":0"}, "", "sway", "SWAYSOCK is set"},
+		{"I3", map[string]string{"I3SOCK": "/run/i3.sock", "DISPLAY": ":0"}, "auto", "sway", "I3SOCK is set"},
+		{"Hyprland", map[string]string{"HYPRLAND_INSTANCE_SIGNATURE": "abc", "WAYLAND_DISPLAY": "wayland-1"}, "", "hyprland", "HYPRLAND_INSTANCE_SIGNATURE is set"},
+		{"Niri", map[string]string{"NIRI_SOCKET": "/run/niri.sock", "WAYLAND_DISPLAY": "wayland-1"}, "", "niri", "NIRI_SOCKET is set"},
+		{"X11", map[string]string{"DISPLAY": ":0"}, "", "x11", "DISPLAY is set"},
+		{"Xwayland", map[string]string{"WAYLAND_DISPLAY": "wayland-0", "DISPLAY": ":1"}, "", "x11",
+			"WAYLAND_DISPLAY is set without a supported compositor socket; using Xwayland on DISPLAY"},
+		{"WaylandOnly", map[string]string{"WAYLAND_DISPLAY": "wayland-0"}, "", "tmux",
+			"WAYLAND_DISPLAY is set without a supported compositor socket or DISPLAY; tmux panes only"},
+		{"Nothing", nil, "", "tmux", "no display server found; tmux panes only"},
+		{"Requested", map[string]string{"SWAYSOCK": "/run/sway.sock"}, "x11", "x11", "requested"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, v := range vars {
+				t.Setenv(v, tt.env[v])
+			}
+			b, reason, err := pickBackend(tt.flag)
+			if err != nil || b.name != tt.want || reason != tt.reason {
+				t.Errorf("got %q (%q, %v), want %q (%q)", b.name, reason, err, tt.want, tt.reason)
+			}
+		})
+	}
+
+	if _, _, err := pickBackend("wayland"); err == nil {
+		t.Error("expected an unknown backend to be rejected")
+	}
+	if got := backendNames(); got != "sway, hyprland, niri, x11, tmux, sim" {
+		t.Errorf("unexpected backend list %q", got)
+	}
+}
+
+func TestRunBackendFlag(t *testing.T) {
+	t.Setenv("TTYHOP_CONFIG", "/nonexistent")
+	if rc := run(nil, []string{"--backend", "psychic", "r"}); rc != 64 {
+		t.Errorf("expected rc 64 for an unknown backend, got %d", rc)
+	}
+	t.Setenv("TTYHOP_BACKEND", "psychic")
+	if rc := run(nil, []string{"r"}); rc != 64 {
+		t.Errorf("expected rc 64 for an unknown $TTYHOP_BACKEND, got %d", rc)
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// configPath returns the config file location: $TTYHOP_CONFIG, else
+// $XDG_CONFIG_HOME/ttyhop/config, else ~/.config/ttyhop/config.
+func configPath() string {
+	if p := os.Getenv("TTYHOP_CONFIG"); p != "" {
+		return p
+	}
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "ttyhop", "config")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "ttyhop", "config")
+}
+
+// loadConfigArgs reads a config file and turns it into flag arguments, so
+// every long flag can also be set from the file:
+//
+//	# ~/.config/ttyhop/config
+//	strategy = edge
+//	wait-ms = 300
+//	no-edge
+//
+// A missing file is not an error.
+func loadConfigArgs(path string) ([]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	var args []string
+	sc := bufio.NewScanner(f)
+	for n := 1; sc.Scan(); n++ {
+		ln := strings.TrimSpace(sc.Text())
+		if ln == "" || strings.HasPrefix(ln, "#") {
+			continue
+		}
+		key, val, hasVal := strings.Cut(ln, "=")
+		key = strings.TrimSpace(key)
+		if key == "" || strings.HasPrefix(key, "-") {
+			return nil, fmt.Errorf("%s:%d: expected 'key = value'", path, n)
+		}
+		if !hasVal {
+			args = append(args, "--"+key)
+			continue
+		}
+		args = append(args, "--"+key+"="+strings.TrimSpace(val))
+	}
+	return args, sc.Err()
+}
+
+// envFlags maps environment variables to the flags they set. They override
+// the config file and are overridden by the command line.
+var envFlags = map[string]string{
+	"TTYHOP_STRATEGY":    "strategy",
+	"TTYHOP_WRAP":        "wrap",
+	"TTYHOP_WRAP_PANES":  "wrap-panes",
+	"TTYHOP_ZOOM":        "zoom",
+	"TTYHOP_TMUX_SOCKET": "tmux-socket",
+	"TTYHOP_BACKEND":     "backend",
+	"TTYHOP_TERMINALS":   "terminals",
+	"TTYHOP_CHAIN":       "chain",
+}
+
+func applyEnvFlags(fs *flag.FlagSet) error {
+	for env, name := range envFlags {
+		if v := os.Getenv(env); v != "" {
+			if err := fs.Set(name, v); err != nil {
+				return fmt.Errorf("%s: %w", env, err)
+			}
+		}
+	}
+	return nil
+}
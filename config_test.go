@@ -0,0 +1,58 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadConfigArgs(t *testing.T) {
+	t.Run("Missing", func(t *testing.T) {
+		args, err := loadConfigArgs(filepath.Join(t.TempDir(), "nope"))
+		if err != nil || args != nil {
+			t.Errorf("expected no args and no error, got %v, %v", args, err)
+		}
+	})
+
+	t.Run("KeysValuesAndBools", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config")
+		contents := "# comment\n\nstrategy = edge\nwait-ms=300\n  no-edge  \n"
+		if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		args, err := loadConfigArgs(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := []string{"--strategy=edge", "--wait-ms=300", "--no-edge"}
+		if !reflect.DeepEqual(args, want) {
+			t.Errorf("expected %q, got %q", want, args)
+		}
+	})
+
+	t.Run("RejectsDashedKeys", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config")
+		if err := os.WriteFile(path, []byte("--strategy = edge\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := loadConfigArgs(path); err == nil {
+			t.Error("expected an error for a dashed key")
+		}
+	})
+}
+
+func TestConfigPath(t *testing.T) {
+	t.Setenv("TTYHOP_CONFIG", "/etc/ttyhop.conf")
+	if got := configPath(); got != "/etc/ttyhop.conf" {
+		t.Errorf("expected TTYHOP_CONFIG to win, got %q", got)
+	}
+	t.Setenv("TTYHOP_CONFIG", "")
+	t.Setenv("XDG_CONFIG_HOME", "/xdg")
+	if got := configPath(); got != filepath.Join("/xdg", "ttyhop", "config") {
+		t.Errorf("expected XDG path, got %q", got)
+	}
+}
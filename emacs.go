@@ -0,0 +1,167 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/leejonesio/ttyhop/internal/neighbor"
+)
+
+// ---------- Emacs windows ----------
+//
+// Emacs is steered through its server with "emacsclient --eval", which runs
+// windmove in the frame ttyhop acts on: the frame on the active tmux pane's
+// tty, or else a focused GUI frame. windmove signals an error at the edge,
+// so the expression returns t when it moved and nil when it didn't.
+
+// emacsWindmove is the windmove command that moves in direction dir.
+func emacsWindmove(dir Direction) string {
+	return map[Direction]string{DirLeft: "windmove-left", DirRight: "windmove-right", DirUp: "windmove-up", DirDown: "windmove-down"}[dir]
+}
+
+// runEmacsclient evaluates expr in the Emacs server and returns what it
+// printed.
+func runEmacsclient(expr string) (string, error) {
+	cmd := exec.Command("emacsclient", "--eval", expr)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if err := cmd.Run(); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out.String()), nil
+}
+
+// emacsSocket is the server socket emacsclient connects to by default:
+// $EMACS_SOCKET_NAME, else "server" in Emacs's server-socket-dir.
+func emacsSocket() string {
+	if s := os.Getenv("EMACS_SOCKET_NAME"); s != "" {
+		return s
+	}
+	if d := os.Getenv("XDG_RUNTIME_DIR"); d != "" {
+		return filepath.Join(d, "emacs", "server")
+	}
+	tmp := os.Getenv("TMPDIR")
+	if tmp == "" {
+		tmp = "/tmp"
+	}
+	return filepath.Join(tmp, fmt.Sprintf("emacs%d", os.Getuid()), "server")
+}
+
+// emacsPaneTTY returns the tty of pane of the tmux level l (its current
+// pane when pane is "") when it runs emacs (or emacsclient), else "".
+func emacsPaneTTY(l tmuxLevel, pane string) string {
+	var out string
+	var err error
+	if pane == "" {
+		out, err = l.display("#{pane_current_command} #{pane_tty}")
+	} else {
+		out, err = l.server.run("display", "-p", "-t", pane, "#{pane_current_command} #{pane_tty}")
+	}
+	f := strings.Fields(out)
+	if err != nil || len(f) < 2 || !strings.HasPrefix(f[0], "emacs") {
+		return ""
+	}
+	return f[len(f)-1]
+}
+
+// emacsMoveExpr is the expression moving in dir from the selected window,
+// with wrap-around off so the edge is reported instead of wrapped past.
+func emacsMoveExpr(dir Direction) string {
+	return fmt.Sprintf("(condition-case nil (let ((windmove-wrap-around nil)) (%s) t) (error nil))", emacsWindmove(dir))
+}
+
+// emacsLandExpr moves from the selected window as far as it goes in dir.
+func emacsLandExpr(dir Direction) string {
+	return fmt.Sprintf("(let ((windmove-wrap-around nil)) (while (ignore-errors (%s) t)))", emacsWindmove(dir))
+}
+
+// emacsTTYExpr evaluates body within the frame on tty. An "emacs -nw"
+// frame lives on the "/dev/tty" terminal, so it is matched through the tty
+// of the Emacs process itself.
+func emacsTTYExpr(tty, body string) string {
+	return fmt.Sprintf(`(let ((tty %q) f)
+  (dolist (fr (frame-list))
+    (let ((name (terminal-name (frame-terminal fr))))
+      (when (or (equal name tty)
+                (and (equal name "/dev/tty")
+                     (equal (alist-get 'ttname (process-attributes (emacs-pid))) tty)))
+        (setq f fr))))
+  (and f (with-selected-frame f %s)))`, tty, body)
+}
+
+// emacsGUIExpr moves in dir when the selected frame is a focused GUI frame.
+func emacsGUIExpr(dir Direction) string {
+	return fmt.Sprintf("(and (display-graphic-p) (frame-focus-state) %s)", emacsMoveExpr(dir))
+}
+
+// emacsTryMove moves to the Emacs window next to the selected one in dir;
+// return true if moved. Inside tmux only an Emacs in the active pane is
+// tried; outside it, a focused GUI frame of a running server.
+func emacsTryMove(dir Direction) bool {
+	var expr string
+	if os.Getenv("TMUX") != "" {
+		levels := tmuxNesting()
+		tty := emacsPaneTTY(levels[len(levels)-1], "")
+		if tty == "" {
+			return false
+		}
+		expr = emacsTTYExpr(tty, emacsMoveExpr(dir))
+	} else {
+		if _, err := os.Stat(emacsSocket()); err != nil {
+			return false
+		}
+		expr = emacsGUIExpr(dir)
+	}
+	out, err := runEmacsclient(expr)
+	if err != nil {
+		dbg("emacs: emacsclient: %v", err)
+		return false
+	}
+	if out != "t" {
+		dbg("emacs: at %s edge", dir)
+		return false
+	}
+	dbg("emacs: window move %s", dir)
+	return true
+}
+
+// emacsSelectEdgeWindow selects the edge window of the Emacs frame in pane
+// of the tmux level l (its current pane when pane is ""): the LEFTMOST when
+// moving right, the TOPMOST when moving down, and so on.
+func emacsSelectEdgeWindow(l tmuxLevel, pane string, dir Direction) {
+	tty := emacsPaneTTY(l, pane)
+	if tty == "" {
+		return
+	}
+	if _, err := runEmacsclient(emacsTTYExpr(tty, emacsLandExpr(dir.Opposite()))); err != nil {
+		dbg("emacs: emacsclient: %v", err)
+		return
+	}
+	dbg("emacs: landed on %s edge window", dir.Opposite())
+}
+
+// emacsNavigator moves between Emacs windows, and lands a window hop on
+// the edge window of the Emacs in the tmux pane it landed on.
+type emacsNavigator struct {
+	opts  hopOptions
+	state *hopState
+}
+
+func (n *emacsNavigator) Try(dir Direction) bool { return emacsTryMove(dir) }
+
+func (n *emacsNavigator) recordOrigin(Direction, neighbor.Rect, neighbor.Candidate) {
+	n.state.recordTmuxOrigin(n.opts)
+}
+
+func (n *emacsNavigator) LandAtEdge(dir Direction) {
+	if p, ok := n.state.landedTmuxPane(n.opts); ok {
+		emacsSelectEdgeWindow(p.level, p.pane, dir)
+	}
+}
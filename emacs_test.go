@@ -0,0 +1,113 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeEmacsclientScript stands in for emacsclient: it logs the expression
+// to $dir/expr and prints $dir/reply, failing like an unreachable server
+// when there is no reply.
+const fakeEmacsclientScript = `#!/bin/sh
+d=$(dirname "$0")
+[ "$1" = "--eval" ] || exit 1
+printf '%s' "$2" > "$d/expr"
+[ -f "$d/reply" ] || { echo "emacsclient: can't find socket" >&2; exit 1; }
+cat "$d/reply"
+`
+
+// fakeEmacsclient puts a fake emacsclient first on $PATH, answering reply
+// ("" for no server), with a server socket in its directory. It returns
+// the fake's directory.
+func fakeEmacsclient(t *testing.T, reply string) string {
+	t.Helper()
+	dir := t.TempDir()
+	fakeOnPath(t, dir, "emacsclient", fakeEmacsclientScript)
+	writeFile(t, filepath.Join(dir, "server"), "", 0o600)
+	if reply != "" {
+		writeFile(t, filepath.Join(dir, "reply"), reply+"\n", 0o644)
+	}
+	t.Setenv("EMACS_SOCKET_NAME", filepath.Join(dir, "server"))
+	t.Setenv("TMUX", "")
+	return dir
+}
+
+func TestEmacsTryMove(t *testing.T) {
+	t.Run("GUIFrame", func(t *testing.T) {
+		dir := fakeEmacsclient(t, "t")
+		if !emacsTryMove(DirRight) {
+			t.Error("expected a window move")
+		}
+		expr := readFile(t, filepath.Join(dir, "expr"))
+		if !strings.Contains(expr, "(frame-focus-state)") || !strings.Contains(expr, "(windmove-right)") {
+			t.Errorf("unexpected expression %q", expr)
+		}
+	})
+
+	t.Run("AtEdge", func(t *testing.T) {
+		fakeEmacsclient(t, "nil")
+		if emacsTryMove(DirLeft) {
+			t.Error("expected no move at the edge")
+		}
+	})
+
+	t.Run("Unreachable", func(t *testing.T) {
+		fakeEmacsclient(t, "")
+		if emacsTryMove(DirLeft) {
+			t.Error("expected no move without a server")
+		}
+	})
+
+	t.Run("NoServerSocket", func(t *testing.T) {
+		dir := fakeEmacsclient(t, "t")
+		t.Setenv("EMACS_SOCKET_NAME", filepath.Join(dir, "missing"))
+		if emacsTryMove(DirLeft) {
+			t.Error("expected no move without a server socket")
+		}
+		if _, err := os.Stat(filepath.Join(dir, "expr")); err == nil {
+			t.Error("expected emacsclient not to be run")
+		}
+	})
+
+	for _, tt := range []struct {
+		name    string
+		command string
+		moved   bool
+	}{
+		{"TmuxPane", "emacs", true},
+		{"TmuxPaneNotEmacs", "zsh", false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := fakeEmacsclient(t, "t")
+			t.Setenv("TMUX", "/tmp/tmux-1000/default,21,0")
+			originalRunTmux := runTmuxCmd
+			defer func() { runTmuxCmd = originalRunTmux }()
+			runTmuxCmd = func(args ...string) (string, error) {
+				return tt.command + " /dev/pts/7", nil
+			}
+			if moved := emacsTryMove(DirUp); moved != tt.moved {
+				t.Errorf("emacsTryMove(up) = %v, want %v", moved, tt.moved)
+			}
+			expr, _ := os.ReadFile(filepath.Join(dir, "expr"))
+			if tt.moved && (!strings.Contains(string(expr), `"/dev/pts/7"`) || !strings.Contains(string(expr), "(windmove-up)")) {
+				t.Errorf("unexpected expression %q", expr)
+			}
+			if !tt.moved && len(expr) != 0 {
+				t.Error("expected emacsclient not to be run")
+			}
+		})
+	}
+
+	t.Run("BeforeTmux", func(t *testing.T) {
+		isolateChain(t)
+		fakeEmacsclient(t, "t")
+		if rc := (&tmuxHopper{}).FocusNeighbor(DirRight, false, true); rc != 0 {
+			t.Errorf("expected the window move to win, got rc %d", rc)
+		}
+	})
+}
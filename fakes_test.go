@@ -0,0 +1,86 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, contents string, mode os.FileMode) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(contents), mode); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.TrimSpace(string(b))
+}
+
+// isolateChain keeps the default navigation chain of a test hop out of the
+// developer's session: no nvim, Emacs, tmux, kitty, WezTerm, zellij or
+// screen is reachable through the environment, and tmux landing finds no
+// server sockets.
+func isolateChain(t *testing.T) {
+	t.Helper()
+	for _, v := range simEnv {
+		t.Setenv(v, "")
+	}
+	t.Setenv("EMACS_SOCKET_NAME", filepath.Join(t.TempDir(), "no-emacs"))
+	t.Setenv("TMUX_TMPDIR", t.TempDir())
+}
+
+// fakeOnPath writes script to dir as command and puts dir first on $PATH.
+func fakeOnPath(t *testing.T, dir, command, script string) {
+	t.Helper()
+	writeFile(t, filepath.Join(dir, command), script, 0o755)
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+// fakeSession is a session of a fake multiplexer: the pane (or region) it
+// has focused and the row of them, left to right, it moves between.
+type fakeSession struct {
+	name  string
+	focus string
+	row   []string
+}
+
+// fakeMuxShell starts the fake zellij and screen scripts. It logs every
+// call to $d/log and defines move_focus DIR, which moves session $s from
+// $cur by its line of $d/$s.moves ("from direction to"), doing nothing
+// without one, like the real ones at the edge.
+const fakeMuxShell = `#!/bin/sh
+d=$(dirname "$0")
+echo "$*" >> "$d/log"
+move_focus() {
+  next=$(awk -v c="$cur" -v m="$1" '$1 == c && $2 == m { print $3 }' "$d/$s.moves")
+  [ -z "$next" ] || echo "$next" > "$d/$s.focus"
+}
+`
+
+// fakeMux puts a fake multiplexer first on $PATH: fakeMuxShell followed
+// by script, with each session's focus in $dir/NAME.focus and its moves in
+// $dir/NAME.moves. It returns the fake's directory.
+func fakeMux(t *testing.T, command, script string, sessions ...fakeSession) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, s := range sessions {
+		var moves []string
+		for i := 0; i+1 < len(s.row); i++ {
+			moves = append(moves, s.row[i]+" right "+s.row[i+1], s.row[i+1]+" left "+s.row[i])
+		}
+		writeFile(t, filepath.Join(dir, s.name+".focus"), s.focus+"\n", 0o644)
+		writeFile(t, filepath.Join(dir, s.name+".moves"), strings.Join(moves, "\n")+"\n", 0o644)
+	}
+	fakeOnPath(t, dir, command, fakeMuxShell+script)
+	return dir
+}
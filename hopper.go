@@ -3,251 +3,12 @@
 
 package main
 
-/*
-#cgo CFLAGS: -x objective-c
-#cgo LDFLAGS: -framework ApplicationServices -framework AppKit
-#import <ApplicationServices/ApplicationServices.h>
-#import <AppKit/AppKit.h>
-#include <stdlib.h>
-#include <stdio.h>
-#include <unistd.h>
-
-// ---------- Logging ----------
-static int g_debug = 0;
-static void set_debug(int d) { g_debug = d; }
-#define DBG(fmt, ...) do { if (g_debug) fprintf(stderr, "ttyhop: " fmt "\n", ##__VA_ARGS__); } while(0)
-
-// ---- cgo bridges to Go helpers ----
-extern void goTmuxSelectEdge(int east, int waitMs);
-extern int  goTmuxTryPaneMove(int east);
-
-// ---------- Accessibility trust ----------
-static int ensure_trusted_i(void) {
-  const void* keys[] = { kAXTrustedCheckOptionPrompt };
-  const void* vals[] = { kCFBooleanTrue };
-  CFDictionaryRef opts = CFDictionaryCreate(NULL, keys, vals, 1,
-                    &kCFTypeDictionaryKeyCallBacks, &kCFTypeDictionaryValueCallBacks);
-  Boolean ok = AXIsProcessTrustedWithOptions(opts);
-  if (opts) CFRelease(opts);
-  DBG("accessibility trusted=%s", ok ? "true" : "false");
-  if (ok) return 1; else return 0;
-}
-
-// ---------- Frontmost app via NSWorkspace (preferred) ----------
-static AXUIElementRef ax_frontmost_app_retained_ws(void) {
-  NSRunningApplication *ra = [[NSWorkspace sharedWorkspace] frontmostApplication];
-  if (!ra) { DBG("frontmostApplication: nil"); return NULL; }
-  pid_t pid = ra.processIdentifier;
-  const char *bid = ra.bundleIdentifier ? ra.bundleIdentifier.UTF8String : "";
-  const char *name = ra.localizedName ? ra.localizedName.UTF8String : "";
-  DBG("frontmost (WS): bid=%s name=%s pid=%d", bid, name, pid);
-  return AXUIElementCreateApplication(pid); // retained
-}
-
-static void front_app_info_ws(char **outBid, char **outName) {
-  *outBid = NULL; *outName = NULL;
-  NSRunningApplication *ra = [[NSWorkspace sharedWorkspace] frontmostApplication];
-  if (!ra) return;
-  if (ra.bundleIdentifier) *outBid = strdup(ra.bundleIdentifier.UTF8String);
-  if (ra.localizedName)    *outName = strdup(ra.localizedName.UTF8String);
-}
-
-// ---------- Legacy AX focused app (fallback) ----------
-static AXUIElementRef ax_focused_app_retained(void) {
-  AXUIElementRef sys = AXUIElementCreateSystemWide();
-  if (!sys) return NULL;
-  CFTypeRef app = NULL;
-  AXError e = AXUIElementCopyAttributeValue(sys, kAXFocusedApplicationAttribute, &app);
-  CFRelease(sys);
-  if (e != kAXErrorSuccess || !app) { DBG("AX focused app: none (err=%d)", e); return NULL; }
-  pid_t pid = 0; AXUIElementGetPid((AXUIElementRef)app, &pid);
-  NSRunningApplication *ra = [NSRunningApplication runningApplicationWithProcessIdentifier:pid];
-  const char *bid = ra.bundleIdentifier ? ra.bundleIdentifier.UTF8String : "";
-  const char *name = ra.localizedName ? ra.localizedName.UTF8String : "";
-  DBG("frontmost (AX): bid=%s name=%s pid=%d", bid, name, pid);
-  return (AXUIElementRef)app; // retained
-}
-
-static void front_app_info_ax(char **outBid, char **outName) {
-  *outBid = NULL; *outName = NULL;
-  AXUIElementRef axApp = ax_focused_app_retained();
-  if (!axApp) return;
-  pid_t pid = 0; AXUIElementGetPid(axApp, &pid);
-  NSRunningApplication *ra = [NSRunningApplication runningApplicationWithProcessIdentifier:pid];
-  if (ra.bundleIdentifier) *outBid = strdup(ra.bundleIdentifier.UTF8String);
-  if (ra.localizedName)    *outName = strdup(ra.localizedName.UTF8String);
-  CFRelease(axApp);
-}
-
-// ---------- Helpers ----------
-static BOOL ax_get_rect(AXUIElementRef win, CGRect *out) {
-  if (!win) return NO;
-  CFTypeRef posVal = NULL, sizeVal = NULL;
-  if (AXUIElementCopyAttributeValue(win, kAXPositionAttribute, &posVal) != kAXErrorSuccess) return NO;
-  if (AXUIElementCopyAttributeValue(win, kAXSizeAttribute, &sizeVal) != kAXErrorSuccess) { if (posVal) CFRelease(posVal); return NO; }
-  CGPoint p = CGPointZero; CGSize s = CGSizeZero;
-  AXValueGetValue((AXValueRef)posVal, kAXValueCGPointType, &p);
-  AXValueGetValue((AXValueRef)sizeVal, kAXValueCGSizeType, &s);
-  if (posVal) CFRelease(posVal);
-  if (sizeVal) CFRelease(sizeVal);
-  *out = (CGRect){p, s};
-  return YES;
-}
-
-static BOOL app_is_alacritty(AXUIElementRef axApp) {
-  if (!axApp) return NO;
-  pid_t pid = 0; AXUIElementGetPid(axApp, &pid);
-  NSRunningApplication *ra = [NSRunningApplication runningApplicationWithProcessIdentifier:pid];
-  if (!ra) return NO;
-  NSString *bid = ra.bundleIdentifier ?: @"";
-  NSString *name = ra.localizedName ?: @"";
-  BOOL ok = [bid isEqualToString:@"org.alacritty"] || [bid isEqualToString:@"io.alacritty"] || [name isEqualToString:@"Alacritty"];
-  const char *cbid = bid.UTF8String;
-  const char *cname = name.UTF8String;
-  DBG("app_is_alacritty=%s (bid=%s name=%s)", ok ? "true" : "false", cbid, cname);
-  return ok;
-}
-
-static AXUIElementRef app_focused_window(AXUIElementRef axApp) {
-  if (!axApp) return NULL;
-  CFTypeRef fw = NULL;
-  if (AXUIElementCopyAttributeValue(axApp, kAXFocusedWindowAttribute, &fw) == kAXErrorSuccess && fw) {
-    return (AXUIElementRef)fw; // retained
-  }
-  CFTypeRef arr = NULL;
-  if (AXUIElementCopyAttributeValue(axApp, kAXWindowsAttribute, &arr) != kAXErrorSuccess || !arr) return NULL;
-  CFArrayRef wins = (CFArrayRef)arr;
-  AXUIElementRef w = NULL;
-  if (CFArrayGetCount(wins) > 0) {
-    w = (AXUIElementRef)CFRetain(CFArrayGetValueAtIndex(wins, 0));
-  }
-  CFRelease(arr);
-  return w;
-}
-
-static CFArrayRef app_windows_retained(AXUIElementRef axApp) {
-  if (!axApp) return NULL;
-  CFTypeRef arr = NULL;
-  if (AXUIElementCopyAttributeValue(axApp, kAXWindowsAttribute, &arr) != kAXErrorSuccess || !arr) return NULL;
-  return (CFArrayRef)arr; // retained
-}
-
-static void focus_window(AXUIElementRef axApp, AXUIElementRef win) {
-  if (!axApp || !win) return;
-  AXUIElementPerformAction(win, kAXRaiseAction);
-  AXUIElementSetAttributeValue(win, kAXMainAttribute, kCFBooleanTrue);
-  AXUIElementSetAttributeValue(win, kAXFocusedAttribute, kCFBooleanTrue);
-  AXUIElementSetAttributeValue(axApp, kAXFocusedWindowAttribute, win);
-  pid_t pid = 0; AXUIElementGetPid(win, &pid);
-  NSRunningApplication *ra = [NSRunningApplication runningApplicationWithProcessIdentifier:pid];
-  if (ra) [ra activateWithOptions:0];
-}
-
-// ---- Send C-<key> to a process (for tmux binds) ----
-// US layout keycodes: 'h' = 4, 'l' = 37.
-static void send_ctrl_key_to_pid(pid_t pid, CGKeyCode key, int times, useconds_t gap_us) {
-  for (int i=0; i<times; i++) {
-    CGEventRef down = CGEventCreateKeyboardEvent(NULL, key, true);
-    CGEventRef up   = CGEventCreateKeyboardEvent(NULL, key, false);
-    CGEventSetFlags(down, kCGEventFlagMaskControl);
-    CGEventSetFlags(up,   kCGEventFlagMaskControl);
-    CGEventPostToPid(pid, down);
-    CGEventPostToPid(pid, up);
-    CFRelease(down); CFRelease(up);
-    if (gap_us) usleep(gap_us);
-  }
-}
-
-// Returns 0 on success. Non-zero = "no move".
-static int alacritty_focus_neighbor_dbg(int east, int debug, int do_edge, int edge_steps, int wait_ms) {
-  g_debug = debug;
-
-  // NEW: try tmux pane move first (no AX needed).
-  if (goTmuxTryPaneMove(east)) {
-    DBG("tmux: moved pane %s", east ? "right" : "left");
-    return 0;
-  }
-
-  if (!ensure_trusted_i()) { DBG("denied: accessibility not trusted"); return 20; }
-
-  AXUIElementRef axApp = ax_frontmost_app_retained_ws();
-  if (!axApp) axApp = ax_focused_app_retained();
-  if (!axApp) { DBG("denied: could not obtain front app"); return 10; }
-
-  if (!app_is_alacritty(axApp)) { CFRelease(axApp); DBG("denied: front app is not Alacritty"); return 1; }
-
-  AXUIElementRef meWin = app_focused_window(axApp);
-  if (!meWin) { CFRelease(axApp); DBG("denied: no focused window"); return 2; }
-
-  CGRect meR; if (!ax_get_rect(meWin, &meR)) { CFRelease(meWin); CFRelease(axApp); DBG("denied: cannot read current window rect"); return 3; }
-  CGFloat cx = CGRectGetMidX(meR), cy = CGRectGetMidY(meR), h = CGRectGetHeight(meR);
-
-  CFArrayRef wins = app_windows_retained(axApp);
-  if (!wins) { CFRelease(meWin); CFRelease(axApp); DBG("denied: cannot list windows"); return 4; }
-
-  CFIndex n = CFArrayGetCount(wins);
-  DBG("windows in app: %ld", (long)n);
-
-  AXUIElementRef best = NULL; CGFloat bestDx = CGFLOAT_MAX; int bestIdx = -1;
-
-  for (CFIndex i = 0; i < n; i++) {
-    AXUIElementRef w = (AXUIElementRef)CFArrayGetValueAtIndex(wins, i);
-    if (CFEqual(w, meWin)) continue;
-    CGRect r; if (!ax_get_rect(w, &r)) continue;
-    CGFloat dx = CGRectGetMidX(r) - cx;
-    CGFloat dy = fabs(CGRectGetMidY(r) - cy);
-    int horiz = (dy <= h * 0.75);
-    if (g_debug) {
-      fprintf(stderr, "ttyhop: cand[%ld] mid=(%.1f,%.1f) dx=%.1f dy=%.1f horiz=%d\n",
-              (long)i, CGRectGetMidX(r), CGRectGetMidY(r), dx, dy, horiz);
-    }
-    if (!horiz) continue;
-    if (!east && dx < 0 && -dx < bestDx) { bestDx = -dx; best = w; bestIdx = (int)i; }
-    if ( east && dx > 0 &&  dx < bestDx) { bestDx =  dx; best = w; bestIdx = (int)i; }
-  }
-
-  int rc = 5;
-  if (best) {
-    const char *dirstr;
-    if (east) dirstr = "east"; else dirstr = "west";
-    DBG("focusing neighbor %s: idx=%d, distance=%.1f", dirstr, bestIdx, bestDx);
-    focus_window(axApp, best);
-
-    if (do_edge) {
-      // Prefer tmux IPC to land on edge pane in the destination window.
-      goTmuxSelectEdge(east, wait_ms);
-      DBG("edge-nudge: tmux IPC select edge");
-      // Keystroke-based nudge is still available (kept for reference):
-      // usleep(20000);
-      // pid_t pid = 0; AXUIElementGetPid(best, &pid);
-      // if (east) { send_ctrl_key_to_pid(pid, 4, edge_steps, 2000); DBG("edge-nudge: C-h x%d", edge_steps); }
-      // else      { send_ctrl_key_to_pid(pid, 37, edge_steps, 2000); DBG("edge-nudge: C-l x%d", edge_steps); }
-    }
-    rc = 0;
-  } else {
-    const char *dirstr2;
-    if (east) dirstr2 = "east"; else dirstr2 = "west";
-    DBG("no neighbor %s found", dirstr2);
-  }
-
-  CFRelease(wins);
-  CFRelease(meWin);
-  CFRelease(axApp);
-  return rc;
-}
-
-*/
-import "C"
-
 import (
-	"bytes"
 	"fmt"
 	"os"
-	"os/exec"
 	"strconv"
-	"strings"
-	"time"
-	"unsafe"
+
+	"github.com/leejonesio/ttyhop/internal/neighbor"
 )
 
 const (
@@ -255,254 +16,294 @@ const (
 	pollIntervalMs = 25
 )
 
-// Hopper provides an interface for all platform-specific (Cgo) interactions.
+// Direction is the way a hop travels.
+type Direction = neighbor.Direction
+
+const (
+	DirLeft  = neighbor.Left
+	DirRight = neighbor.Right
+	DirUp    = neighbor.Up
+	DirDown  = neighbor.Down
+)
+
+// parseDirection maps a command-line word (e.g. "left" or "l") to a Direction.
+func parseDirection(s string) (Direction, bool) {
+	switch s {
+	case "left", "l":
+		return DirLeft, true
+	case "right", "r":
+		return DirRight, true
+	case "up", "k":
+		return DirUp, true
+	case "down", "j":
+		return DirDown, true
+	}
+	return 0, false
+}
+
+// Hopper provides an interface for all platform-specific interactions.
 type Hopper interface {
-	FocusNeighbor(east bool, debug bool, doEdge bool, edgeSteps int) int
+	FocusNeighbor(dir Direction, debug bool, doEdge bool) int
+	// FocusDisplay hops to the terminal window on another monitor; see hopDisplay.
+	FocusDisplay(target string, debug bool) int
+	// Inspect scores the candidate windows for a hop in dir without moving.
+	// best is -1 when nothing would be chosen; rc is non-zero on failure.
+	Inspect(dir Direction) (best int, scored []neighbor.Scored, rc int)
 	SetDebug(debug bool)
 	SetWaitMs(waitMs int)
+	SetStrategy(strategy neighbor.Strategy)
+	// SetWrap enables wrap-around to the far window and/or, when no window
+	// hop happens, to the far pane of the current tmux window.
+	SetWrap(windows, panes bool)
+	// SetZoomPolicy decides how hops treat zoomed tmux windows.
+	SetZoomPolicy(zoom zoomPolicy)
+	// SetTerminals sets the allowlist of apps to hop between.
+	SetTerminals(terminals []termMatcher)
+	// SetChain sets the navigators and windows step a hop tries, in order.
+	SetChain(chain []string)
+	// SetTmuxSockets names extra tmux servers a hop might land in.
+	SetTmuxSockets(sockets []string)
+	// SetLayout sets the layout file the sim backend replays.
+	SetLayout(path string)
 	IsTrusted() bool
 	GetFrontAppInfo() (bid, name string, source string)
 }
 
-// cgoHopper is the unexported, production implementation of Hopper that calls Cgo functions.
-type cgoHopper struct {
-	waitMs int
+// cleaner is a Hopper that changes process-wide state, such as the tmux
+// runner or the environment, while it works; run calls cleanup on return.
+type cleaner interface {
+	cleanup()
 }
 
-// newHopper creates a new production Hopper that uses Cgo.
-func newHopper() Hopper {
-	return &cgoHopper{}
+// hopOptions holds the settings every Hopper implementation shares.
+type hopOptions struct {
+	waitMs      int
+	strategy    neighbor.Strategy
+	wrapWindows bool
+	wrapPanes   bool
+	zoom        zoomPolicy
+	terminals   []termMatcher // nil means defaultTerminals
+	chain       []string      // nil means defaultChain
+	// tmuxSockets names extra tmux servers from --tmux-socket, each a
+	// socket name (as for tmux -L) or a path (as for tmux -S).
+	tmuxSockets []string
+	layoutPath  string // --layout, for the sim backend
 }
 
-func (h *cgoHopper) FocusNeighbor(east bool, debug bool, doEdge bool, edgeSteps int) int {
-	cEast := C.int(0)
-	if east {
-		cEast = 1
-	}
-	cDebug := C.int(0)
-	if debug {
-		cDebug = 1
-	}
-	cDoEdge := C.int(1)
-	if !doEdge {
-		cDoEdge = 0
-	}
-	cEdgeSteps := C.int(edgeSteps)
-	cWaitMs := C.int(h.waitMs)
-	return int(C.alacritty_focus_neighbor_dbg(cEast, cDebug, cDoEdge, cEdgeSteps, cWaitMs))
+func (o *hopOptions) SetWaitMs(waitMs int) {
+	o.waitMs = waitMs
 }
 
-func (h *cgoHopper) SetDebug(debug bool) {
-	cDebug := C.int(0)
-	if debug {
-		cDebug = 1
-	}
-	C.set_debug(cDebug)
+func (o *hopOptions) SetStrategy(strategy neighbor.Strategy) {
+	o.strategy = strategy
 }
 
-func (h *cgoHopper) SetWaitMs(waitMs int) {
-	h.waitMs = waitMs
+func (o *hopOptions) SetWrap(windows, panes bool) {
+	o.wrapWindows, o.wrapPanes = windows, panes
 }
 
-func (h *cgoHopper) IsTrusted() bool {
-	return C.ensure_trusted_i() == 1
+func (o *hopOptions) SetZoomPolicy(zoom zoomPolicy) {
+	o.zoom = zoom
 }
 
-func (h *cgoHopper) GetFrontAppInfo() (string, string, string) {
-	var bidWS, nameWS *C.char
-	C.front_app_info_ws(&bidWS, &nameWS)
-	if bidWS != nil {
-		defer C.free(unsafe.Pointer(bidWS))
-	}
-	if nameWS != nil {
-		defer C.free(unsafe.Pointer(nameWS))
-	}
-	bid := C.GoString(bidWS)
-	name := C.GoString(nameWS)
-
-	if bid == "" && name == "" {
-		var bidAX, nameAX *C.char
-		C.front_app_info_ax(&bidAX, &nameAX)
-		if bidAX != nil {
-			defer C.free(unsafe.Pointer(bidAX))
-		}
-		if nameAX != nil {
-			defer C.free(unsafe.Pointer(nameAX))
-		}
-		return C.GoString(bidAX), C.GoString(nameAX), "AX"
-	}
-	return bid, name, "WS"
+func (o *hopOptions) SetTerminals(terminals []termMatcher) {
+	o.terminals = terminals
 }
 
-// ---------- small stderr logger to match C DBG prefix ----------
-func dbg(format string, a ...any) {
-	if os.Getenv("TTYHOP_LOG") == "1" {
-		fmt.Fprintf(os.Stderr, "ttyhop: "+format+"\n", a...)
-	}
+func (o *hopOptions) SetChain(chain []string) {
+	o.chain = chain
 }
 
-// ---------- tmux IPC helpers ----------
-
-type runTmuxCmdFunc func(args ...string) (string, error)
+func (o *hopOptions) SetTmuxSockets(sockets []string) {
+	o.tmuxSockets = sockets
+}
 
-var runTmuxCmd runTmuxCmdFunc = defaultRunTmuxCmd
+func (o *hopOptions) SetLayout(path string) {
+	o.layoutPath = path
+}
 
-func defaultRunTmuxCmd(args ...string) (string, error) {
-	cmd := exec.Command("tmux", args...)
-	var out bytes.Buffer
-	cmd.Stdout = &out
-	cmd.Stderr = &out
-	err := cmd.Run()
-	if err != nil {
-		return "", err
+// pick scores cands with the configured strategy, falling back to the
+// default, preferring windows on the current display. When nothing lies in
+// dir and window wrapping is on, it picks the far window on the other side
+// instead and reports wrapped.
+func (o *hopOptions) pick(dir Direction, cur neighbor.Rect, cands []neighbor.Candidate, displays []neighbor.Display) (best int, scored []neighbor.Scored, wrapped bool) {
+	st := o.strategy
+	if st.Name == "" {
+		st = neighbor.Default
 	}
-	return strings.TrimSpace(out.String()), nil
+	best, scored = st.PickOnDisplays(dir, cur, cands, displays)
+	if best < 0 && o.wrapWindows {
+		if w, wScored := st.Wrap(dir, cur, cands); w >= 0 {
+			return w, wScored, true
+		}
+	}
+	return best, scored, false
 }
 
-// Try to move tmux pane first; return true if moved.
-// NOTE: #{pane_at_left/right} == 1 means you are AT the outer edge (no neighbor that way).
-func tmuxTryPaneMove(east bool) bool {
-	if os.Getenv("TMUX") == "" {
-		return false
+// windowSource is the platform half of a window hop: it lists the focused
+// terminal window and its siblings, and focuses one of them. Everything in
+// between is decided by the neighbor package.
+type windowSource interface {
+	// windows returns the focused window's rect and the other candidate
+	// windows in stacking order, front-most first. A non-zero rc is
+	// returned as the process exit code.
+	windows() (cur neighbor.Rect, cands []neighbor.Candidate, rc int)
+	// displays lists the monitors, or nil if the backend can't tell.
+	displays() []neighbor.Display
+	// focus raises and focuses the candidate with the given ID.
+	focus(id string)
+}
+
+// hop runs the shared navigation flow: each navigator of the chain in
+// turn (by default Neovim split or Emacs window, then tmux, zellij or
+// screen pane, then kitty split or WezTerm pane), hopping to the nearest
+// window in dir at the windows step. Returns 0 on success and non-zero for
+// "no move".
+func hop(src windowSource, dir Direction, doEdge bool, opts hopOptions) int {
+	w := &windowNavigator{src: src, opts: opts, doEdge: doEdge, rc: 5}
+	w.chain = openChain(opts, w)
+	if tryChain(w.chain, dir) {
+		return 0
+	}
+	if opts.wrapPanes && wrapChain(w.chain, dir) {
+		return 0
 	}
+	return w.rc
+}
+
+// windowNavigator is the windows step of the chain: it hops to the nearest
+// window in dir, then lands every navigator of the chain on its edge.
+type windowNavigator struct {
+	src    windowSource
+	opts   hopOptions
+	doEdge bool
+	chain  []Navigator
+	// rc is why the last Try didn't hop, as an exit code.
+	rc int
+}
 
-	// Active pane before move
-	oldID, err := runTmuxCmd("display", "-p", "#{pane_id}")
-	if err != nil || strings.TrimSpace(oldID) == "" {
+func (w *windowNavigator) Try(dir Direction) bool {
+	cur, best, scored, wrapped, rc := inspect(w.src, dir, w.opts)
+	for _, s := range scored {
+		dbg("%s", formatScored(s))
+	}
+	if rc == 0 && best < 0 {
+		dbg("no neighbor %s found", dir)
+		rc = 5
+	}
+	if rc != 0 {
+		w.rc = rc
 		return false
 	}
 
-	// Edge check (1 = at edge, no neighbor; 0 = has neighbor)
-	edgeKey := "#{pane_at_right}"
-	dir := "-R"
-	if !east {
-		edgeKey = "#{pane_at_left}"
-		dir = "-L"
+	target := scored[best]
+	if wrapped {
+		dbg("wrapping %s to the far %s window: id=%s", dir, dir.Opposite(), target.ID)
+	} else {
+		dbg("focusing neighbor %s: id=%s, distance=%.1f, score=%.1f (%s)", dir, target.ID, target.Distance, target.Score, w.opts.strategy.Name)
 	}
-	edge, err := runTmuxCmd("display", "-p", edgeKey)
-	if err != nil {
-		return false
+	// Capture where we're hopping from before focus moves away.
+	if w.doEdge {
+		for _, n := range w.chain {
+			if r, ok := n.(originRecorder); ok {
+				r.recordOrigin(dir, cur, target.Candidate)
+			}
+		}
 	}
-	if strings.TrimSpace(edge) == "1" {
-		return false // at edge, let caller hop windows
+	w.src.focus(target.ID)
+
+	if w.doEdge {
+		// Land from the outside in: terminal splits first, then the
+		// multiplexer running in them, then the editor in its pane.
+		for i := len(w.chain) - 1; i >= 0; i-- {
+			w.chain[i].LandAtEdge(dir)
+		}
 	}
+	return true
+}
 
-	// Move relative to the active pane (no -t)
-	_, _ = runTmuxCmd("select-pane", dir)
+// LandAtEdge does nothing: the window hop itself lands the others.
+func (w *windowNavigator) LandAtEdge(dir Direction) {}
 
-	// Verify it actually changed pane
-	newID, _ := runTmuxCmd("display", "-p", "#{pane_id}")
-	if strings.TrimSpace(newID) == "" || newID == oldID {
-		return false
+// inspect lists the windows from src and scores them for a hop in dir.
+func inspect(src windowSource, dir Direction, opts hopOptions) (cur neighbor.Rect, best int, scored []neighbor.Scored, wrapped bool, rc int) {
+	cur, cands, rc := src.windows()
+	if rc != 0 {
+		return cur, -1, nil, false, rc
 	}
-
-	dbg("tmux: pane move %s via IPC", map[bool]string{true: "right", false: "left"}[east])
-	return true
+	displays := src.displays()
+	dbg("windows in app: %d, displays: %d", len(cands)+1, len(displays))
+	best, scored, wrapped = opts.pick(dir, cur, cands, displays)
+	return cur, best, scored, wrapped, 0
 }
 
-//export goTmuxTryPaneMove
-func goTmuxTryPaneMove(east C.int) C.int {
-	if tmuxTryPaneMove(east != 0) {
-		return 1
+// parseDisplayTarget validates the argument to "ttyhop display": next, prev
+// or a 1-based display number.
+func parseDisplayTarget(s string) bool {
+	if s == "next" || s == "prev" {
+		return true
 	}
-	return 0
+	n, err := strconv.Atoi(s)
+	return err == nil && n > 0
 }
 
-// Select the appropriate edge pane in the newly focused Alacritty window.
-// Uses the newly active tmux client; #{pane_at_left/right} (1 = outer edge).
-func pickActiveClient() (string, error) {
-	out, err := runTmuxCmd("list-clients", "-F", "#{client_tty} #{client_active} #{client_activity}")
-	if err != nil || out == "" {
-		return "", err
+// hopDisplay focuses the front-most terminal window on another display,
+// chosen by target ("next", "prev" or a 1-based number counted left to
+// right). Returns 0 on success and 5 if that display has no terminal window.
+func hopDisplay(src windowSource, target string) int {
+	cur, cands, rc := src.windows()
+	if rc != 0 {
+		return rc
 	}
-	type rec struct {
-		tty    string
-		active bool
-		act    int64
+	displays := src.displays()
+	if len(displays) == 0 {
+		dbg("display: backend cannot list displays")
+		return 5
 	}
-	var best rec
-	var have bool
-	for _, ln := range strings.Split(out, "\n") {
-		f := strings.Fields(ln)
-		if len(f) < 3 {
-			continue
-		}
-		a, _ := strconv.ParseInt(f[2], 10, 64)
-		r := rec{tty: f[0], active: (f[1] == "1"), act: a}
-		if r.active {
-			return r.tty, nil
-		}
-		if !have || r.act > best.act {
-			best, have = r, true
-		}
+	neighbor.SortDisplays(displays)
+
+	here := neighbor.DisplayOf(cur, displays)
+	to := here
+	switch target {
+	case "next":
+		to = (here + 1) % len(displays)
+	case "prev":
+		to = (here - 1 + len(displays)) % len(displays)
+	default:
+		n, _ := strconv.Atoi(target)
+		to = n - 1
 	}
-	if have {
-		return best.tty, nil
+	if to < 0 || to >= len(displays) || to == here {
+		dbg("display: no display %s from %d of %d", target, here+1, len(displays))
+		return 5
 	}
-	return "", nil
-}
 
-func tmuxSelectEdgePane(east bool, waitMs int) {
-	// Wait briefly for the newly focused Alacritty window's tmux client to become active.
-	if waitMs <= 0 {
-		waitMsStr := os.Getenv("TTYHOP_EDGE_WAIT_MS")
-		waitMsVal, err := strconv.Atoi(waitMsStr)
-		if err != nil || waitMsVal <= 0 {
-			waitMs = defaultWaitMs // default
-		} else {
-			waitMs = waitMsVal
+	// Candidates arrive in stacking order, so the first one on the target
+	// display is the one the user saw last.
+	for _, c := range cands {
+		if neighbor.DisplayOf(c.Rect, displays) == to {
+			dbg("display: focusing %s on display %d (%s)", c.ID, to+1, displays[to].ID)
+			src.focus(c.ID)
+			return 0
 		}
 	}
-	dbg("using edge wait: %dms", waitMs)
-
-	pollInterval := pollIntervalMs * time.Millisecond
-	numPolls := waitMs / pollIntervalMs
-
-	for i := 0; i < numPolls; i++ {
-		time.Sleep(pollInterval)
+	dbg("display: no terminal window on display %d", to+1)
+	return 5
+}
 
-		tty, err := pickActiveClient()
-		if err != nil || strings.TrimSpace(tty) == "" {
-			continue
-		}
+// formatScored renders one candidate for debug logs and --check.
+func formatScored(s neighbor.Scored) string {
+	return fmt.Sprintf("cand[%s] mid=(%.1f,%.1f) dx=%.1f dy=%.1f score=%.1f eligible=%v",
+		s.ID, s.Rect.MidX(), s.Rect.MidY(), s.Dx, s.Dy, s.Score, s.Eligible)
+}
 
-		// Query that client's current window
-		win, err := runTmuxCmd("display", "-p", "-t", tty, "#{window_id}")
-		if err != nil || strings.TrimSpace(win) == "" {
-			continue
-		}
+// ---------- small stderr logger to match C DBG prefix ----------
 
-		// List panes in that window; use pane_at_left/right (1 = outer edge)
-		panes, err := runTmuxCmd("list-panes", "-t", win, "-F", "#{pane_id} #{pane_at_left} #{pane_at_right}")
-		if err != nil || strings.TrimSpace(panes) == "" {
-			continue
-		}
+// debugLog is set by Hopper.SetDebug from -v/--log or TTYHOP_LOG=1.
+var debugLog bool
 
-		var target string
-		for _, ln := range strings.Split(panes, "\n") {
-			f := strings.Fields(ln)
-			if len(f) != 3 {
-				continue
-			}
-			id, atL, atR := f[0], f[1], f[2]
-			if east && atL == "1" { // moving right -> land on LEFTMOST pane
-				target = id
-				break
-			}
-			if !east && atR == "1" { // moving left -> land on RIGHTMOST pane
-				target = id
-				break
-			}
-		}
-		if target != "" {
-			_, _ = runTmuxCmd("select-pane", "-t", target)
-			dbg("tmux: landed on edge pane %s", map[bool]string{true: "LEFTMOST", false: "RIGHTMOST"}[east])
-		}
-		return
+func dbg(format string, a ...any) {
+	if debugLog {
+		fmt.Fprintf(os.Stderr, "ttyhop: "+format+"\n", a...)
 	}
 }
-
-//export goTmuxSelectEdge
-func goTmuxSelectEdge(east C.int, waitMs C.int) {
-	tmuxSelectEdgePane(east != 0, int(waitMs))
-}
@@ -0,0 +1,484 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package main
+
+/*
+#cgo CFLAGS: -x objective-c
+#cgo LDFLAGS: -framework ApplicationServices -framework AppKit
+#import <ApplicationServices/ApplicationServices.h>
+#import <AppKit/AppKit.h>
+#include <stdlib.h>
+#include <stdio.h>
+#include <unistd.h>
+
+// ---------- Logging ----------
+static int g_debug = 0;
+static void set_debug(int d) { g_debug = d; }
+#define DBG(fmt, ...) do { if (g_debug) fprintf(stderr, "ttyhop: " fmt "\n", ##__VA_ARGS__); } while(0)
+
+// ---------- Accessibility trust ----------
+static int ensure_trusted_i(void) {
+  const void* keys[] = { kAXTrustedCheckOptionPrompt };
+  const void* vals[] = { kCFBooleanTrue };
+  CFDictionaryRef opts = CFDictionaryCreate(NULL, keys, vals, 1,
+                    &kCFTypeDictionaryKeyCallBacks, &kCFTypeDictionaryValueCallBacks);
+  Boolean ok = AXIsProcessTrustedWithOptions(opts);
+  if (opts) CFRelease(opts);
+  DBG("accessibility trusted=%s", ok ? "true" : "false");
+  if (ok) return 1; else return 0;
+}
+
+// ---------- Frontmost app via NSWorkspace (preferred) ----------
+static void front_app_info_ws(char **outBid, char **outName) {
+  *outBid = NULL; *outName = NULL;
+  NSRunningApplication *ra = [[NSWorkspace sharedWorkspace] frontmostApplication];
+  if (!ra) return;
+  if (ra.bundleIdentifier) *outBid = strdup(ra.bundleIdentifier.UTF8String);
+  if (ra.localizedName)    *outName = strdup(ra.localizedName.UTF8String);
+}
+
+// ---------- Legacy AX focused app (fallback) ----------
+static AXUIElementRef ax_focused_app_retained(void) {
+  AXUIElementRef sys = AXUIElementCreateSystemWide();
+  if (!sys) return NULL;
+  CFTypeRef app = NULL;
+  AXError e = AXUIElementCopyAttributeValue(sys, kAXFocusedApplicationAttribute, &app);
+  CFRelease(sys);
+  if (e != kAXErrorSuccess || !app) { DBG("AX focused app: none (err=%d)", e); return NULL; }
+  pid_t pid = 0; AXUIElementGetPid((AXUIElementRef)app, &pid);
+  NSRunningApplication *ra = [NSRunningApplication runningApplicationWithProcessIdentifier:pid];
+  const char *bid = ra.bundleIdentifier ? ra.bundleIdentifier.UTF8String : "";
+  const char *name = ra.localizedName ? ra.localizedName.UTF8String : "";
+  DBG("frontmost (AX): bid=%s name=%s pid=%d", bid, name, pid);
+  return (AXUIElementRef)app; // retained
+}
+
+static void front_app_info_ax(char **outBid, char **outName) {
+  *outBid = NULL; *outName = NULL;
+  AXUIElementRef axApp = ax_focused_app_retained();
+  if (!axApp) return;
+  pid_t pid = 0; AXUIElementGetPid(axApp, &pid);
+  NSRunningApplication *ra = [NSRunningApplication runningApplicationWithProcessIdentifier:pid];
+  if (ra.bundleIdentifier) *outBid = strdup(ra.bundleIdentifier.UTF8String);
+  if (ra.localizedName)    *outName = strdup(ra.localizedName.UTF8String);
+  CFRelease(axApp);
+}
+
+// ---------- Helpers ----------
+static BOOL ax_get_rect(AXUIElementRef win, CGRect *out) {
+  if (!win) return NO;
+  CFTypeRef posVal = NULL, sizeVal = NULL;
+  if (AXUIElementCopyAttributeValue(win, kAXPositionAttribute, &posVal) != kAXErrorSuccess) return NO;
+  if (AXUIElementCopyAttributeValue(win, kAXSizeAttribute, &sizeVal) != kAXErrorSuccess) { if (posVal) CFRelease(posVal); return NO; }
+  CGPoint p = CGPointZero; CGSize s = CGSizeZero;
+  AXValueGetValue((AXValueRef)posVal, kAXValueCGPointType, &p);
+  AXValueGetValue((AXValueRef)sizeVal, kAXValueCGSizeType, &s);
+  if (posVal) CFRelease(posVal);
+  if (sizeVal) CFRelease(sizeVal);
+  *out = (CGRect){p, s};
+  return YES;
+}
+
+// Returns the window's title (caller frees); "" when it has none.
+static char *ax_copy_title(AXUIElementRef win) {
+  CFTypeRef t = NULL;
+  char *out = NULL;
+  if (AXUIElementCopyAttributeValue(win, kAXTitleAttribute, &t) == kAXErrorSuccess && t) {
+    if (CFGetTypeID(t) == CFStringGetTypeID()) {
+      const char *u = ((NSString *)t).UTF8String;
+      if (u) out = strdup(u);
+    }
+    CFRelease(t);
+  }
+  return out ? out : strdup("");
+}
+
+static void ax_free_strings(char **s, int n) {
+  if (!s) return;
+  for (int i = 0; i < n; i++) free(s[i]);
+  free(s);
+}
+
+static AXUIElementRef app_focused_window(AXUIElementRef axApp) {
+  if (!axApp) return NULL;
+  CFTypeRef fw = NULL;
+  if (AXUIElementCopyAttributeValue(axApp, kAXFocusedWindowAttribute, &fw) == kAXErrorSuccess && fw) {
+    return (AXUIElementRef)fw; // retained
+  }
+  CFTypeRef arr = NULL;
+  if (AXUIElementCopyAttributeValue(axApp, kAXWindowsAttribute, &arr) != kAXErrorSuccess || !arr) return NULL;
+  CFArrayRef wins = (CFArrayRef)arr;
+  AXUIElementRef w = NULL;
+  if (CFArrayGetCount(wins) > 0) {
+    w = (AXUIElementRef)CFRetain(CFArrayGetValueAtIndex(wins, 0));
+  }
+  CFRelease(arr);
+  return w;
+}
+
+static CFArrayRef app_windows_retained(AXUIElementRef axApp) {
+  if (!axApp) return NULL;
+  CFTypeRef arr = NULL;
+  if (AXUIElementCopyAttributeValue(axApp, kAXWindowsAttribute, &arr) != kAXErrorSuccess || !arr) return NULL;
+  return (CFArrayRef)arr; // retained
+}
+
+static void focus_window(AXUIElementRef axApp, AXUIElementRef win) {
+  if (!axApp || !win) return;
+  AXUIElementPerformAction(win, kAXRaiseAction);
+  AXUIElementSetAttributeValue(win, kAXMainAttribute, kCFBooleanTrue);
+  AXUIElementSetAttributeValue(win, kAXFocusedAttribute, kCFBooleanTrue);
+  AXUIElementSetAttributeValue(axApp, kAXFocusedWindowAttribute, win);
+  pid_t pid = 0; AXUIElementGetPid(win, &pid);
+  NSRunningApplication *ra = [NSRunningApplication runningApplicationWithProcessIdentifier:pid];
+  if (ra) [ra activateWithOptions:0];
+}
+
+// ---- Send C-<key> to a process (for tmux binds) ----
+// US layout keycodes: 'h' = 4, 'l' = 37.
+static void send_ctrl_key_to_pid(pid_t pid, CGKeyCode key, int times, useconds_t gap_us) {
+  for (int i=0; i<times; i++) {
+    CGEventRef down = CGEventCreateKeyboardEvent(NULL, key, true);
+    CGEventRef up   = CGEventCreateKeyboardEvent(NULL, key, false);
+    CGEventSetFlags(down, kCGEventFlagMaskControl);
+    CGEventSetFlags(up,   kCGEventFlagMaskControl);
+    CGEventPostToPid(pid, down);
+    CGEventPostToPid(pid, up);
+    CFRelease(down); CFRelease(up);
+    if (gap_us) usleep(gap_us);
+  }
+}
+
+// ---------- Window collection for the Go neighbor engine ----------
+// The focused window and the candidate windows (from every allowed
+// terminal app) stay retained between ax_focused_window,
+// ax_collect_windows and ax_focus_index; ax_release drops them.
+static AXUIElementRef g_me = NULL;
+static CFMutableArrayRef g_wins = NULL;
+
+static void ax_release(void) {
+  if (g_wins) { CFRelease(g_wins); g_wins = NULL; }
+  if (g_me)   { CFRelease(g_me);   g_me = NULL; }
+}
+
+// Reports the front app's pid, bundle id and name (caller frees the
+// strings). Returns 0, or 10 when there is no front app.
+static int ax_front_app(int *outPid, char **outBid, char **outName) {
+  *outPid = 0; *outBid = NULL; *outName = NULL;
+  NSRunningApplication *ra = [[NSWorkspace sharedWorkspace] frontmostApplication];
+  pid_t pid = ra ? ra.processIdentifier : 0;
+  if (!ra) {
+    AXUIElementRef axApp = ax_focused_app_retained();
+    if (!axApp) { DBG("denied: could not obtain front app"); return 10; }
+    AXUIElementGetPid(axApp, &pid);
+    CFRelease(axApp);
+    ra = [NSRunningApplication runningApplicationWithProcessIdentifier:pid];
+  }
+  *outPid = pid;
+  if (ra.bundleIdentifier) *outBid = strdup(ra.bundleIdentifier.UTF8String);
+  if (ra.localizedName)    *outName = strdup(ra.localizedName.UTF8String);
+  DBG("frontmost: bid=%s name=%s pid=%d", *outBid ? *outBid : "", *outName ? *outName : "", pid);
+  return 0;
+}
+
+// Finds pid's focused window, filling cur[4] with its x, y, w, h and
+// *outTitle with its title (caller frees). Returns 0, 2 without a focused
+// window or 3 when its rect can't be read.
+static int ax_focused_window(int pid, double *cur, char **outTitle) {
+  *outTitle = NULL;
+  ax_release();
+  AXUIElementRef axApp = AXUIElementCreateApplication(pid);
+  AXUIElementRef meWin = app_focused_window(axApp);
+  CFRelease(axApp);
+  if (!meWin) { DBG("denied: no focused window"); return 2; }
+
+  CGRect meR; if (!ax_get_rect(meWin, &meR)) { CFRelease(meWin); DBG("denied: cannot read current window rect"); return 3; }
+  cur[0] = meR.origin.x; cur[1] = meR.origin.y; cur[2] = meR.size.width; cur[3] = meR.size.height;
+  *outTitle = ax_copy_title(meWin);
+  g_me = meWin;
+  return 0;
+}
+
+// Fills parallel arrays with the pid, bundle id and name of every regular
+// app (caller frees pids, and the strings with ax_free_strings). Returns
+// the count.
+static int ax_list_apps(int **outPids, char ***outBids, char ***outNames) {
+  NSArray<NSRunningApplication *> *apps = [[NSWorkspace sharedWorkspace] runningApplications];
+  NSUInteger max = apps.count > 0 ? apps.count : 1;
+  *outPids = malloc(sizeof(int) * max);
+  *outBids = malloc(sizeof(char *) * max);
+  *outNames = malloc(sizeof(char *) * max);
+  int n = 0;
+  for (NSRunningApplication *ra in apps) {
+    if (ra.activationPolicy != NSApplicationActivationPolicyRegular) continue;
+    (*outPids)[n] = ra.processIdentifier;
+    (*outBids)[n] = strdup(ra.bundleIdentifier ? ra.bundleIdentifier.UTF8String : "");
+    (*outNames)[n] = strdup(ra.localizedName ? ra.localizedName.UTF8String : "");
+    n++;
+  }
+  return n;
+}
+
+// Lists the windows of the apps in pids, front app first, skipping the
+// focused window. Fills 4 doubles per window into *outRects, its title
+// into *outTitles and the index of its app in pids into *outApps (caller
+// frees rects and apps, and titles with ax_free_strings). Returns 0, or 4
+// when the first app's windows can't be listed.
+static int ax_collect_windows(const int *pids, int npids, double **outRects, char ***outTitles, int **outApps, int *outCount) {
+  *outRects = NULL; *outTitles = NULL; *outApps = NULL; *outCount = 0;
+  if (g_wins) { CFRelease(g_wins); g_wins = NULL; }
+
+  int cap = 16, count = 0;
+  double *rects = malloc(sizeof(double) * 4 * cap);
+  char **titles = malloc(sizeof(char *) * cap);
+  int *apps = malloc(sizeof(int) * cap);
+  g_wins = CFArrayCreateMutable(NULL, 0, &kCFTypeArrayCallBacks);
+  for (int a = 0; a < npids; a++) {
+    AXUIElementRef axApp = AXUIElementCreateApplication(pids[a]);
+    // Don't let an unresponsive app stall the hop.
+    if (a > 0) AXUIElementSetMessagingTimeout(axApp, 0.25);
+    CFArrayRef wins = app_windows_retained(axApp);
+    CFRelease(axApp);
+    if (!wins) {
+      if (a == 0) {
+        free(rects); ax_free_strings(titles, count); free(apps);
+        DBG("denied: cannot list windows");
+        return 4;
+      }
+      continue;
+    }
+    CFIndex n = CFArrayGetCount(wins);
+    for (CFIndex i = 0; i < n; i++) {
+      AXUIElementRef w = (AXUIElementRef)CFArrayGetValueAtIndex(wins, i);
+      if (g_me && CFEqual(w, g_me)) continue;
+      CGRect r; if (!ax_get_rect(w, &r)) continue;
+      if (count == cap) {
+        cap *= 2;
+        rects = realloc(rects, sizeof(double) * 4 * cap);
+        titles = realloc(titles, sizeof(char *) * cap);
+        apps = realloc(apps, sizeof(int) * cap);
+      }
+      CFArrayAppendValue(g_wins, w);
+      rects[4*count+0] = r.origin.x;
+      rects[4*count+1] = r.origin.y;
+      rects[4*count+2] = r.size.width;
+      rects[4*count+3] = r.size.height;
+      titles[count] = ax_copy_title(w);
+      apps[count] = a;
+      count++;
+    }
+    CFRelease(wins);
+  }
+
+  *outRects = rects;
+  *outTitles = titles;
+  *outApps = apps;
+  *outCount = count;
+  return 0;
+}
+
+// Fills *outRects with 4 doubles per screen (caller frees), converted from
+// Cocoa's bottom-left origin to the top-left origin AX window rects use.
+static int ax_list_displays(double **outRects) {
+  NSArray<NSScreen *> *screens = [NSScreen screens];
+  int n = (int)screens.count;
+  *outRects = malloc(sizeof(double) * 4 * (n > 0 ? n : 1));
+  if (n == 0) return 0;
+  CGFloat primaryH = NSMaxY(screens[0].frame);
+  for (int i = 0; i < n; i++) {
+    NSRect f = screens[i].frame;
+    (*outRects)[4*i+0] = f.origin.x;
+    (*outRects)[4*i+1] = primaryH - NSMaxY(f);
+    (*outRects)[4*i+2] = f.size.width;
+    (*outRects)[4*i+3] = f.size.height;
+  }
+  return n;
+}
+
+static void ax_focus_index(int idx) {
+  if (!g_wins || idx < 0 || idx >= CFArrayGetCount(g_wins)) return;
+  AXUIElementRef win = (AXUIElementRef)CFArrayGetValueAtIndex(g_wins, idx);
+  pid_t pid = 0; AXUIElementGetPid(win, &pid);
+  AXUIElementRef axApp = AXUIElementCreateApplication(pid);
+  focus_window(axApp, win);
+  CFRelease(axApp);
+}
+
+*/
+import "C"
+
+import (
+	"strconv"
+	"unsafe"
+
+	"github.com/leejonesio/ttyhop/internal/neighbor"
+)
+
+// cgoHopper is the unexported, production implementation of Hopper that calls Cgo functions.
+type cgoHopper struct {
+	hopOptions
+}
+
+// macosBackend drives windows through the Accessibility API. It always
+// matches on macOS; the others are reachable with --backend, e.g. x11 for
+// XQuartz.
+var macosBackend = backend{"macos", func() string { return "running on macOS" }, func() Hopper { return &cgoHopper{} }}
+
+var backends = []backend{macosBackend, swayBackend, hyprlandBackend, niriBackend, x11Backend, tmuxBackend, simBackend}
+
+func (h *cgoHopper) FocusNeighbor(dir Direction, debug bool, doEdge bool) int {
+	h.SetDebug(debug)
+	defer C.ax_release()
+	// Keystroke-based nudge (send_ctrl_key_to_pid) is still available in C
+	// for reference; landing uses tmux IPC.
+	return hop(h, dir, doEdge, h.hopOptions)
+}
+
+func (h *cgoHopper) FocusDisplay(target string, debug bool) int {
+	h.SetDebug(debug)
+	defer C.ax_release()
+	return hopDisplay(h, target)
+}
+
+func (h *cgoHopper) Inspect(dir Direction) (int, []neighbor.Scored, int) {
+	defer C.ax_release()
+	_, best, scored, _, rc := inspect(h, dir, h.hopOptions)
+	return best, scored, rc
+}
+
+// windows lists the other allowed terminal windows: the front app's
+// first, then those of every other allowed app.
+func (h *cgoHopper) windows() (neighbor.Rect, []neighbor.Candidate, int) {
+	if C.ensure_trusted_i() == 0 {
+		dbg("denied: accessibility not trusted")
+		return neighbor.Rect{}, nil, 20
+	}
+	var pid C.int
+	var cBid, cName *C.char
+	if rc := C.ax_front_app(&pid, &cBid, &cName); rc != 0 {
+		return neighbor.Rect{}, nil, int(rc)
+	}
+	front := appInfo{bundle: C.GoString(cBid), name: C.GoString(cName)}
+	C.free(unsafe.Pointer(cBid))
+	C.free(unsafe.Pointer(cName))
+
+	var cur [4]C.double
+	var cTitle *C.char
+	if rc := C.ax_focused_window(pid, &cur[0], &cTitle); rc != 0 {
+		return neighbor.Rect{}, nil, int(rc)
+	}
+	front.title = C.GoString(cTitle)
+	C.free(unsafe.Pointer(cTitle))
+	if !h.isTerminal(front) {
+		dbg("denied: front app %q (%s) is not a terminal", front.name, front.bundle)
+		return neighbor.Rect{}, nil, 1
+	}
+
+	// Only apps the allowlist could take windows from are asked for them.
+	apps, pids := []appInfo{front}, []C.int{pid}
+	var appPids *C.int
+	var bids, names **C.char
+	n := int(C.ax_list_apps(&appPids, &bids, &names))
+	pidVals, bidVals, nameVals := unsafe.Slice(appPids, n), unsafe.Slice(bids, n), unsafe.Slice(names, n)
+	for i := 0; i < n; i++ {
+		a := appInfo{bundle: C.GoString(bidVals[i]), name: C.GoString(nameVals[i])}
+		if pidVals[i] != pid && (h.terminalsNeedTitles() || h.isTerminal(a)) {
+			apps, pids = append(apps, a), append(pids, pidVals[i])
+		}
+	}
+	C.free(unsafe.Pointer(appPids))
+	C.ax_free_strings(bids, C.int(n))
+	C.ax_free_strings(names, C.int(n))
+
+	var rects *C.double
+	var titles **C.char
+	var owners *C.int
+	var count C.int
+	if rc := C.ax_collect_windows(&pids[0], C.int(len(pids)), &rects, &titles, &owners, &count); rc != 0 {
+		return neighbor.Rect{}, nil, int(rc)
+	}
+	defer C.free(unsafe.Pointer(rects))
+	defer C.free(unsafe.Pointer(owners))
+	defer C.ax_free_strings(titles, count)
+
+	vals := unsafe.Slice(rects, int(count)*4)
+	titleVals, ownerVals := unsafe.Slice(titles, int(count)), unsafe.Slice(owners, int(count))
+	var cands []neighbor.Candidate
+	for i := 0; i < int(count); i++ {
+		a := apps[ownerVals[i]]
+		a.title = C.GoString(titleVals[i])
+		if !h.isTerminal(a) {
+			continue
+		}
+		cands = append(cands, neighbor.Candidate{
+			ID:    strconv.Itoa(i),
+			Rect:  neighbor.Rect{X: float64(vals[4*i]), Y: float64(vals[4*i+1]), W: float64(vals[4*i+2]), H: float64(vals[4*i+3])},
+			Title: a.title,
+		})
+	}
+	return neighbor.Rect{X: float64(cur[0]), Y: float64(cur[1]), W: float64(cur[2]), H: float64(cur[3])}, cands, 0
+}
+
+func (h *cgoHopper) displays() []neighbor.Display {
+	var rects *C.double
+	n := int(C.ax_list_displays(&rects))
+	defer C.free(unsafe.Pointer(rects))
+
+	vals := unsafe.Slice(rects, n*4)
+	displays := make([]neighbor.Display, n)
+	for i := range displays {
+		displays[i] = neighbor.Display{
+			ID:   strconv.Itoa(i),
+			Rect: neighbor.Rect{X: float64(vals[4*i]), Y: float64(vals[4*i+1]), W: float64(vals[4*i+2]), H: float64(vals[4*i+3])},
+		}
+	}
+	return displays
+}
+
+func (h *cgoHopper) focus(id string) {
+	idx, err := strconv.Atoi(id)
+	if err != nil {
+		return
+	}
+	C.ax_focus_index(C.int(idx))
+}
+
+func (h *cgoHopper) SetDebug(debug bool) {
+	debugLog = debug
+	cDebug := C.int(0)
+	if debug {
+		cDebug = 1
+	}
+	C.set_debug(cDebug)
+}
+
+func (h *cgoHopper) IsTrusted() bool {
+	return C.ensure_trusted_i() == 1
+}
+
+func (h *cgoHopper) GetFrontAppInfo() (string, string, string) {
+	var bidWS, nameWS *C.char
+	C.front_app_info_ws(&bidWS, &nameWS)
+	if bidWS != nil {
+		defer C.free(unsafe.Pointer(bidWS))
+	}
+	if nameWS != nil {
+		defer C.free(unsafe.Pointer(nameWS))
+	}
+	bid := C.GoString(bidWS)
+	name := C.GoString(nameWS)
+
+	if bid == "" && name == "" {
+		var bidAX, nameAX *C.char
+		C.front_app_info_ax(&bidAX, &nameAX)
+		if bidAX != nil {
+			defer C.free(unsafe.Pointer(bidAX))
+		}
+		if nameAX != nil {
+			defer C.free(unsafe.Pointer(nameAX))
+		}
+		return C.GoString(bidAX), C.GoString(nameAX), "AX"
+	}
+	return bid, name, "WS"
+}
@@ -0,0 +1,146 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/leejonesio/ttyhop/internal/hyprland"
+	"github.com/leejonesio/ttyhop/internal/neighbor"
+)
+
+// hyprlandHopper hops between terminal windows on Hyprland, reading
+// j/clients and j/activewindow from the request socket of the instance in
+// $HYPRLAND_INSTANCE_SIGNATURE and focusing with focuswindow.
+type hyprlandHopper struct {
+	hopOptions
+}
+
+func newHyprlandHopper() *hyprlandHopper {
+	return &hyprlandHopper{}
+}
+
+func (h *hyprlandHopper) FocusNeighbor(dir Direction, debug bool, doEdge bool) int {
+	h.SetDebug(debug)
+	return hop(h, dir, doEdge, h.hopOptions)
+}
+
+func (h *hyprlandHopper) FocusDisplay(target string, debug bool) int {
+	h.SetDebug(debug)
+	return hopDisplay(h, target)
+}
+
+func (h *hyprlandHopper) Inspect(dir Direction) (int, []neighbor.Scored, int) {
+	_, best, scored, _, rc := inspect(h, dir, h.hopOptions)
+	return best, scored, rc
+}
+
+// hyprlandInfo identifies a window for the terminal allowlist. Hyprland's
+// class is the app_id of Wayland clients and WM_CLASS of X11 ones.
+func hyprlandInfo(c hyprland.Client) appInfo {
+	return appInfo{class: c.Class, appID: c.Class, title: c.Title}
+}
+
+// windows lists the other allowed terminal windows on workspaces some
+// monitor is showing, most recently focused first. Exit codes follow the
+// macOS backend: 10 when the socket can't be reached, 2 without an active
+// window, 1 when it isn't a terminal and 4 when the clients or monitors
+// can't be listed.
+func (h *hyprlandHopper) windows() (neighbor.Rect, []neighbor.Candidate, int) {
+	active, err := hyprland.ActiveWindow()
+	if err != nil {
+		dbg("denied: %v", err)
+		return neighbor.Rect{}, nil, 10
+	}
+	if active.Address == "" {
+		dbg("denied: no focused window")
+		return neighbor.Rect{}, nil, 2
+	}
+	if !h.isTerminal(hyprlandInfo(active)) {
+		dbg("denied: front window is %q, not a terminal", active.Class)
+		return neighbor.Rect{}, nil, 1
+	}
+	clients, err := hyprland.Clients()
+	if err != nil {
+		dbg("denied: cannot list windows: %v", err)
+		return neighbor.Rect{}, nil, 4
+	}
+	mons, err := hyprland.Monitors()
+	if err != nil {
+		dbg("denied: cannot list monitors: %v", err)
+		return neighbor.Rect{}, nil, 4
+	}
+	visible := func(ws int) bool {
+		for _, m := range mons {
+			if !m.Disabled && m.Shows(ws) {
+				return true
+			}
+		}
+		return false
+	}
+
+	sort.SliceStable(clients, func(i, j int) bool {
+		return clients[i].FocusHistoryID < clients[j].FocusHistoryID
+	})
+	var cands []neighbor.Candidate
+	for _, c := range clients {
+		if c.Address == active.Address || !h.isTerminal(hyprlandInfo(c)) {
+			continue
+		}
+		if !c.Mapped || c.Hidden || !visible(c.Workspace.ID) {
+			continue
+		}
+		cands = append(cands, neighbor.Candidate{ID: c.Address, Rect: hyprlandRect(c), Title: c.Title})
+	}
+	return hyprlandRect(active), cands, 0
+}
+
+func hyprlandRect(c hyprland.Client) neighbor.Rect {
+	return neighbor.Rect{X: float64(c.At[0]), Y: float64(c.At[1]), W: float64(c.Size[0]), H: float64(c.Size[1])}
+}
+
+func (h *hyprlandHopper) displays() []neighbor.Display {
+	mons, err := hyprland.Monitors()
+	if err != nil {
+		return nil
+	}
+	var displays []neighbor.Display
+	for _, m := range mons {
+		if m.Disabled {
+			continue
+		}
+		w, hgt := m.LogicalSize()
+		displays = append(displays, neighbor.Display{ID: m.Name, Rect: neighbor.Rect{X: float64(m.X), Y: float64(m.Y), W: w, H: hgt}})
+	}
+	return displays
+}
+
+func (h *hyprlandHopper) focus(id string) {
+	if !strings.HasPrefix(id, "0x") {
+		return
+	}
+	if err := hyprland.Dispatch("focuswindow address:" + id); err != nil {
+		dbg("%v", err)
+	}
+}
+
+func (h *hyprlandHopper) SetDebug(debug bool) {
+	debugLog = debug
+}
+
+// IsTrusted reports whether the request socket answers.
+func (h *hyprlandHopper) IsTrusted() bool {
+	_, err := hyprland.ActiveWindow()
+	return err == nil
+}
+
+// GetFrontAppInfo returns the active window's class and title.
+func (h *hyprlandHopper) GetFrontAppInfo() (string, string, string) {
+	active, err := hyprland.ActiveWindow()
+	if err != nil {
+		return "", "", "hyprland"
+	}
+	return active.Class, active.Title, "hyprland"
+}
@@ -0,0 +1,162 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/leejonesio/ttyhop/internal/hyprland/hyprlandtest"
+)
+
+// hyprlandServer replays the replies captured in testdata: Alacritty
+// windows 0x55d1c0a3e2f0 (active, left half of DP-1) and 0x55d1c0b41a20
+// (top right), Firefox below it, a terminal grouped behind 0x55d1c0b41a20,
+// one on hidden workspace 4 and one on HDMI-A-1. edit, if set, rewrites a
+// reply first.
+func hyprlandServer(t *testing.T, edit func(req, reply string) string) *hyprlandtest.Server {
+	t.Helper()
+	isolateChain(t)
+	replies := map[string]string{}
+	for req, file := range map[string]string{
+		"j/clients":      "hyprland_clients.json",
+		"j/activewindow": "hyprland_activewindow.json",
+		"j/monitors":     "hyprland_monitors.json",
+	} {
+		b, err := os.ReadFile("testdata/" + file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		replies[req] = string(b)
+		if edit != nil {
+			replies[req] = edit(req, replies[req])
+		}
+	}
+	return hyprlandtest.NewServer(t, replies)
+}
+
+func TestHyprlandHopper(t *testing.T) {
+	t.Run("Neighbors", func(t *testing.T) {
+		srv := hyprlandServer(t, nil)
+		h := newHyprlandHopper()
+		if rc := h.FocusNeighbor(DirRight, false, false); rc != 0 {
+			t.Fatalf("expected rc 0, got %d", rc)
+		}
+		if rc := h.FocusNeighbor(DirLeft, false, false); rc != 5 {
+			t.Errorf("expected rc 5 at the left edge, got %d", rc)
+		}
+		if d := srv.Dispatches(); strings.Join(d, "; ") != "focuswindow address:0x55d1c0b41a20" {
+			t.Errorf("expected 0x55d1c0b41a20 to be focused, got %q", d)
+		}
+	})
+
+	t.Run("Inspect", func(t *testing.T) {
+		hyprlandServer(t, nil)
+		_, scored, rc := newHyprlandHopper().Inspect(DirRight)
+		var ids []string
+		for _, s := range scored {
+			ids = append(ids, s.ID)
+		}
+		// Firefox, the grouped terminal and hidden workspace 4 are left out.
+		if rc != 0 || strings.Join(ids, ",") != "0x55d1c0b41a20,0x55d1c0e2f340" {
+			t.Errorf("unexpected candidates %q (rc %d)", ids, rc)
+		}
+	})
+
+	t.Run("Displays", func(t *testing.T) {
+		srv := hyprlandServer(t, nil)
+		// DP-1 is 2560x1440 at scale 1.25, so HDMI-A-1 starts at x=2048.
+		if rc := newHyprlandHopper().FocusDisplay("next", false); rc != 0 {
+			t.Fatalf("expected rc 0, got %d", rc)
+		}
+		if d := srv.Dispatches(); strings.Join(d, "; ") != "focuswindow address:0x55d1c0e2f340" {
+			t.Errorf("expected the terminal on HDMI-A-1 to be focused, got %q", d)
+		}
+	})
+
+	t.Run("LandsOnEdgePane", func(t *testing.T) {
+		hyprlandServer(t, nil)
+		t.Setenv("TMUX_TMPDIR", t.TempDir())
+		originalRunTmux := runTmuxCmd
+		defer func() { runTmuxCmd = originalRunTmux }()
+		var selected string
+		runTmuxCmd = func(args ...string) (string, error) {
+			cmd := strings.Join(args, " ")
+			switch {
+			case strings.HasPrefix(cmd, "list-clients"):
+				return "/dev/pts/3 0 1627840934", nil
+			case strings.HasPrefix(cmd, "display -p -t /dev/pts/3"):
+				return "@1 0", nil
+			case strings.HasPrefix(cmd, "list-panes -t @1"):
+				return "%1 1 0\n%2 0 1", nil
+			case strings.HasPrefix(cmd, "select-pane -t"):
+				selected = args[2]
+			}
+			return "", nil
+		}
+		h := newHyprlandHopper()
+		h.SetWaitMs(50)
+		if rc := h.FocusNeighbor(DirRight, false, true); rc != 0 {
+			t.Fatalf("expected rc 0, got %d", rc)
+		}
+		if selected != "%1" {
+			t.Errorf("expected the leftmost tmux pane after the hop, got %q", selected)
+		}
+	})
+
+	t.Run("ExitCodes", func(t *testing.T) {
+		tests := []struct {
+			name  string
+			edit  func(req, reply string) string
+			noEnv bool
+			rc    int
+		}{
+			{"NotTerminal", func(req, reply string) string {
+				if req == "j/activewindow" {
+					return strings.Replace(reply, `"class": "Alacritty"`, `"class": "firefox"`, 1)
+				}
+				return reply
+			}, false, 1},
+			{"NoActiveWindow", func(req, reply string) string {
+				if req == "j/activewindow" {
+					return "{}"
+				}
+				return reply
+			}, false, 2},
+			{"NoClients", func(req, reply string) string {
+				if req == "j/clients" {
+					return "unknown request"
+				}
+				return reply
+			}, false, 4},
+			{"NoSocket", nil, true, 10},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				srv := hyprlandServer(t, tt.edit)
+				if tt.noEnv {
+					t.Setenv("HYPRLAND_INSTANCE_SIGNATURE", "missing")
+				}
+				if rc := newHyprlandHopper().FocusNeighbor(DirRight, false, false); rc != tt.rc {
+					t.Errorf("expected rc %d, got %d", tt.rc, rc)
+				}
+				if d := srv.Dispatches(); len(d) != 0 {
+					t.Errorf("expected no dispatch, got %q", d)
+				}
+			})
+		}
+	})
+
+	t.Run("FrontAppInfo", func(t *testing.T) {
+		hyprlandServer(t, nil)
+		h := newHyprlandHopper()
+		if !h.IsTrusted() {
+			t.Error("expected the fake socket to answer")
+		}
+		if class, title, src := h.GetFrontAppInfo(); class != "Alacritty" || title != "zsh" || src != "hyprland" {
+			t.Errorf("unexpected front app %q %q %q", class, title, src)
+		}
+	})
+}
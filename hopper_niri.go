@@ -0,0 +1,303 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package main
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/leejonesio/ttyhop/internal/neighbor"
+	"github.com/leejonesio/ttyhop/internal/niri"
+)
+
+// niriHopper hops between terminal windows on niri over the socket in
+// $NIRI_SOCKET. niri lays each workspace out as an endless strip of
+// columns, so the focused workspace is unrolled into one virtual row: its
+// off-screen columns sit beside the visible ones, its output is widened to
+// hold them and the outputs beyond are pushed aside. East is then the next
+// column even when it is scrolled out of view.
+type niriHopper struct {
+	hopOptions
+	snap *niriSnapshot
+}
+
+// niriSnapshot is the unrolled layout for one operation.
+type niriSnapshot struct {
+	cur      neighbor.Rect
+	cands    []neighbor.Candidate
+	displays []neighbor.Display
+	focused  niri.Window
+}
+
+func newNiriHopper() *niriHopper {
+	return &niriHopper{}
+}
+
+func (h *niriHopper) release() {
+	h.snap = nil
+}
+
+func (h *niriHopper) FocusNeighbor(dir Direction, debug bool, doEdge bool) int {
+	h.SetDebug(debug)
+	defer h.release()
+	return hop(h, dir, doEdge, h.hopOptions)
+}
+
+func (h *niriHopper) FocusDisplay(target string, debug bool) int {
+	h.SetDebug(debug)
+	defer h.release()
+	return hopDisplay(h, target)
+}
+
+func (h *niriHopper) Inspect(dir Direction) (int, []neighbor.Scored, int) {
+	defer h.release()
+	_, best, scored, _, rc := inspect(h, dir, h.hopOptions)
+	return best, scored, rc
+}
+
+// niriInfo identifies a window for the terminal allowlist.
+func niriInfo(w niri.Window) appInfo {
+	return appInfo{appID: w.AppID, title: w.Title}
+}
+
+// windows lists the other allowed terminal windows on the workspaces the
+// outputs are showing. Exit codes follow the macOS backend: 10 when the
+// socket can't be reached, 2 without a focused window, 1 when it isn't a
+// terminal, 3 when niri doesn't report its layout (before 25.05) and 4 when
+// the workspaces or outputs can't be listed.
+func (h *niriHopper) windows() (neighbor.Rect, []neighbor.Candidate, int) {
+	wins, err := niri.Windows()
+	if err != nil {
+		dbg("denied: %v", err)
+		return neighbor.Rect{}, nil, 10
+	}
+	var focused *niri.Window
+	for i := range wins {
+		if wins[i].IsFocused {
+			focused = &wins[i]
+		}
+	}
+	if focused == nil {
+		dbg("denied: no focused window")
+		return neighbor.Rect{}, nil, 2
+	}
+	if !h.isTerminal(niriInfo(*focused)) {
+		dbg("denied: front window is %q, not a terminal", focused.AppID)
+		return neighbor.Rect{}, nil, 1
+	}
+	if focused.Layout == nil || focused.WorkspaceID == nil {
+		dbg("denied: niri reports no layout for the focused window; 25.05 or newer is needed")
+		return neighbor.Rect{}, nil, 3
+	}
+	wss, err := niri.Workspaces()
+	if err != nil {
+		dbg("denied: cannot list workspaces: %v", err)
+		return neighbor.Rect{}, nil, 4
+	}
+	outs, err := niri.Outputs()
+	if err != nil {
+		dbg("denied: cannot list outputs: %v", err)
+		return neighbor.Rect{}, nil, 4
+	}
+	snap := h.unroll(wins, *focused, wss, outs)
+	if snap == nil {
+		dbg("denied: focused workspace is not on an output")
+		return neighbor.Rect{}, nil, 3
+	}
+	h.snap = snap
+	return snap.cur, snap.cands, 0
+}
+
+// unroll lays the focused workspace's columns out side by side around
+// the focused window's on-screen position and places the windows visible
+// on other outputs around them. It returns nil if the focused workspace
+// isn't on an enabled output.
+func (h *niriHopper) unroll(wins []niri.Window, focused niri.Window, wss []niri.Workspace, outs map[string]niri.Output) *niriSnapshot {
+	wsOutput := map[uint64]string{}
+	active := map[uint64]bool{}
+	for _, ws := range wss {
+		wsOutput[ws.ID] = ws.Output
+		active[ws.ID] = ws.IsActive
+	}
+	home := *focused.WorkspaceID
+	fo, ok := outs[wsOutput[home]]
+	if !ok || fo.Logical == nil {
+		return nil
+	}
+	origin := *fo.Logical
+
+	// Virtual column positions on the focused workspace, from 0.
+	colW := map[int]float64{}
+	var cols []int
+	for _, w := range wins {
+		if w.WorkspaceID == nil || *w.WorkspaceID != home || w.Layout == nil || w.Layout.PosInScrollingLayout == nil {
+			continue
+		}
+		c := w.Layout.PosInScrollingLayout[0]
+		if _, seen := colW[c]; !seen {
+			cols = append(cols, c)
+		}
+		colW[c] = max(colW[c], w.Layout.TileSize[0])
+	}
+	sort.Ints(cols)
+	colX := map[int]float64{}
+	var stripW float64
+	for _, c := range cols {
+		colX[c] = stripW
+		stripW += colW[c]
+	}
+	tileY := func(w niri.Window) float64 {
+		var y float64
+		for _, o := range wins {
+			if o.WorkspaceID == nil || *o.WorkspaceID != home || o.Layout == nil || o.Layout.PosInScrollingLayout == nil {
+				continue
+			}
+			if p := o.Layout.PosInScrollingLayout; p[0] == w.Layout.PosInScrollingLayout[0] && p[1] < w.Layout.PosInScrollingLayout[1] {
+				y += o.Layout.TileSize[1]
+			}
+		}
+		return y
+	}
+
+	// Anchor the strip on a tile that is on screen, preferring the focused one.
+	anchored := false
+	var dx, dy float64
+	anchors := append([]niri.Window{focused}, wins...)
+	for _, w := range anchors {
+		if w.WorkspaceID == nil || *w.WorkspaceID != home || w.Layout == nil ||
+			w.Layout.PosInScrollingLayout == nil || w.Layout.TilePosInWorkspaceView == nil {
+			continue
+		}
+		dx = origin.X + w.Layout.TilePosInWorkspaceView[0] - colX[w.Layout.PosInScrollingLayout[0]]
+		dy = origin.Y + w.Layout.TilePosInWorkspaceView[1] - tileY(w)
+		anchored = true
+		break
+	}
+
+	// Widen the focused output over the strip and push its neighbors aside.
+	left, right := origin.X, origin.X+origin.Width
+	if anchored {
+		left, right = min(left, dx), max(right, dx+stripW)
+	}
+	growL, growR := origin.X-left, right-(origin.X+origin.Width)
+	shifted := map[string]niri.Logical{}
+	for name, o := range outs {
+		if o.Logical == nil {
+			continue
+		}
+		l := *o.Logical
+		switch {
+		case name == wsOutput[home]:
+			l.X, l.Width = left, right-left
+		case l.X >= origin.X+origin.Width:
+			l.X += growR
+		case l.X+l.Width <= origin.X:
+			l.X -= growL
+		}
+		shifted[name] = l
+	}
+
+	snap := &niriSnapshot{focused: focused}
+	var names []string
+	for name := range shifted {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		l := shifted[name]
+		snap.displays = append(snap.displays, neighbor.Display{ID: name, Rect: neighbor.Rect{X: l.X, Y: l.Y, W: l.Width, H: l.Height}})
+	}
+
+	place := func(w niri.Window) (neighbor.Rect, bool) {
+		if w.Layout == nil || w.WorkspaceID == nil {
+			return neighbor.Rect{}, false
+		}
+		size := w.Layout.TileSize
+		if *w.WorkspaceID == home && anchored && w.Layout.PosInScrollingLayout != nil {
+			return neighbor.Rect{X: dx + colX[w.Layout.PosInScrollingLayout[0]], Y: dy + tileY(w), W: size[0], H: size[1]}, true
+		}
+		if w.Layout.TilePosInWorkspaceView == nil {
+			return neighbor.Rect{}, false
+		}
+		l, ok := shifted[wsOutput[*w.WorkspaceID]]
+		if !ok {
+			return neighbor.Rect{}, false
+		}
+		pos := w.Layout.TilePosInWorkspaceView
+		if *w.WorkspaceID == home {
+			// Floating windows keep their place on the unwidened output.
+			return neighbor.Rect{X: origin.X + pos[0], Y: origin.Y + pos[1], W: size[0], H: size[1]}, true
+		}
+		return neighbor.Rect{X: l.X + pos[0], Y: l.Y + pos[1], W: size[0], H: size[1]}, true
+	}
+
+	snap.cur, _ = place(focused)
+	// Floating windows are in front of the tiles, and the focused
+	// workspace in front of the other outputs.
+	rank := func(w niri.Window) int {
+		switch {
+		case *w.WorkspaceID == home && w.IsFloating:
+			return 0
+		case *w.WorkspaceID == home:
+			return 1
+		}
+		return 2
+	}
+	var others []niri.Window
+	for _, w := range wins {
+		if w.ID == focused.ID || !h.isTerminal(niriInfo(w)) || w.WorkspaceID == nil || !active[*w.WorkspaceID] {
+			continue
+		}
+		others = append(others, w)
+	}
+	sort.SliceStable(others, func(i, j int) bool { return rank(others[i]) < rank(others[j]) })
+	for _, w := range others {
+		if r, ok := place(w); ok {
+			snap.cands = append(snap.cands, neighbor.Candidate{ID: strconv.FormatUint(w.ID, 10), Rect: r, Title: w.Title})
+		}
+	}
+	return snap
+}
+
+// displays returns the outputs in the unrolled layout windows built.
+func (h *niriHopper) displays() []neighbor.Display {
+	if h.snap == nil {
+		return nil
+	}
+	return h.snap.displays
+}
+
+func (h *niriHopper) focus(id string) {
+	n, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return
+	}
+	if err := niri.FocusWindow(n); err != nil {
+		dbg("%v", err)
+	}
+}
+
+func (h *niriHopper) SetDebug(debug bool) {
+	debugLog = debug
+}
+
+// IsTrusted reports whether the socket answers.
+func (h *niriHopper) IsTrusted() bool {
+	_, err := niri.Outputs()
+	return err == nil
+}
+
+// GetFrontAppInfo returns the focused window's app_id and title.
+func (h *niriHopper) GetFrontAppInfo() (string, string, string) {
+	wins, err := niri.Windows()
+	if err != nil {
+		return "", "", "niri"
+	}
+	for _, w := range wins {
+		if w.IsFocused {
+			return w.AppID, w.Title, "niri"
+		}
+	}
+	return "", "", "niri"
+}
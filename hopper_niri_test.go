@@ -0,0 +1,140 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/leejonesio/ttyhop/internal/niri/niritest"
+)
+
+// niriServer scripts the layout in testdata. Workspace 1 on DP-1 is
+// scrolled to show columns 2 (terminals 12, focused, over 13) and 3
+// (Firefox); columns 1 (terminal 11) and 4 (terminal 15) are off-screen,
+// and terminal 16 floats. HDMI-A-1 to the right shows terminal 31, and
+// terminal 21 is on a hidden workspace. edit, if set, rewrites a reply.
+func niriServer(t *testing.T, edit func(req, reply string) string) *niritest.Server {
+	t.Helper()
+	isolateChain(t)
+	replies := map[string]string{}
+	for req, file := range map[string]string{
+		"Windows":    "niri_windows.json",
+		"Workspaces": "niri_workspaces.json",
+		"Outputs":    "niri_outputs.json",
+	} {
+		b, err := os.ReadFile("testdata/" + file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		replies[req] = string(b)
+		if edit != nil {
+			replies[req] = edit(req, replies[req])
+		}
+	}
+	return niritest.NewServer(t, replies)
+}
+
+func niriFocus(id string) string {
+	return `{"Action":{"FocusWindow":{"id":` + id + `}}}`
+}
+
+func TestNiriHopper(t *testing.T) {
+	t.Run("NextColumnOffScreen", func(t *testing.T) {
+		for _, tt := range []struct {
+			dir  Direction
+			want string
+		}{
+			// Column 4 is off-screen, beyond Firefox, yet comes before HDMI-A-1.
+			{DirRight, "15"},
+			{DirLeft, "11"},
+			{DirDown, "13"},
+		} {
+			srv := niriServer(t, nil)
+			if rc := newNiriHopper().FocusNeighbor(tt.dir, false, false); rc != 0 {
+				t.Fatalf("%s: expected rc 0, got %d", tt.dir, rc)
+			}
+			if a := srv.Actions(); len(a) != 1 || a[0] != niriFocus(tt.want) {
+				t.Errorf("%s: expected window %s to be focused, got %q", tt.dir, tt.want, a)
+			}
+		}
+	})
+
+	t.Run("Inspect", func(t *testing.T) {
+		niriServer(t, nil)
+		_, scored, rc := newNiriHopper().Inspect(DirRight)
+		var ids []string
+		for _, s := range scored {
+			ids = append(ids, s.ID)
+		}
+		// Floating first, then the strip, then the other output; Firefox
+		// and the hidden workspace are left out.
+		if rc != 0 || strings.Join(ids, ",") != "16,11,13,15,31" {
+			t.Errorf("unexpected candidates %q (rc %d)", ids, rc)
+		}
+	})
+
+	t.Run("Displays", func(t *testing.T) {
+		srv := niriServer(t, nil)
+		if rc := newNiriHopper().FocusDisplay("next", false); rc != 0 {
+			t.Fatalf("expected rc 0, got %d", rc)
+		}
+		if a := srv.Actions(); len(a) != 1 || a[0] != niriFocus("31") {
+			t.Errorf("expected window 31 on HDMI-A-1 to be focused, got %q", a)
+		}
+	})
+
+	t.Run("ExitCodes", func(t *testing.T) {
+		swap := func(target, old, new string) func(req, reply string) string {
+			return func(req, reply string) string {
+				if req == target {
+					return strings.ReplaceAll(reply, old, new)
+				}
+				return reply
+			}
+		}
+		tests := []struct {
+			name   string
+			edit   func(req, reply string) string
+			socket string
+			rc     int
+		}{
+			{"NotTerminal", func(req, reply string) string {
+				reply = strings.Replace(reply, `"is_focused": true`, `"is_focused": false`, 1)
+				return strings.Replace(reply, `"app_id": "firefox", "pid": 1870, "workspace_id": 1, "is_focused": false`,
+					`"app_id": "firefox", "pid": 1870, "workspace_id": 1, "is_focused": true`, 1)
+			}, "", 1},
+			{"NoFocusedWindow", swap("Windows", `"is_focused": true`, `"is_focused": false`), "", 2},
+			{"NoLayout", swap("Windows", `"layout"`, `"old_layout"`), "", 3},
+			{"NoWorkspaces", swap("Workspaces", "[", ""), "", 4},
+			{"NoSocket", nil, "/nonexistent/niri.sock", 10},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				srv := niriServer(t, tt.edit)
+				if tt.socket != "" {
+					t.Setenv("NIRI_SOCKET", tt.socket)
+				}
+				if rc := newNiriHopper().FocusNeighbor(DirRight, false, false); rc != tt.rc {
+					t.Errorf("expected rc %d, got %d", tt.rc, rc)
+				}
+				if a := srv.Actions(); len(a) != 0 {
+					t.Errorf("expected no action, got %q", a)
+				}
+			})
+		}
+	})
+
+	t.Run("FrontAppInfo", func(t *testing.T) {
+		niriServer(t, nil)
+		h := newNiriHopper()
+		if !h.IsTrusted() {
+			t.Error("expected the stand-in socket to answer")
+		}
+		if app, title, src := h.GetFrontAppInfo(); app != "Alacritty" || title != "zsh" || src != "niri" {
+			t.Errorf("unexpected front app %q %q %q", app, title, src)
+		}
+	})
+}
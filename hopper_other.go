@@ -0,0 +1,11 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+//go:build !darwin
+
+package main
+
+// backends are tried in this order when --backend is auto: a compositor
+// that advertises its own socket wins over plain X11, and tmux alone is
+// the last resort. sim is only used when asked for.
+var backends = []backend{swayBackend, hyprlandBackend, niriBackend, x11Backend, tmuxBackend, simBackend}
@@ -0,0 +1,302 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/leejonesio/ttyhop/internal/neighbor"
+)
+
+// simOut receives the sim backend's report.
+var simOut io.Writer = os.Stdout
+
+// simLayout describes a desktop for the sim backend: its displays and its
+// windows, front-most first, each with the tmux panes it shows.
+//
+//	{
+//	  "displays": [{"id": "main", "rect": {"x": 0, "y": 0, "w": 1920, "h": 1080}}],
+//	  "windows": [
+//	    {"id": "1", "app": "Alacritty", "title": "zsh", "focused": true,
+//	     "rect": {"x": 0, "y": 0, "w": 960, "h": 1080},
+//	     "tmux": {"width": 120, "height": 60, "panes": [
+//	       {"id": "%1", "left": 0, "top": 0, "width": 60, "height": 60, "active": true},
+//	       {"id": "%2", "left": 61, "top": 0, "width": 59, "height": 60}]}}
+//	  ]
+//	}
+type simLayout struct {
+	Displays []simDisplay `json:"displays"`
+	Windows  []*simWindow `json:"windows"`
+}
+
+type simRect struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+	W float64 `json:"w"`
+	H float64 `json:"h"`
+}
+
+func (r simRect) rect() neighbor.Rect {
+	return neighbor.Rect{X: r.X, Y: r.Y, W: r.W, H: r.H}
+}
+
+type simDisplay struct {
+	ID   string  `json:"id"`
+	Rect simRect `json:"rect"`
+}
+
+type simWindow struct {
+	ID      string         `json:"id"`
+	App     string         `json:"app"`
+	Bundle  string         `json:"bundle"`
+	Title   string         `json:"title"`
+	Rect    simRect        `json:"rect"`
+	Focused bool           `json:"focused"`
+	Tmux    *simTmuxWindow `json:"tmux"`
+}
+
+// simTmuxWindow is the tmux window a terminal shows, sized in cells.
+type simTmuxWindow struct {
+	Width  int        `json:"width"`
+	Height int        `json:"height"`
+	Zoomed bool       `json:"zoomed"`
+	Panes  []*simPane `json:"panes"`
+
+	id string
+}
+
+type simPane struct {
+	ID      string `json:"id"`
+	Left    int    `json:"left"`
+	Top     int    `json:"top"`
+	Width   int    `json:"width"`
+	Height  int    `json:"height"`
+	Active  bool   `json:"active"`
+	Command string `json:"command"`
+}
+
+// loadSimLayout reads a layout file and fills in defaults: window ids by
+// position, pane ids in order, and "zsh" as every pane's command.
+func loadSimLayout(path string) (*simLayout, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var l simLayout
+	if err := json.Unmarshal(b, &l); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	n := 0
+	for i, w := range l.Windows {
+		if w.ID == "" {
+			w.ID = strconv.Itoa(i + 1)
+		}
+		if w.Tmux == nil {
+			continue
+		}
+		if len(w.Tmux.Panes) == 0 {
+			return nil, fmt.Errorf("%s: window %s: tmux has no panes", path, w.ID)
+		}
+		w.Tmux.id = "@" + strconv.Itoa(i+1)
+		for _, p := range w.Tmux.Panes {
+			n++
+			if p.ID == "" {
+				p.ID = "%" + strconv.Itoa(n)
+			}
+			if p.Command == "" {
+				p.Command = "zsh"
+			}
+		}
+		w.Tmux.activate(w.activePane())
+	}
+	return &l, nil
+}
+
+// simHopper runs the whole hop against a simLayout instead of a display:
+// window picks go through the neighbor engine and pane moves and landing
+// through simTmux. It prints what would end up focused.
+type simHopper struct {
+	hopOptions
+	layout  *simLayout
+	restore []func()
+}
+
+func newSimHopper() *simHopper {
+	return &simHopper{}
+}
+
+// simEnv lists the variables load changes: tmux's, and those through which
+// a dry run could reach a real nvim, kitty, WezTerm, zellij, screen or
+// Emacs.
+var simEnv = []string{"TMUX", "TMUX_PANE", "NVIM", "KITTY_WINDOW_ID", "KITTY_LISTEN_ON", "WEZTERM_PANE", "ZELLIJ", "ZELLIJ_SESSION_NAME", "STY", "EMACS_SOCKET_NAME"}
+
+// load reads --layout once and puts simTmux in place of tmux, with $TMUX
+// pointing into the focused window when it has panes, until cleanup. Bad
+// layouts exit 64.
+func (h *simHopper) load() int {
+	if h.layout != nil {
+		return 0
+	}
+	if h.layoutPath == "" {
+		fmt.Fprintln(os.Stderr, "ttyhop: the sim backend needs --layout FILE")
+		return 64
+	}
+	l, err := loadSimLayout(h.layoutPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ttyhop: layout:", err)
+		return 64
+	}
+	h.layout = l
+	sim := &simTmux{layout: l, origin: h.focused()}
+	originalRunTmux := runTmuxCmd
+	runTmuxCmd = sim.run
+	h.restore = append(h.restore, func() { runTmuxCmd = originalRunTmux })
+	for _, v := range simEnv {
+		if s, ok := os.LookupEnv(v); ok {
+			h.restore = append(h.restore, func() { _ = os.Setenv(v, s) })
+		} else {
+			h.restore = append(h.restore, func() { _ = os.Unsetenv(v) })
+		}
+		// A dry run must not move focus in a real nvim, kitty, WezTerm,
+		// zellij or screen.
+		_ = os.Unsetenv(v)
+	}
+	if sim.origin != nil && sim.origin.Tmux != nil {
+		_ = os.Setenv("TMUX", "/dev/sim/tmux,0,0")
+		_ = os.Setenv("TMUX_PANE", sim.origin.activePane().ID)
+	}
+	// Nor in a real Emacs: point emacsclient at a socket that isn't there.
+	_ = os.Setenv("EMACS_SOCKET_NAME", "/dev/null/ttyhop-sim")
+	return 0
+}
+
+// cleanup puts back the tmux runner and environment load replaced.
+func (h *simHopper) cleanup() {
+	for _, f := range h.restore {
+		f()
+	}
+	h.restore, h.layout = nil, nil
+}
+
+func (h *simHopper) focused() *simWindow {
+	for _, w := range h.layout.Windows {
+		if w.Focused {
+			return w
+		}
+	}
+	return nil
+}
+
+func (h *simHopper) FocusNeighbor(dir Direction, debug bool, doEdge bool) int {
+	h.SetDebug(debug)
+	if rc := h.load(); rc != 0 {
+		return rc
+	}
+	return h.report(hop(h, dir, doEdge, h.hopOptions))
+}
+
+func (h *simHopper) FocusDisplay(target string, debug bool) int {
+	h.SetDebug(debug)
+	if rc := h.load(); rc != 0 {
+		return rc
+	}
+	return h.report(hopDisplay(h, target))
+}
+
+// report prints the window and pane that would be focused after a hop
+// that returned rc.
+func (h *simHopper) report(rc int) int {
+	if rc != 0 {
+		fmt.Fprintf(simOut, "no move (exit code %d)\n", rc)
+		return rc
+	}
+	w := h.focused()
+	if w == nil {
+		return rc
+	}
+	fmt.Fprintf(simOut, "window=%s title=%q", w.ID, w.Title)
+	if p := w.activePane(); p != nil {
+		fmt.Fprintf(simOut, " pane=%s", p.ID)
+		if w.Tmux.Zoomed {
+			fmt.Fprint(simOut, " zoomed")
+		}
+	}
+	fmt.Fprintln(simOut)
+	return rc
+}
+
+func (h *simHopper) Inspect(dir Direction) (int, []neighbor.Scored, int) {
+	if rc := h.load(); rc != 0 {
+		return -1, nil, rc
+	}
+	_, best, scored, _, rc := inspect(h, dir, h.hopOptions)
+	return best, scored, rc
+}
+
+// info identifies a window for the terminal allowlist; its app stands in
+// for every id a real platform would report.
+func (w *simWindow) info() appInfo {
+	return appInfo{bundle: w.Bundle, name: w.App, class: w.App, appID: w.App, title: w.Title}
+}
+
+// windows lists the other allowed terminal windows in file order. Exit
+// codes follow the real backends: 2 without a focused window and 1 when it
+// isn't a terminal.
+func (h *simHopper) windows() (neighbor.Rect, []neighbor.Candidate, int) {
+	cur := h.focused()
+	if cur == nil {
+		dbg("denied: no focused window")
+		return neighbor.Rect{}, nil, 2
+	}
+	if !h.isTerminal(cur.info()) {
+		dbg("denied: front window is %q, not a terminal", cur.App)
+		return neighbor.Rect{}, nil, 1
+	}
+	var cands []neighbor.Candidate
+	for _, w := range h.layout.Windows {
+		if w != cur && h.isTerminal(w.info()) {
+			cands = append(cands, neighbor.Candidate{ID: w.ID, Rect: w.Rect.rect(), Title: w.Title})
+		}
+	}
+	return cur.Rect.rect(), cands, 0
+}
+
+func (h *simHopper) displays() []neighbor.Display {
+	var displays []neighbor.Display
+	for _, d := range h.layout.Displays {
+		displays = append(displays, neighbor.Display{ID: d.ID, Rect: d.Rect.rect()})
+	}
+	return displays
+}
+
+func (h *simHopper) focus(id string) {
+	for _, w := range h.layout.Windows {
+		w.Focused = w.ID == id
+	}
+	dbg("sim: focused window %s", id)
+}
+
+func (h *simHopper) SetDebug(debug bool) {
+	debugLog = debug
+}
+
+// IsTrusted always succeeds: the sim needs no permissions.
+func (h *simHopper) IsTrusted() bool {
+	return true
+}
+
+// GetFrontAppInfo returns the focused window's app and title.
+func (h *simHopper) GetFrontAppInfo() (string, string, string) {
+	if h.load() != 0 {
+		return "", "", "sim"
+	}
+	if w := h.focused(); w != nil {
+		return w.App, w.Title, "sim"
+	}
+	return "", "", "sim"
+}
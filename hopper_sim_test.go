@@ -0,0 +1,139 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// runSim runs ttyhop with the sim backend on testdata/sim_layout.json,
+// after edit rewrites it, and returns the exit code and report. In the
+// layout, window 1 (left half of the built-in display, focused) shows
+// panes %1 | %2 and window 2 (top right) shows %3 over %4, beside %5.
+// Firefox sits below window 2 and window 4 fills the external display.
+func runSim(t *testing.T, edit func(string) string, args ...string) (int, string) {
+	t.Helper()
+	b, err := os.ReadFile("testdata/sim_layout.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	layout := string(b)
+	if edit != nil {
+		layout = edit(layout)
+	}
+	path := filepath.Join(t.TempDir(), "layout.json")
+	if err := os.WriteFile(path, []byte(layout), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("TTYHOP_CONFIG", "/nonexistent")
+	t.Setenv("TMUX_TMPDIR", t.TempDir())
+	t.Setenv("TMUX", "")
+	t.Setenv("TMUX_PANE", "")
+	originalOut := simOut
+	defer func() { simOut = originalOut }()
+	var out strings.Builder
+	simOut = &out
+
+	rc := run(nil, append([]string{"--backend", "sim", "--layout", path, "--wait-ms", "50"}, args...))
+	return rc, strings.TrimSpace(out.String())
+}
+
+// activate moves the active pane of the layout's first window to pane.
+func activate(pane string) func(string) string {
+	return func(layout string) string {
+		layout = strings.Replace(layout, `"active": true`, `"active": false`, 1)
+		return strings.Replace(layout, `{"id": "`+pane+`",`, `{"id": "`+pane+`", "active": true,`, 1)
+	}
+}
+
+func TestSimHopper(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(string) string
+		args []string
+		rc   int
+		want string
+	}{
+		{"PaneMoveFirst", nil, []string{"r"}, 0, `window=1 title="zsh" pane=%2`},
+		{"HopAndLandOnEdgePane", activate("%2"), []string{"r"}, 0, `window=2 title="nvim" pane=%3`},
+		{"HopBackToRightEdge", func(l string) string {
+			l = strings.Replace(l, `"focused": true`, `"focused": false`, 1)
+			l = strings.Replace(l, `"title": "nvim",`, `"title": "nvim", "focused": true,`, 1)
+			l = strings.Replace(l, `"height": 31, "active": true`, `"height": 31`, 1)
+			return strings.Replace(l, `{"id": "%4",`, `{"id": "%4", "active": true,`, 1)
+		}, []string{"l"}, 0, `window=1 title="zsh" pane=%2`},
+		{"PaneMoveInOtherWindow", func(l string) string {
+			l = strings.Replace(l, `"focused": true`, `"focused": false`, 1)
+			return strings.Replace(l, `"title": "nvim",`, `"title": "nvim", "focused": true,`, 1)
+		}, []string{"l"}, 0, `window=2 title="nvim" pane=%3`},
+		{"ZoomedOriginHopsWindows", func(l string) string {
+			return strings.Replace(activate("%2")(l), `"height": 60, "panes"`, `"height": 60, "zoomed": true, "panes"`, 1)
+		}, []string{"--zoom", "edge", "r"}, 0, `window=2 title="nvim" pane=%3`},
+		{"NoNeighbor", nil, []string{"l"}, 5, "no move (exit code 5)"},
+		{"NotTerminal", func(l string) string {
+			l = strings.Replace(l, `"focused": true`, `"focused": false`, 1)
+			return strings.Replace(l, `"title": "Mozilla Firefox",`, `"title": "Mozilla Firefox", "focused": true,`, 1)
+		}, []string{"r"}, 1, "no move (exit code 1)"},
+		{"Display", nil, []string{"display", "next"}, 0, `window=4 title="ssh"`},
+		{"HopToOtherAllowedApp", func(l string) string {
+			return strings.Replace(activate("%2")(l), `"app": "Alacritty", "title": "nvim"`, `"app": "kitty", "title": "nvim"`, 1)
+		}, []string{"--terminals", "Alacritty,kitty", "r"}, 0, `window=2 title="nvim" pane=%3`},
+		{"TitleAllowsWindow", func(l string) string {
+			l = strings.Replace(l, `"focused": true`, `"focused": false`, 1)
+			return strings.Replace(l, `"title": "Mozilla Firefox",`, `"title": "Mozilla Firefox", "focused": true,`, 1)
+		}, []string{"--terminals", "Alacritty,title:^Mozilla", "k"}, 0, `window=2 title="nvim" pane=%4`},
+		{"ChainWindowsFirst", nil, []string{"--chain", "windows,tmux", "r"}, 0, `window=2 title="nvim" pane=%3`},
+		{"ChainWithoutWindows", activate("%2"), []string{"--chain", "nvim,tmux", "r"}, 5, "no move (exit code 5)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rc, out := runSim(t, tt.edit, tt.args...)
+			if rc != tt.rc || out != tt.want {
+				t.Errorf("got rc %d %q, want rc %d %q", rc, out, tt.rc, tt.want)
+			}
+		})
+	}
+
+	t.Run("BadTerminals", func(t *testing.T) {
+		if rc, _ := runSim(t, nil, "--terminals", "title:(", "r"); rc != 64 {
+			t.Errorf("expected rc 64 for a bad --terminals, got %d", rc)
+		}
+	})
+
+	t.Run("BadChain", func(t *testing.T) {
+		if rc, _ := runSim(t, nil, "--chain", "tmux,vscode", "r"); rc != 64 {
+			t.Errorf("expected rc 64 for a bad --chain, got %d", rc)
+		}
+	})
+
+	t.Run("BadLayout", func(t *testing.T) {
+		if rc, _ := runSim(t, func(string) string { return "{" }, "r"); rc != 64 {
+			t.Errorf("expected rc 64 for a malformed layout, got %d", rc)
+		}
+	})
+
+	t.Run("RestoresState", func(t *testing.T) {
+		t.Setenv("NVIM", "/tmp/nvim.sock")
+		t.Setenv("EMACS_SOCKET_NAME", "")
+		_ = os.Unsetenv("EMACS_SOCKET_NAME")
+		runTmux := runTmuxCmd
+		if rc, _ := runSim(t, activate("%2"), "r"); rc != 0 {
+			t.Fatalf("expected a hop, got rc %d", rc)
+		}
+		if os.Getenv("NVIM") != "/tmp/nvim.sock" || os.Getenv("TMUX") != "" || os.Getenv("TMUX_PANE") != "" {
+			t.Errorf("expected the environment back, got NVIM=%q TMUX=%q TMUX_PANE=%q", os.Getenv("NVIM"), os.Getenv("TMUX"), os.Getenv("TMUX_PANE"))
+		}
+		if _, ok := os.LookupEnv("EMACS_SOCKET_NAME"); ok {
+			t.Error("expected EMACS_SOCKET_NAME unset again")
+		}
+		if fmt.Sprint(runTmuxCmd) != fmt.Sprint(runTmux) {
+			t.Error("expected the tmux runner back")
+		}
+	})
+}
@@ -0,0 +1,193 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package main
+
+import (
+	"strconv"
+
+	"github.com/leejonesio/ttyhop/internal/i3ipc"
+	"github.com/leejonesio/ttyhop/internal/neighbor"
+)
+
+// swayHopper hops between terminal windows on sway or i3, reading the
+// layout tree over the IPC socket in $SWAYSOCK or $I3SOCK and focusing by
+// con_id.
+type swayHopper struct {
+	hopOptions
+	conn *i3ipc.Conn
+}
+
+func newSwayHopper() *swayHopper {
+	return &swayHopper{}
+}
+
+func (h *swayHopper) dial() error {
+	if h.conn != nil {
+		return nil
+	}
+	c, err := i3ipc.Dial("")
+	if err != nil {
+		dbg("%v", err)
+		return err
+	}
+	h.conn = c
+	return nil
+}
+
+func (h *swayHopper) release() {
+	if h.conn != nil {
+		_ = h.conn.Close()
+		h.conn = nil
+	}
+}
+
+func (h *swayHopper) FocusNeighbor(dir Direction, debug bool, doEdge bool) int {
+	h.SetDebug(debug)
+	defer h.release()
+	return hop(h, dir, doEdge, h.hopOptions)
+}
+
+func (h *swayHopper) FocusDisplay(target string, debug bool) int {
+	h.SetDebug(debug)
+	defer h.release()
+	return hopDisplay(h, target)
+}
+
+func (h *swayHopper) Inspect(dir Direction) (int, []neighbor.Scored, int) {
+	defer h.release()
+	_, best, scored, _, rc := inspect(h, dir, h.hopOptions)
+	return best, scored, rc
+}
+
+// swayAppOf names a window's application: its Wayland app_id, or its X11
+// class under i3 and Xwayland.
+func swayAppOf(n *i3ipc.Node) string {
+	if n.AppID != nil && *n.AppID != "" {
+		return *n.AppID
+	}
+	if n.WindowProperties != nil {
+		return n.WindowProperties.Class
+	}
+	return ""
+}
+
+// swayInfo identifies a window for the terminal allowlist.
+func swayInfo(n *i3ipc.Node) appInfo {
+	a := appInfo{title: n.Name}
+	if n.AppID != nil {
+		a.appID = *n.AppID
+	}
+	if p := n.WindowProperties; p != nil {
+		a.class, a.instance = p.Class, p.Instance
+	}
+	return a
+}
+
+// windows lists the other allowed terminal windows on visible workspaces.
+// Exit codes follow the macOS backend: 10 when the IPC socket can't be
+// reached, 4 when the tree can't be read, 2 without a focused window and 1
+// when it isn't a terminal.
+func (h *swayHopper) windows() (neighbor.Rect, []neighbor.Candidate, int) {
+	if err := h.dial(); err != nil {
+		return neighbor.Rect{}, nil, 10
+	}
+	tree, err := h.conn.Tree()
+	if err != nil {
+		dbg("denied: cannot read the layout tree: %v", err)
+		return neighbor.Rect{}, nil, 4
+	}
+	visible := map[string]bool{}
+	if wss, err := h.conn.Workspaces(); err == nil {
+		for _, ws := range wss {
+			visible[ws.Name] = ws.Visible
+		}
+	}
+
+	var focused *i3ipc.Node
+	var wins []*i3ipc.Node
+	i3ipc.Walk(tree, func(n *i3ipc.Node, ws string) {
+		if n.Focused {
+			focused = n
+		}
+		if n.IsWindow() && visible[ws] {
+			wins = append(wins, n)
+		}
+	})
+	if focused == nil || !focused.IsWindow() {
+		dbg("denied: no focused window")
+		return neighbor.Rect{}, nil, 2
+	}
+	if !h.isTerminal(swayInfo(focused)) {
+		dbg("denied: front window is %q, not a terminal", swayAppOf(focused))
+		return neighbor.Rect{}, nil, 1
+	}
+
+	var cands []neighbor.Candidate
+	for _, n := range wins {
+		if n == focused || !h.isTerminal(swayInfo(n)) {
+			continue
+		}
+		cands = append(cands, neighbor.Candidate{ID: strconv.FormatInt(n.ID, 10), Rect: swayRect(n.Rect), Title: n.Name})
+	}
+	return swayRect(focused.Rect), cands, 0
+}
+
+func swayRect(r i3ipc.Rect) neighbor.Rect {
+	return neighbor.Rect{X: float64(r.X), Y: float64(r.Y), W: float64(r.Width), H: float64(r.Height)}
+}
+
+func (h *swayHopper) displays() []neighbor.Display {
+	if h.dial() != nil {
+		return nil
+	}
+	outs, err := h.conn.Outputs()
+	if err != nil {
+		return nil
+	}
+	var displays []neighbor.Display
+	for _, o := range outs {
+		if o.Active {
+			displays = append(displays, neighbor.Display{ID: o.Name, Rect: swayRect(o.Rect)})
+		}
+	}
+	return displays
+}
+
+func (h *swayHopper) focus(id string) {
+	if _, err := strconv.ParseInt(id, 10, 64); err != nil || h.dial() != nil {
+		return
+	}
+	if err := h.conn.Command("[con_id=" + id + "] focus"); err != nil {
+		dbg("sway: %v", err)
+	}
+}
+
+func (h *swayHopper) SetDebug(debug bool) {
+	debugLog = debug
+}
+
+// IsTrusted reports whether the IPC socket accepts our connection.
+func (h *swayHopper) IsTrusted() bool {
+	defer h.release()
+	return h.dial() == nil
+}
+
+// GetFrontAppInfo returns the focused window's app_id (or class) and title.
+func (h *swayHopper) GetFrontAppInfo() (string, string, string) {
+	defer h.release()
+	if h.dial() != nil {
+		return "", "", "sway"
+	}
+	tree, err := h.conn.Tree()
+	if err != nil {
+		return "", "", "sway"
+	}
+	var app, title string
+	i3ipc.Walk(tree, func(n *i3ipc.Node, _ string) {
+		if n.Focused && n.IsWindow() {
+			app, title = swayAppOf(n), n.Name
+		}
+	})
+	return app, title, "sway"
+}
@@ -0,0 +1,160 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/leejonesio/ttyhop/internal/i3ipc"
+	"github.com/leejonesio/ttyhop/internal/i3ipc/i3ipctest"
+)
+
+// swayServer replays the recorded layout in testdata: Alacritty windows 10
+// (focused, left half of eDP-1) and 11 (top right), Firefox 12 (bottom
+// right), a floating Alacritty 20 on HDMI-A-1 and Alacritty 30 on a hidden
+// workspace. edit, if set, rewrites the tree first.
+func swayServer(t *testing.T, edit func(tree string) string) *i3ipctest.Server {
+	t.Helper()
+	isolateChain(t)
+	read := func(name string) string {
+		b, err := os.ReadFile("testdata/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+	tree := read("sway_tree.json")
+	if edit != nil {
+		tree = edit(tree)
+	}
+	srv := i3ipctest.NewServer(t, map[uint32]string{
+		i3ipc.GetTree:       tree,
+		i3ipc.GetWorkspaces: read("sway_workspaces.json"),
+		i3ipc.GetOutputs:    read("sway_outputs.json"),
+	})
+	t.Setenv("SWAYSOCK", srv.Path)
+	t.Setenv("I3SOCK", "")
+	return srv
+}
+
+func TestSwayHopper(t *testing.T) {
+	t.Run("Neighbors", func(t *testing.T) {
+		srv := swayServer(t, nil)
+		h := newSwayHopper()
+		if rc := h.FocusNeighbor(DirRight, false, false); rc != 0 {
+			t.Fatalf("expected rc 0, got %d", rc)
+		}
+		if rc := h.FocusNeighbor(DirLeft, false, false); rc != 5 {
+			t.Errorf("expected rc 5 at the left edge, got %d", rc)
+		}
+		if cmds := srv.Commands(); strings.Join(cmds, "; ") != "[con_id=11] focus" {
+			t.Errorf("expected con 11 to be focused, got %q", cmds)
+		}
+	})
+
+	t.Run("I3Socket", func(t *testing.T) {
+		srv := swayServer(t, nil)
+		t.Setenv("I3SOCK", srv.Path)
+		t.Setenv("SWAYSOCK", "")
+		if rc := newSwayHopper().FocusNeighbor(DirRight, false, false); rc != 0 {
+			t.Errorf("expected rc 0 over $I3SOCK, got %d", rc)
+		}
+	})
+
+	t.Run("Inspect", func(t *testing.T) {
+		swayServer(t, nil)
+		_, scored, rc := newSwayHopper().Inspect(DirRight)
+		var ids []string
+		for _, s := range scored {
+			ids = append(ids, s.ID)
+		}
+		// Firefox and the hidden workspace's terminal are left out.
+		if rc != 0 || strings.Join(ids, ",") != "11,20" {
+			t.Errorf("expected candidates 11,20, got %q (rc %d)", ids, rc)
+		}
+	})
+
+	t.Run("Displays", func(t *testing.T) {
+		srv := swayServer(t, nil)
+		if rc := newSwayHopper().FocusDisplay("2", false); rc != 0 {
+			t.Fatalf("expected rc 0, got %d", rc)
+		}
+		if cmds := srv.Commands(); strings.Join(cmds, "; ") != "[con_id=20] focus" {
+			t.Errorf("expected con 20 on HDMI-A-1 to be focused, got %q", cmds)
+		}
+	})
+
+	t.Run("LandsOnEdgePane", func(t *testing.T) {
+		swayServer(t, nil)
+		t.Setenv("TMUX_TMPDIR", t.TempDir())
+		originalRunTmux := runTmuxCmd
+		defer func() { runTmuxCmd = originalRunTmux }()
+		var selected string
+		runTmuxCmd = func(args ...string) (string, error) {
+			cmd := strings.Join(args, " ")
+			switch {
+			case strings.HasPrefix(cmd, "list-clients"):
+				return "/dev/pts/3 0 1627840934", nil
+			case strings.HasPrefix(cmd, "display -p -t /dev/pts/3"):
+				return "@1 0", nil
+			case strings.HasPrefix(cmd, "list-panes -t @1"):
+				return "%1 1 0\n%2 0 1", nil
+			case strings.HasPrefix(cmd, "select-pane -t"):
+				selected = args[2]
+			}
+			return "", nil
+		}
+		h := newSwayHopper()
+		h.SetWaitMs(50)
+		if rc := h.FocusNeighbor(DirRight, false, true); rc != 0 {
+			t.Fatalf("expected rc 0, got %d", rc)
+		}
+		if selected != "%1" {
+			t.Errorf("expected the leftmost tmux pane after the hop, got %q", selected)
+		}
+	})
+
+	t.Run("ExitCodes", func(t *testing.T) {
+		unfocus := func(tree string) string {
+			return strings.Replace(tree, `"focused": true`, `"focused": false`, 1)
+		}
+		tests := []struct {
+			name string
+			edit func(string) string
+			env  string
+			rc   int
+		}{
+			{"NotTerminal", func(tree string) string {
+				const firefox = `"app_id": "firefox", "rect": {"x": 960, "y": 540, "width": 960, "height": 540}, `
+				return strings.Replace(unfocus(tree), firefox+`"focused": false`, firefox+`"focused": true`, 1)
+			}, "", 1},
+			{"NoFocusedWindow", unfocus, "", 2},
+			{"BadTree", func(string) string { return "{" }, "", 4},
+			{"NoSocket", nil, "/nonexistent/sway.sock", 10},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				srv := swayServer(t, tt.edit)
+				if tt.env != "" {
+					t.Setenv("SWAYSOCK", tt.env)
+				}
+				if rc := newSwayHopper().FocusNeighbor(DirRight, false, false); rc != tt.rc {
+					t.Errorf("expected rc %d, got %d", tt.rc, rc)
+				}
+				if cmds := srv.Commands(); len(cmds) != 0 {
+					t.Errorf("expected no focus command, got %q", cmds)
+				}
+			})
+		}
+	})
+
+	t.Run("FrontAppInfo", func(t *testing.T) {
+		swayServer(t, nil)
+		if app, title, src := newSwayHopper().GetFrontAppInfo(); app != "Alacritty" || title != "zsh" || src != "sway" {
+			t.Errorf("unexpected front app %q %q %q", app, title, src)
+		}
+	})
+}
@@ -0,0 +1,51 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package main
+
+import "github.com/leejonesio/ttyhop/internal/neighbor"
+
+// tmuxHopper is used where no window backend is available, or when asked
+// for with --backend tmux. It still moves between tmux panes, but never
+// hops between OS windows.
+type tmuxHopper struct {
+	hopOptions
+}
+
+func (h *tmuxHopper) FocusNeighbor(dir Direction, debug bool, doEdge bool) int {
+	h.SetDebug(debug)
+	chain := openChain(h.hopOptions, nil)
+	if tryChain(chain, dir) {
+		return 0
+	}
+	dbg("tmux-only backend; not hopping windows %s", dir)
+	if h.wrapPanes && wrapChain(chain, dir) {
+		return 0
+	}
+	return 5
+}
+
+func (h *tmuxHopper) SetDebug(debug bool) {
+	debugLog = debug
+}
+
+// FocusDisplay has no displays to hop between without a window backend.
+func (h *tmuxHopper) FocusDisplay(target string, debug bool) int {
+	h.SetDebug(debug)
+	dbg("tmux-only backend; not hopping displays")
+	return 5
+}
+
+// Inspect has no windows to score without a window backend.
+func (h *tmuxHopper) Inspect(dir Direction) (int, []neighbor.Scored, int) {
+	return -1, nil, 0
+}
+
+// IsTrusted always succeeds: there is no permission gate without a window backend.
+func (h *tmuxHopper) IsTrusted() bool {
+	return true
+}
+
+func (h *tmuxHopper) GetFrontAppInfo() (string, string, string) {
+	return "", "", "none"
+}
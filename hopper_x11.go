@@ -0,0 +1,189 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/leejonesio/ttyhop/internal/neighbor"
+	"github.com/leejonesio/ttyhop/internal/x11"
+)
+
+// x11Hopper hops between terminal windows on an X11 desktop through the
+// EWMH hints any modern window manager keeps on the root window:
+// _NET_CLIENT_LIST to list windows and _NET_ACTIVE_WINDOW to focus them.
+type x11Hopper struct {
+	hopOptions
+	conn *x11.Conn
+}
+
+func newX11Hopper() *x11Hopper {
+	return &x11Hopper{}
+}
+
+// dial opens the connection for one operation; release closes it.
+func (h *x11Hopper) dial() error {
+	if h.conn != nil {
+		return nil
+	}
+	c, err := x11.Dial("")
+	if err != nil {
+		dbg("%v", err)
+		return err
+	}
+	h.conn = c
+	return nil
+}
+
+func (h *x11Hopper) release() {
+	if h.conn != nil {
+		_ = h.conn.Close()
+		h.conn = nil
+	}
+}
+
+func (h *x11Hopper) FocusNeighbor(dir Direction, debug bool, doEdge bool) int {
+	h.SetDebug(debug)
+	defer h.release()
+	return hop(h, dir, doEdge, h.hopOptions)
+}
+
+func (h *x11Hopper) FocusDisplay(target string, debug bool) int {
+	h.SetDebug(debug)
+	defer h.release()
+	return hopDisplay(h, target)
+}
+
+func (h *x11Hopper) Inspect(dir Direction) (int, []neighbor.Scored, int) {
+	defer h.release()
+	_, best, scored, _, rc := inspect(h, dir, h.hopOptions)
+	return best, scored, rc
+}
+
+// info identifies window w for the terminal allowlist. The title is read
+// too, since landing uses it to tell which session a window shows.
+func (h *x11Hopper) info(w uint32) appInfo {
+	instance, class, _ := h.conn.Class(w)
+	a := appInfo{class: class, instance: instance}
+	a.title, _ = h.conn.Title(w)
+	return a
+}
+
+// windows lists the other allowed terminal windows on the current desktop.
+// Exit codes follow the macOS backend: 10 when the X server can't be
+// reached, 1 when the active window isn't a terminal, 2 without an active
+// window, 3 when its geometry can't be read and 4 when there is no client
+// list.
+func (h *x11Hopper) windows() (neighbor.Rect, []neighbor.Candidate, int) {
+	if err := h.dial(); err != nil {
+		return neighbor.Rect{}, nil, 10
+	}
+	c := h.conn
+	active, err := c.ActiveWindow()
+	if err != nil {
+		dbg("denied: cannot read _NET_ACTIVE_WINDOW: %v", err)
+		return neighbor.Rect{}, nil, 10
+	}
+	if active == 0 {
+		dbg("denied: no focused window")
+		return neighbor.Rect{}, nil, 2
+	}
+	if a := h.info(active); !h.isTerminal(a) {
+		dbg("denied: front window is %q, not a terminal", a.class)
+		return neighbor.Rect{}, nil, 1
+	}
+	cur, err := h.rect(active)
+	if err != nil {
+		dbg("denied: cannot read current window rect: %v", err)
+		return neighbor.Rect{}, nil, 3
+	}
+	wins, err := c.ClientList()
+	if err != nil {
+		dbg("denied: cannot list windows: %v", err)
+		return neighbor.Rect{}, nil, 4
+	}
+
+	desktop := c.CurrentDesktop()
+	var cands []neighbor.Candidate
+	for _, w := range wins {
+		if w == active {
+			continue
+		}
+		a := h.info(w)
+		if !h.isTerminal(a) {
+			continue
+		}
+		if d := c.Desktop(w); d != desktop && d != x11.AllDesktops && desktop != x11.AllDesktops {
+			continue
+		}
+		if c.Hidden(w) {
+			continue
+		}
+		r, err := h.rect(w)
+		if err != nil {
+			continue
+		}
+		cands = append(cands, neighbor.Candidate{ID: fmt.Sprintf("%#x", w), Rect: r, Title: a.title})
+	}
+	return cur, cands, 0
+}
+
+func (h *x11Hopper) rect(w uint32) (neighbor.Rect, error) {
+	x, y, width, height, err := h.conn.Geometry(w)
+	return neighbor.Rect{X: float64(x), Y: float64(y), W: float64(width), H: float64(height)}, err
+}
+
+func (h *x11Hopper) displays() []neighbor.Display {
+	if h.dial() != nil {
+		return nil
+	}
+	mons, err := h.conn.Monitors()
+	if err != nil {
+		return nil
+	}
+	displays := make([]neighbor.Display, len(mons))
+	for i, m := range mons {
+		displays[i] = neighbor.Display{
+			ID:   strconv.Itoa(i),
+			Rect: neighbor.Rect{X: float64(m[0]), Y: float64(m[1]), W: float64(m[2]), H: float64(m[3])},
+		}
+	}
+	return displays
+}
+
+func (h *x11Hopper) focus(id string) {
+	w, err := strconv.ParseUint(id, 0, 32)
+	if err != nil || h.dial() != nil {
+		return
+	}
+	if err := h.conn.Activate(uint32(w)); err != nil {
+		dbg("x11: cannot activate %s: %v", id, err)
+	}
+}
+
+func (h *x11Hopper) SetDebug(debug bool) {
+	debugLog = debug
+}
+
+// IsTrusted reports whether the X server accepts our connection; X11 has
+// no separate permission to grant.
+func (h *x11Hopper) IsTrusted() bool {
+	defer h.release()
+	return h.dial() == nil
+}
+
+// GetFrontAppInfo returns the active window's WM_CLASS instance and class.
+func (h *x11Hopper) GetFrontAppInfo() (string, string, string) {
+	defer h.release()
+	if h.dial() != nil {
+		return "", "", "x11"
+	}
+	active, err := h.conn.ActiveWindow()
+	if err != nil || active == 0 {
+		return "", "", "x11"
+	}
+	instance, class, _ := h.conn.Class(active)
+	return instance, class, "x11"
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/leejonesio/ttyhop/internal/x11/x11test"
+)
+
+// x11Layout serves a 2x2 grid of Alacritty windows on one monitor, a
+// browser, a minimized terminal and one on another desktop, with the
+// top-left terminal active.
+func x11Layout(t *testing.T) *x11test.Server {
+	t.Helper()
+	isolateChain(t)
+	srv := x11test.NewServer(t)
+	t.Setenv("DISPLAY", srv.Display())
+
+	term := func(id uint32, x, y int) {
+		srv.AddWindow(id, x, y, 900, 500)
+		srv.SetClass(id, "Alacritty", "Alacritty")
+		srv.SetCardinals(id, "_NET_WM_DESKTOP", "CARDINAL", 0)
+	}
+	term(0x11, 0, 0)
+	term(0x12, 1000, 0)
+	term(0x21, 0, 600)
+	term(0x22, 1000, 600)
+	term(0x30, 500, 0) // minimized, between the top two
+	srv.SetAtoms(0x30, "_NET_WM_STATE", "_NET_WM_STATE_HIDDEN")
+	term(0x40, 500, 600) // on desktop 1
+	srv.SetCardinals(0x40, "_NET_WM_DESKTOP", "CARDINAL", 1)
+	srv.AddWindow(0x50, 500, 0, 400, 400)
+	srv.SetClass(0x50, "Navigator", "firefox")
+
+	srv.SetCardinals(x11test.Root, "_NET_CURRENT_DESKTOP", "CARDINAL", 0)
+	srv.SetCardinals(x11test.Root, "_NET_CLIENT_LIST_STACKING", "WINDOW", 0x40, 0x30, 0x22, 0x21, 0x12, 0x50, 0x11)
+	srv.SetCardinals(x11test.Root, "_NET_ACTIVE_WINDOW", "WINDOW", 0x11)
+	return srv
+}
+
+func TestX11Hopper(t *testing.T) {
+	t.Run("Neighbors", func(t *testing.T) {
+		srv := x11Layout(t)
+		h := newX11Hopper()
+		for _, tt := range []struct {
+			dir  Direction
+			want uint32
+		}{
+			{DirRight, 0x12},
+			{DirDown, 0x21},
+		} {
+			if rc := h.FocusNeighbor(tt.dir, false, false); rc != 0 {
+				t.Fatalf("%s: expected rc 0, got %d", tt.dir, rc)
+			}
+			sent := srv.Sent()
+			last := sent[len(sent)-1]
+			if last.Type != "_NET_ACTIVE_WINDOW" || last.Window != tt.want || last.Data[2] != 0x11 {
+				t.Errorf("%s: expected activation of %#x, got %+v", tt.dir, tt.want, last)
+			}
+		}
+		if rc := h.FocusNeighbor(DirLeft, false, false); rc != 5 {
+			t.Errorf("expected rc 5 with nothing to the left, got %d", rc)
+		}
+	})
+
+	t.Run("Inspect", func(t *testing.T) {
+		x11Layout(t)
+		best, scored, rc := newX11Hopper().Inspect(DirRight)
+		if rc != 0 || best < 0 || scored[best].ID != "0x12" {
+			t.Fatalf("expected 0x12 to be picked, got best=%d rc=%d %+v", best, rc, scored)
+		}
+		// Only the three visible terminals on this desktop are candidates.
+		if len(scored) != 3 {
+			t.Errorf("expected 3 candidates, got %+v", scored)
+		}
+	})
+
+	t.Run("Displays", func(t *testing.T) {
+		srv := x11Layout(t)
+		srv.Monitors = [][4]int{{0, 0, 950, 1200}, {950, 0, 1000, 1200}}
+		if rc := newX11Hopper().FocusDisplay("next", false); rc != 0 {
+			t.Fatalf("expected rc 0, got %d", rc)
+		}
+		// 0x12 is stacked above 0x22 on the second monitor.
+		if sent := srv.Sent(); len(sent) != 1 || sent[0].Window != 0x12 {
+			t.Errorf("expected activation of 0x12, got %+v", sent)
+		}
+	})
+
+	t.Run("FrontAppInfo", func(t *testing.T) {
+		x11Layout(t)
+		h := newX11Hopper()
+		if !h.IsTrusted() {
+			t.Error("expected the fake display to accept connections")
+		}
+		if inst, class, src := h.GetFrontAppInfo(); inst != "Alacritty" || class != "Alacritty" || src != "x11" {
+			t.Errorf("unexpected front app %q %q %q", inst, class, src)
+		}
+	})
+
+	t.Run("ExitCodes", func(t *testing.T) {
+		tests := []struct {
+			name  string
+			setup func(t *testing.T, srv *x11test.Server)
+			rc    int
+		}{
+			{"NotTerminal", func(t *testing.T, srv *x11test.Server) {
+				srv.SetCardinals(x11test.Root, "_NET_ACTIVE_WINDOW", "WINDOW", 0x50)
+			}, 1},
+			{"NoActiveWindow", func(t *testing.T, srv *x11test.Server) {
+				srv.SetCardinals(x11test.Root, "_NET_ACTIVE_WINDOW", "WINDOW", 0)
+			}, 2},
+			{"NoClientList", func(t *testing.T, srv *x11test.Server) {
+				srv.SetCardinals(x11test.Root, "_NET_CLIENT_LIST_STACKING", "WINDOW")
+			}, 4},
+			{"NoDisplay", func(t *testing.T, srv *x11test.Server) {
+				t.Setenv("DISPLAY", "/nonexistent/X0:0")
+			}, 10},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				srv := x11Layout(t)
+				tt.setup(t, srv)
+				if rc := newX11Hopper().FocusNeighbor(DirRight, false, false); rc != tt.rc {
+					t.Errorf("expected rc %d, got %d", tt.rc, rc)
+				}
+				if sent := srv.Sent(); len(sent) != 0 {
+					t.Errorf("expected no activation, got %+v", sent)
+				}
+			})
+		}
+	})
+}
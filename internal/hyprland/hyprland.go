@@ -0,0 +1,161 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+// Package hyprland is a minimal client for Hyprland's request socket
+// (.socket.sock). Each request is a single write on a fresh connection; the
+// compositor replies and closes it. A "j/" prefix asks for JSON.
+package hyprland
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// timeout bounds one request, so a wedged compositor can't hang a hop.
+const timeout = 2 * time.Second
+
+// Workspace identifies a workspace.
+type Workspace struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// Client is one entry of j/clients (and the reply to j/activewindow).
+type Client struct {
+	Address        string    `json:"address"`
+	Mapped         bool      `json:"mapped"`
+	Hidden         bool      `json:"hidden"`
+	At             [2]int    `json:"at"`
+	Size           [2]int    `json:"size"`
+	Workspace      Workspace `json:"workspace"`
+	Floating       bool      `json:"floating"`
+	Monitor        int       `json:"monitor"`
+	Class          string    `json:"class"`
+	Title          string    `json:"title"`
+	FocusHistoryID int       `json:"focusHistoryID"`
+}
+
+// Monitor is one entry of j/monitors. Width and Height are in pixels; X and
+// Y are in the layout coordinates clients use.
+type Monitor struct {
+	ID               int       `json:"id"`
+	Name             string    `json:"name"`
+	Width            int       `json:"width"`
+	Height           int       `json:"height"`
+	X                int       `json:"x"`
+	Y                int       `json:"y"`
+	Scale            float64   `json:"scale"`
+	Transform        int       `json:"transform"`
+	ActiveWorkspace  Workspace `json:"activeWorkspace"`
+	SpecialWorkspace Workspace `json:"specialWorkspace"`
+	Disabled         bool      `json:"disabled"`
+}
+
+// LogicalSize is the monitor's size in layout coordinates: its pixel size
+// divided by its scale, turned sideways by odd transforms.
+func (m Monitor) LogicalSize() (w, h float64) {
+	w, h = float64(m.Width), float64(m.Height)
+	if m.Scale > 0 {
+		w, h = w/m.Scale, h/m.Scale
+	}
+	if m.Transform%2 == 1 {
+		w, h = h, w
+	}
+	return w, h
+}
+
+// Shows reports whether a workspace is on screen on this monitor.
+func (m Monitor) Shows(ws int) bool {
+	return ws == m.ActiveWorkspace.ID || (m.SpecialWorkspace.ID != 0 && ws == m.SpecialWorkspace.ID)
+}
+
+// SocketPath returns the request socket of the instance named by
+// $HYPRLAND_INSTANCE_SIGNATURE, under $XDG_RUNTIME_DIR/hypr (or /tmp/hypr,
+// where Hyprland before 0.40 put it). It is empty outside Hyprland.
+func SocketPath() string {
+	sig := os.Getenv("HYPRLAND_INSTANCE_SIGNATURE")
+	if sig == "" {
+		return ""
+	}
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		p := filepath.Join(dir, "hypr", sig, ".socket.sock")
+		if _, err := os.Stat(p); err == nil {
+			return p
+		}
+	}
+	return filepath.Join("/tmp/hypr", sig, ".socket.sock")
+}
+
+// Request sends one request, such as "j/clients", and returns the reply.
+func Request(req string) ([]byte, error) {
+	path := SocketPath()
+	if path == "" {
+		return nil, errors.New("hyprland: HYPRLAND_INSTANCE_SIGNATURE is not set")
+	}
+	c, err := net.DialTimeout("unix", path, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("hyprland: %w", err)
+	}
+	defer func() { _ = c.Close() }()
+	_ = c.SetDeadline(time.Now().Add(timeout))
+	if _, err := io.WriteString(c, req); err != nil {
+		return nil, fmt.Errorf("hyprland: %w", err)
+	}
+	out, err := io.ReadAll(c)
+	if err != nil {
+		return nil, fmt.Errorf("hyprland: %w", err)
+	}
+	return out, nil
+}
+
+func requestJSON(req string, v any) error {
+	out, err := Request("j/" + req)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(out, v); err != nil {
+		return fmt.Errorf("hyprland: %s: %w", req, err)
+	}
+	return nil
+}
+
+// Clients lists every client window.
+func Clients() ([]Client, error) {
+	var cs []Client
+	err := requestJSON("clients", &cs)
+	return cs, err
+}
+
+// ActiveWindow returns the focused client; its Address is empty when
+// nothing has focus.
+func ActiveWindow() (Client, error) {
+	var c Client
+	err := requestJSON("activewindow", &c)
+	return c, err
+}
+
+// Monitors lists the monitors.
+func Monitors() ([]Monitor, error) {
+	var ms []Monitor
+	err := requestJSON("monitors", &ms)
+	return ms, err
+}
+
+// Dispatch runs a dispatcher such as "focuswindow address:0x55d1c0a3e2f0".
+func Dispatch(args string) error {
+	out, err := Request("dispatch " + args)
+	if err != nil {
+		return err
+	}
+	if reply := strings.TrimSpace(string(out)); reply != "ok" {
+		return fmt.Errorf("hyprland: dispatch %s: %s", args, reply)
+	}
+	return nil
+}
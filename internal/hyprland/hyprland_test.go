@@ -0,0 +1,64 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package hyprland_test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/leejonesio/ttyhop/internal/hyprland"
+	"github.com/leejonesio/ttyhop/internal/hyprland/hyprlandtest"
+)
+
+func TestSocketPath(t *testing.T) {
+	t.Setenv("HYPRLAND_INSTANCE_SIGNATURE", "")
+	if p := hyprland.SocketPath(); p != "" {
+		t.Errorf("expected no socket outside Hyprland, got %q", p)
+	}
+
+	srv := hyprlandtest.NewServer(t, nil)
+	want := filepath.Join(srv.RuntimeDir, "hypr", hyprlandtest.Signature, ".socket.sock")
+	if p := hyprland.SocketPath(); p != want {
+		t.Errorf("expected %q, got %q", want, p)
+	}
+
+	// Older releases kept the socket under /tmp/hypr.
+	t.Setenv("HYPRLAND_INSTANCE_SIGNATURE", "old")
+	if p := hyprland.SocketPath(); p != "/tmp/hypr/old/.socket.sock" {
+		t.Errorf("expected the /tmp/hypr fallback, got %q", p)
+	}
+}
+
+func TestRequests(t *testing.T) {
+	srv := hyprlandtest.NewServer(t, map[string]string{
+		"j/activewindow":                   `{"address": "0x1", "class": "foot", "at": [5, 6], "size": [7, 8]}`,
+		"j/monitors":                       `[{"name": "eDP-1", "width": 2880, "height": 1800, "scale": 2, "transform": 1}]`,
+		"dispatch focuswindow address:0x2": "No such window found",
+	})
+	c, err := hyprland.ActiveWindow()
+	if err != nil || c.Address != "0x1" || c.Class != "foot" || c.At != [2]int{5, 6} || c.Size != [2]int{7, 8} {
+		t.Errorf("unexpected active window %+v (%v)", c, err)
+	}
+	ms, err := hyprland.Monitors()
+	if err != nil || len(ms) != 1 {
+		t.Fatalf("unexpected monitors %+v (%v)", ms, err)
+	}
+	if w, h := ms[0].LogicalSize(); w != 900 || h != 1440 {
+		t.Errorf("expected a rotated 900x1440 monitor, got %vx%v", w, h)
+	}
+	if _, err := hyprland.Clients(); err == nil {
+		t.Error("expected a non-JSON reply to fail")
+	}
+
+	if err := hyprland.Dispatch("focuswindow address:0x1"); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	if err := hyprland.Dispatch("focuswindow address:0x2"); err == nil || !strings.Contains(err.Error(), "No such window") {
+		t.Errorf("expected the compositor's error, got %v", err)
+	}
+	if d := srv.Dispatches(); strings.Join(d, ",") != "focuswindow address:0x1,focuswindow address:0x2" {
+		t.Errorf("unexpected dispatches %q", d)
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+// Package hyprlandtest runs a fake Hyprland request socket that replays
+// captured replies, so backends can be tested without a compositor.
+package hyprlandtest
+
+import (
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Signature is the instance signature the fake runs under.
+const Signature = "ttyhoptest"
+
+// Server answers each request with a canned reply and records the
+// dispatches it is sent.
+type Server struct {
+	// RuntimeDir is the XDG_RUNTIME_DIR holding hypr/<Signature>/.socket.sock.
+	RuntimeDir string
+
+	mu         sync.Mutex
+	replies    map[string]string
+	dispatches []string
+}
+
+// NewServer starts a fake server that stops when the test ends and points
+// XDG_RUNTIME_DIR and HYPRLAND_INSTANCE_SIGNATURE at it. replies maps
+// requests (e.g. "j/clients") to their payloads; dispatches answer "ok".
+func NewServer(t testing.TB, replies map[string]string) *Server {
+	t.Helper()
+	// A short directory keeps the socket path under the sun_path limit.
+	dir, err := os.MkdirTemp("", "hypr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	s := &Server{RuntimeDir: dir, replies: replies}
+	sock := filepath.Join(dir, "hypr", Signature, ".socket.sock")
+	if err := os.MkdirAll(filepath.Dir(sock), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	ln, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Skipf("cannot listen on a unix socket: %v", err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+	t.Setenv("XDG_RUNTIME_DIR", dir)
+	t.Setenv("HYPRLAND_INSTANCE_SIGNATURE", Signature)
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(c)
+		}
+	}()
+	return s
+}
+
+// Dispatches returns the dispatcher arguments received so far.
+func (s *Server) Dispatches() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.dispatches...)
+}
+
+func (s *Server) serve(c net.Conn) {
+	defer func() { _ = c.Close() }()
+	buf := make([]byte, 8192)
+	n, err := c.Read(buf)
+	if err != nil {
+		return
+	}
+	req := string(buf[:n])
+	s.mu.Lock()
+	reply, ok := s.replies[req]
+	if args, found := strings.CutPrefix(req, "dispatch "); found {
+		s.dispatches = append(s.dispatches, args)
+		if !ok {
+			reply, ok = "ok", true
+		}
+	}
+	s.mu.Unlock()
+	if !ok {
+		reply = "unknown request"
+	}
+	_, _ = io.WriteString(c, reply)
+}
@@ -0,0 +1,227 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+// Package i3ipc is a minimal client for the i3 IPC protocol, which sway
+// speaks too: a message is "i3-ipc", a 32-bit payload length and type in
+// native byte order, then a JSON payload.
+package i3ipc
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"time"
+)
+
+// timeout bounds one request, so a wedged window manager can't hang a hop.
+const timeout = 2 * time.Second
+
+// Magic starts every message.
+const Magic = "i3-ipc"
+
+// Message types.
+const (
+	RunCommand    = 0
+	GetWorkspaces = 1
+	GetOutputs    = 3
+	GetTree       = 4
+)
+
+// Order is the byte order of message headers (native; every platform
+// ttyhop runs on is little-endian).
+var Order = binary.LittleEndian
+
+// Rect is a container or output rectangle in layout coordinates.
+type Rect struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// WindowProperties holds the X11 properties of an i3 (or Xwayland) window.
+type WindowProperties struct {
+	Class    string `json:"class"`
+	Instance string `json:"instance"`
+	Title    string `json:"title"`
+}
+
+// Node is a container in the layout tree.
+type Node struct {
+	ID               int64             `json:"id"`
+	Type             string            `json:"type"`
+	Name             string            `json:"name"`
+	Rect             Rect              `json:"rect"`
+	Focused          bool              `json:"focused"`
+	AppID            *string           `json:"app_id"`
+	Window           *int64            `json:"window"`
+	WindowProperties *WindowProperties `json:"window_properties"`
+	Nodes            []*Node           `json:"nodes"`
+	FloatingNodes    []*Node           `json:"floating_nodes"`
+}
+
+// IsWindow reports whether n holds an application window rather than a
+// split, workspace or output.
+func (n *Node) IsWindow() bool {
+	return (n.Type == "con" || n.Type == "floating_con") && (n.AppID != nil || n.Window != nil)
+}
+
+// Workspace is one entry of GET_WORKSPACES.
+type Workspace struct {
+	Name    string `json:"name"`
+	Visible bool   `json:"visible"`
+	Focused bool   `json:"focused"`
+	Output  string `json:"output"`
+}
+
+// Output is one entry of GET_OUTPUTS.
+type Output struct {
+	Name   string `json:"name"`
+	Active bool   `json:"active"`
+	Rect   Rect   `json:"rect"`
+}
+
+// CommandResult is one entry of a RUN_COMMAND reply.
+type CommandResult struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error"`
+}
+
+// SocketPath returns $SWAYSOCK, else $I3SOCK.
+func SocketPath() string {
+	if p := os.Getenv("SWAYSOCK"); p != "" {
+		return p
+	}
+	return os.Getenv("I3SOCK")
+}
+
+// Conn is a connection to the window manager.
+type Conn struct {
+	c net.Conn
+}
+
+// Dial connects to the IPC socket at path, or SocketPath() when empty.
+func Dial(path string) (*Conn, error) {
+	if path == "" {
+		path = SocketPath()
+	}
+	if path == "" {
+		return nil, errors.New("i3ipc: neither SWAYSOCK nor I3SOCK is set")
+	}
+	c, err := net.DialTimeout("unix", path, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("i3ipc: %w", err)
+	}
+	return &Conn{c: c}, nil
+}
+
+// Close closes the connection.
+func (c *Conn) Close() error {
+	return c.c.Close()
+}
+
+// WriteMessage writes one message to w.
+func WriteMessage(w io.Writer, typ uint32, payload []byte) error {
+	msg := make([]byte, len(Magic)+8, len(Magic)+8+len(payload))
+	copy(msg, Magic)
+	Order.PutUint32(msg[len(Magic):], uint32(len(payload)))
+	Order.PutUint32(msg[len(Magic)+4:], typ)
+	_, err := w.Write(append(msg, payload...))
+	return err
+}
+
+// ReadMessage reads one message from r.
+func ReadMessage(r io.Reader) (typ uint32, payload []byte, err error) {
+	head := make([]byte, len(Magic)+8)
+	if _, err := io.ReadFull(r, head); err != nil {
+		return 0, nil, err
+	}
+	if string(head[:len(Magic)]) != Magic {
+		return 0, nil, errors.New("i3ipc: bad magic")
+	}
+	payload = make([]byte, Order.Uint32(head[len(Magic):]))
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, err
+	}
+	return Order.Uint32(head[len(Magic)+4:]), payload, nil
+}
+
+// Request sends a message and decodes the reply into v. Events (which
+// have the high bit set) are skipped.
+func (c *Conn) Request(typ uint32, payload string, v any) error {
+	_ = c.c.SetDeadline(time.Now().Add(timeout))
+	if err := WriteMessage(c.c, typ, []byte(payload)); err != nil {
+		return fmt.Errorf("i3ipc: %w", err)
+	}
+	for {
+		rtyp, body, err := ReadMessage(c.c)
+		if err != nil {
+			return fmt.Errorf("i3ipc: %w", err)
+		}
+		if rtyp&(1<<31) != 0 {
+			continue
+		}
+		if rtyp != typ {
+			return fmt.Errorf("i3ipc: reply type %d to request %d", rtyp, typ)
+		}
+		return json.Unmarshal(body, v)
+	}
+}
+
+// Tree returns the layout tree.
+func (c *Conn) Tree() (*Node, error) {
+	var root Node
+	err := c.Request(GetTree, "", &root)
+	return &root, err
+}
+
+// Workspaces lists the workspaces.
+func (c *Conn) Workspaces() ([]Workspace, error) {
+	var ws []Workspace
+	err := c.Request(GetWorkspaces, "", &ws)
+	return ws, err
+}
+
+// Outputs lists the outputs.
+func (c *Conn) Outputs() ([]Output, error) {
+	var outs []Output
+	err := c.Request(GetOutputs, "", &outs)
+	return outs, err
+}
+
+// Command runs a command such as "[con_id=7] focus".
+func (c *Conn) Command(cmd string) error {
+	var res []CommandResult
+	if err := c.Request(RunCommand, cmd, &res); err != nil {
+		return err
+	}
+	for _, r := range res {
+		if !r.Success {
+			return fmt.Errorf("i3ipc: %s: %s", cmd, r.Error)
+		}
+	}
+	return nil
+}
+
+// Walk calls fn for n and every node below it, with the name of the
+// workspace it belongs to ("" above workspaces).
+func Walk(n *Node, fn func(n *Node, workspace string)) {
+	walk(n, "", fn)
+}
+
+func walk(n *Node, ws string, fn func(*Node, string)) {
+	if n.Type == "workspace" {
+		ws = n.Name
+	}
+	fn(n, ws)
+	for _, c := range n.Nodes {
+		walk(c, ws, fn)
+	}
+	for _, c := range n.FloatingNodes {
+		walk(c, ws, fn)
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package i3ipc_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/leejonesio/ttyhop/internal/i3ipc"
+	"github.com/leejonesio/ttyhop/internal/i3ipc/i3ipctest"
+)
+
+func TestMessageRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	if err := i3ipc.WriteMessage(&buf, i3ipc.GetTree, []byte("{}")); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), i3ipc.Magic) || buf.Len() != len(i3ipc.Magic)+8+2 {
+		t.Fatalf("unexpected framing %q", buf.Bytes())
+	}
+	typ, payload, err := i3ipc.ReadMessage(&buf)
+	if err != nil || typ != i3ipc.GetTree || string(payload) != "{}" {
+		t.Errorf("got %d %q %v", typ, payload, err)
+	}
+	if _, _, err := i3ipc.ReadMessage(strings.NewReader("i3-ipd\x00\x00\x00\x00\x00\x00\x00\x00")); err == nil {
+		t.Error("expected bad magic to be rejected")
+	}
+}
+
+func TestConn(t *testing.T) {
+	srv := i3ipctest.NewServer(t, map[uint32]string{
+		i3ipc.GetTree: `{"id": 1, "type": "root", "nodes": [{"id": 2, "type": "output", "nodes": [
+			{"id": 3, "type": "workspace", "name": "1", "nodes": [{"id": 4, "type": "con", "app_id": "foot", "nodes": []}],
+			 "floating_nodes": [{"id": 5, "type": "floating_con", "window": 42, "nodes": []}]}]}]}`,
+	})
+	c, err := i3ipc.Dial(srv.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	// The fake sends an event ahead of every reply; Request must skip it.
+	tree, err := c.Tree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	i3ipc.Walk(tree, func(n *i3ipc.Node, ws string) {
+		if n.IsWindow() {
+			got = append(got, ws)
+		}
+	})
+	if strings.Join(got, ",") != "1,1" {
+		t.Errorf("expected two windows on workspace 1, got %q", got)
+	}
+
+	if err := c.Command("[con_id=4] focus"); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	if cmds := srv.Commands(); len(cmds) != 1 || cmds[0] != "[con_id=4] focus" {
+		t.Errorf("unexpected commands %q", cmds)
+	}
+}
+
+func TestCommandFailure(t *testing.T) {
+	srv := i3ipctest.NewServer(t, map[uint32]string{
+		i3ipc.RunCommand: `[{"success": false, "error": "No matching node"}]`,
+	})
+	c, err := i3ipc.Dial(srv.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if err := c.Command("[con_id=9] focus"); err == nil || !strings.Contains(err.Error(), "No matching node") {
+		t.Errorf("expected the compositor's error, got %v", err)
+	}
+}
+
+func TestSocketPath(t *testing.T) {
+	t.Setenv("SWAYSOCK", "")
+	t.Setenv("I3SOCK", "/run/i3/ipc.sock")
+	if p := i3ipc.SocketPath(); p != "/run/i3/ipc.sock" {
+		t.Errorf("expected $I3SOCK, got %q", p)
+	}
+	t.Setenv("SWAYSOCK", "/run/sway.sock")
+	if p := i3ipc.SocketPath(); p != "/run/sway.sock" {
+		t.Errorf("expected $SWAYSOCK to win, got %q", p)
+	}
+}
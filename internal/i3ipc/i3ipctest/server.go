@@ -0,0 +1,85 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+// Package i3ipctest runs a fake sway/i3 IPC socket that replays recorded
+// replies, so backends can be tested without a compositor.
+package i3ipctest
+
+import (
+	"net"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/leejonesio/ttyhop/internal/i3ipc"
+)
+
+// Server answers each message type with a canned JSON reply and records
+// the commands it is sent.
+type Server struct {
+	// Path is the socket to point SWAYSOCK or I3SOCK at.
+	Path string
+
+	mu       sync.Mutex
+	replies  map[uint32]string
+	commands []string
+}
+
+// NewServer starts a fake server that stops when the test ends. replies
+// maps message types (e.g. i3ipc.GetTree) to their JSON payloads.
+// RUN_COMMAND succeeds unless a reply is given for it.
+func NewServer(t testing.TB, replies map[uint32]string) *Server {
+	t.Helper()
+	s := &Server{Path: filepath.Join(t.TempDir(), "ipc.sock"), replies: replies}
+	ln, err := net.Listen("unix", s.Path)
+	if err != nil {
+		t.Skipf("cannot listen on a unix socket: %v", err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(c)
+		}
+	}()
+	return s
+}
+
+// Commands returns the RUN_COMMAND payloads received so far.
+func (s *Server) Commands() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.commands...)
+}
+
+func (s *Server) serve(c net.Conn) {
+	defer func() { _ = c.Close() }()
+	for {
+		typ, payload, err := i3ipc.ReadMessage(c)
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		reply, ok := s.replies[typ]
+		if typ == i3ipc.RunCommand {
+			s.commands = append(s.commands, string(payload))
+			if !ok {
+				reply, ok = `[{"success":true}]`, true
+			}
+		}
+		s.mu.Unlock()
+		if !ok {
+			reply = "null"
+		}
+		// An unrelated event first, as a subscribed client might see.
+		if err := i3ipc.WriteMessage(c, 1<<31|2, []byte(`{"change":"focus"}`)); err != nil {
+			return
+		}
+		if err := i3ipc.WriteMessage(c, typ, []byte(reply)); err != nil {
+			return
+		}
+	}
+}
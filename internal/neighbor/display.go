@@ -0,0 +1,123 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package neighbor
+
+import (
+	"math"
+	"sort"
+)
+
+// Display is a monitor's frame, in the same coordinates as window rects.
+type Display struct {
+	ID   string
+	Rect Rect
+}
+
+func (r Rect) contains(x, y float64) bool {
+	return x >= r.X && x < r.X+r.W && y >= r.Y && y < r.Y+r.H
+}
+
+// DisplayOf returns the index of the display holding r's center, falling
+// back to the display whose center is nearest. Returns -1 for no displays.
+func DisplayOf(r Rect, displays []Display) int {
+	best, bestDist := -1, math.MaxFloat64
+	for i, d := range displays {
+		if d.Rect.contains(r.MidX(), r.MidY()) {
+			return i
+		}
+		if dist := math.Hypot(d.Rect.MidX()-r.MidX(), d.Rect.MidY()-r.MidY()); dist < bestDist {
+			best, bestDist = i, dist
+		}
+	}
+	return best
+}
+
+// SortDisplays orders displays left to right, then top to bottom, which is
+// the numbering used by "ttyhop display N".
+func SortDisplays(displays []Display) {
+	sort.SliceStable(displays, func(i, j int) bool {
+		a, b := displays[i].Rect, displays[j].Rect
+		if a.X != b.X {
+			return a.X < b.X
+		}
+		return a.Y < b.Y
+	})
+}
+
+// PickOnDisplays is Pick for a multi-monitor layout. Candidates on the
+// current window's display are preferred; only when none lies in dir does it
+// cross to the nearest display in that direction. Crossing maps the current
+// window onto the next display proportionally across the axis of travel, so
+// monitors of different sizes line up by relative position rather than raw
+// pixels. Candidates on other displays are returned as not Eligible.
+func (st Strategy) PickOnDisplays(dir Direction, cur Rect, cands []Candidate, displays []Display) (int, []Scored) {
+	if len(displays) < 2 {
+		return st.Pick(dir, cur, cands)
+	}
+	on := func(d int) []bool {
+		mask := make([]bool, len(cands))
+		for i, c := range cands {
+			mask[i] = DisplayOf(c.Rect, displays) == d
+		}
+		return mask
+	}
+
+	here := DisplayOf(cur, displays)
+	best, scored := st.pickMasked(dir, cur, cands, on(here))
+	if best >= 0 {
+		return best, scored
+	}
+
+	next := adjacentDisplay(dir, here, displays)
+	if next < 0 {
+		return -1, scored
+	}
+	return st.pickMasked(dir, project(dir, cur, displays[here].Rect, displays[next].Rect), cands, on(next))
+}
+
+// pickMasked is Pick restricted to the candidates where mask is true.
+func (st Strategy) pickMasked(dir Direction, cur Rect, cands []Candidate, mask []bool) (int, []Scored) {
+	_, scored := st.Pick(dir, cur, cands)
+	best := -1
+	for i := range scored {
+		if !mask[i] {
+			scored[i].Eligible = false
+			continue
+		}
+		if scored[i].Eligible && (best < 0 || scored[i].Score < scored[best].Score) {
+			best = i
+		}
+	}
+	return best, scored
+}
+
+// adjacentDisplay finds the nearest display lying in dir from displays[from]
+// that shares some span with it across the axis of travel.
+func adjacentDisplay(dir Direction, from int, displays []Display) int {
+	best, bestGap := -1, math.MaxFloat64
+	for i, d := range displays {
+		if i == from {
+			continue
+		}
+		g := measure(dir, displays[from].Rect, d.Rect)
+		if g.along <= 0 || g.overlap <= 0 {
+			continue
+		}
+		if g.gap < bestGap {
+			best, bestGap = i, g.gap
+		}
+	}
+	return best
+}
+
+// project maps r from display src onto display dst across the axis of
+// travel, keeping its position along the axis.
+func project(dir Direction, r, src, dst Rect) Rect {
+	if dir.Vertical() {
+		scale := dst.W / src.W
+		return Rect{X: dst.X + (r.X-src.X)*scale, Y: r.Y, W: r.W * scale, H: r.H}
+	}
+	scale := dst.H / src.H
+	return Rect{X: r.X, Y: dst.Y + (r.Y-src.Y)*scale, W: r.W, H: r.H * scale}
+}
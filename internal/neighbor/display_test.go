@@ -0,0 +1,111 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package neighbor
+
+import "testing"
+
+func TestPickOnDisplays(t *testing.T) {
+	// A 2560x1440 monitor with a 1920x1080 one to its right, top-aligned.
+	displays := []Display{
+		{ID: "main", Rect: Rect{X: 0, Y: 0, W: 2560, H: 1440}},
+		{ID: "side", Rect: Rect{X: 2560, Y: 0, W: 1920, H: 1080}},
+	}
+
+	tests := []struct {
+		name  string
+		dir   Direction
+		cur   Rect
+		cands []Candidate
+		want  string
+	}{
+		{
+			name: "PrefersSameDisplay",
+			dir:  Right,
+			cur:  Rect{X: 0, Y: 0, W: 800, H: 1440},
+			cands: []Candidate{
+				// Nearer by center, but across the bezel.
+				{ID: "side", Rect: Rect{X: 2560, Y: 0, W: 400, H: 1080}},
+				{ID: "same", Rect: Rect{X: 1700, Y: 0, W: 860, H: 1440}},
+			},
+			want: "same",
+		},
+		{
+			name: "CrossesAtDisplayEdge",
+			dir:  Right,
+			cur:  Rect{X: 1280, Y: 0, W: 1280, H: 1440},
+			cands: []Candidate{
+				{ID: "west", Rect: Rect{X: 0, Y: 0, W: 1280, H: 1440}},
+				{ID: "side", Rect: Rect{X: 2560, Y: 0, W: 1920, H: 1080}},
+			},
+			want: "side",
+		},
+		{
+			name: "CrossingMatchesRelativePosition",
+			dir:  Right,
+			// Bottom half of the tall monitor.
+			cur: Rect{X: 1280, Y: 720, W: 1280, H: 720},
+			cands: []Candidate{
+				{ID: "top", Rect: Rect{X: 2560, Y: 0, W: 1920, H: 540}},
+				{ID: "bottom", Rect: Rect{X: 3000, Y: 540, W: 1480, H: 540}},
+			},
+			want: "bottom",
+		},
+		{
+			name: "NoDisplayThatWay",
+			dir:  Left,
+			cur:  Rect{X: 0, Y: 0, W: 1280, H: 1440},
+			cands: []Candidate{
+				{ID: "side", Rect: Rect{X: 2560, Y: 0, W: 1920, H: 1080}},
+			},
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			best, scored := Default.PickOnDisplays(tt.dir, tt.cur, tt.cands, displays)
+			if len(scored) != len(tt.cands) {
+				t.Fatalf("expected %d scored candidates, got %d", len(tt.cands), len(scored))
+			}
+			got := ""
+			if best >= 0 {
+				got = tt.cands[best].ID
+			}
+			if got != tt.want {
+				t.Errorf("chose %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDisplayOf(t *testing.T) {
+	displays := []Display{
+		{ID: "a", Rect: Rect{X: 0, Y: 0, W: 100, H: 100}},
+		{ID: "b", Rect: Rect{X: 100, Y: 0, W: 100, H: 100}},
+	}
+	if got := DisplayOf(Rect{X: 110, Y: 10, W: 50, H: 50}, displays); got != 1 {
+		t.Errorf("expected display 1, got %d", got)
+	}
+	// Entirely off-screen to the right: nearest display wins.
+	if got := DisplayOf(Rect{X: 400, Y: 0, W: 50, H: 50}, displays); got != 1 {
+		t.Errorf("expected nearest display 1, got %d", got)
+	}
+	if got := DisplayOf(Rect{}, nil); got != -1 {
+		t.Errorf("expected -1 without displays, got %d", got)
+	}
+}
+
+func TestSortDisplays(t *testing.T) {
+	displays := []Display{
+		{ID: "right", Rect: Rect{X: 1920, Y: 0, W: 1920, H: 1080}},
+		{ID: "below", Rect: Rect{X: 0, Y: 1080, W: 1920, H: 1080}},
+		{ID: "left", Rect: Rect{X: 0, Y: 0, W: 1920, H: 1080}},
+	}
+	SortDisplays(displays)
+	for i, want := range []string{"left", "below", "right"} {
+		if displays[i].ID != want {
+			t.Errorf("position %d: expected %q, got %q", i, want, displays[i].ID)
+		}
+	}
+}
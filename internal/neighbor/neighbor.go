@@ -0,0 +1,168 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+// Package neighbor picks the window to hop to from a set of candidate
+// rectangles. It has no platform dependencies, so every window backend
+// shares the same rules and they can be tested anywhere.
+package neighbor
+
+import (
+	"fmt"
+	"math"
+)
+
+// Direction is the way a hop travels.
+type Direction int
+
+const (
+	Left Direction = iota
+	Right
+	Up
+	Down
+)
+
+func (d Direction) String() string {
+	switch d {
+	case Left:
+		return "left"
+	case Right:
+		return "right"
+	case Up:
+		return "up"
+	case Down:
+		return "down"
+	}
+	return fmt.Sprintf("Direction(%d)", int(d))
+}
+
+// Vertical reports whether d moves along the y axis.
+func (d Direction) Vertical() bool {
+	return d == Up || d == Down
+}
+
+// Opposite returns the direction pointing the other way.
+func (d Direction) Opposite() Direction {
+	switch d {
+	case Left:
+		return Right
+	case Right:
+		return Left
+	case Up:
+		return Down
+	default:
+		return Up
+	}
+}
+
+// Rect is a window frame in screen coordinates. The origin is top-left and
+// y grows downward, matching the Accessibility API and X11.
+type Rect struct {
+	X, Y, W, H float64
+}
+
+func (r Rect) MidX() float64 { return r.X + r.W/2 }
+func (r Rect) MidY() float64 { return r.Y + r.H/2 }
+
+// Candidate is a window that could be focused. ID is opaque to this package;
+// backends use it to find the window again. Title is the window title, or
+// "" when the backend doesn't read it.
+type Candidate struct {
+	ID    string
+	Rect  Rect
+	Title string
+}
+
+// Scored is a Candidate annotated with how it relates to the current window
+// under a Strategy.
+type Scored struct {
+	Candidate
+	Dx, Dy   float64 // offset of the candidate's center from the current center
+	Distance float64 // center distance along the direction of travel (> 0 is ahead)
+	Score    float64 // strategy score; lower wins
+	Eligible bool    // the strategy accepts this candidate at all
+}
+
+// Band is the fraction of the current window's size, measured across the
+// direction of travel, that a candidate's center may be offset by under the
+// center strategy.
+const Band = 0.75
+
+// Pick chooses a target with the Default strategy.
+func Pick(dir Direction, cur Rect, cands []Candidate) (int, []Scored) {
+	return Default.Pick(dir, cur, cands)
+}
+
+// Pick scores every candidate for a hop from cur in direction dir and returns
+// the index of the eligible candidate with the lowest Score, or -1 when
+// nothing lies that way. The scored slice is in the same order as cands.
+func (st Strategy) Pick(dir Direction, cur Rect, cands []Candidate) (int, []Scored) {
+	scored := make([]Scored, len(cands))
+	best := -1
+	for i, c := range cands {
+		g := measure(dir, cur, c.Rect)
+		s := Scored{Candidate: c, Dx: g.dx, Dy: g.dy, Distance: g.along}
+		s.Score, s.Eligible = st.score(g)
+		scored[i] = s
+		if !s.Eligible {
+			continue
+		}
+		if best < 0 || s.Score < scored[best].Score {
+			best = i
+		}
+	}
+	return best, scored
+}
+
+// Wrap picks the window a hop in dir wraps around to when Pick finds
+// nothing: the eligible candidate farthest away in the opposite direction,
+// e.g. the far-west window when hopping east off the right edge. The scored
+// slice describes the candidates relative to dir.Opposite().
+func (st Strategy) Wrap(dir Direction, cur Rect, cands []Candidate) (int, []Scored) {
+	_, scored := st.Pick(dir.Opposite(), cur, cands)
+	best := -1
+	for i, s := range scored {
+		if !s.Eligible {
+			continue
+		}
+		if best < 0 || s.Distance > scored[best].Distance {
+			best = i
+		}
+	}
+	return best, scored
+}
+
+// geometry describes a candidate relative to the current window, rotated so
+// that "along" always points in the direction of travel.
+type geometry struct {
+	dx, dy   float64 // raw center offset
+	along    float64 // center offset in the direction of travel
+	across   float64 // absolute center offset across it
+	gap      float64 // space between the facing edges; negative when they overlap
+	overlap  float64 // length of the shared span across the direction of travel
+	curSpan  float64 // current window's size across the direction of travel
+	candSpan float64 // candidate's size across the direction of travel
+}
+
+func measure(dir Direction, cur, cand Rect) geometry {
+	g := geometry{dx: cand.MidX() - cur.MidX(), dy: cand.MidY() - cur.MidY()}
+
+	// Project both rects onto the axis of travel (a) and the cross axis (b).
+	curA0, curA1, candA0, candA1 := cur.X, cur.X+cur.W, cand.X, cand.X+cand.W
+	curB0, curB1, candB0, candB1 := cur.Y, cur.Y+cur.H, cand.Y, cand.Y+cand.H
+	g.along, g.across = g.dx, math.Abs(g.dy)
+	if dir.Vertical() {
+		curA0, curA1, candA0, candA1 = cur.Y, cur.Y+cur.H, cand.Y, cand.Y+cand.H
+		curB0, curB1, candB0, candB1 = cur.X, cur.X+cur.W, cand.X, cand.X+cand.W
+		g.along, g.across = g.dy, math.Abs(g.dx)
+	}
+
+	if dir == Left || dir == Up {
+		g.along = -g.along
+		g.gap = curA0 - candA1
+	} else {
+		g.gap = candA0 - curA1
+	}
+	g.overlap = math.Max(0, math.Min(curB1, candB1)-math.Max(curB0, candB0))
+	g.curSpan, g.candSpan = curB1-curB0, candB1-candB0
+	return g
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package neighbor
+
+import "testing"
+
+func TestPick(t *testing.T) {
+	// A 1/3 + 2/3 split on a 3000x1200 ultrawide.
+	left := Rect{X: 0, Y: 0, W: 1000, H: 1200}
+	right := Rect{X: 1000, Y: 0, W: 2000, H: 1200}
+
+	tests := []struct {
+		name  string
+		dir   Direction
+		cur   Rect
+		cands []Candidate
+		want  string // ID of the expected target, "" for none
+	}{
+		{
+			name:  "SplitMovingRight",
+			dir:   Right,
+			cur:   left,
+			cands: []Candidate{{ID: "r", Rect: right}},
+			want:  "r",
+		},
+		{
+			name:  "SplitMovingLeft",
+			dir:   Left,
+			cur:   right,
+			cands: []Candidate{{ID: "l", Rect: left}},
+			want:  "l",
+		},
+		{
+			name:  "NothingToTheRight",
+			dir:   Right,
+			cur:   right,
+			cands: []Candidate{{ID: "l", Rect: left}},
+			want:  "",
+		},
+		{
+			name: "NearestWins",
+			dir:  Right,
+			cur:  Rect{X: 0, Y: 0, W: 500, H: 500},
+			cands: []Candidate{
+				{ID: "far", Rect: Rect{X: 1200, Y: 0, W: 500, H: 500}},
+				{ID: "near", Rect: Rect{X: 600, Y: 0, W: 500, H: 500}},
+			},
+			want: "near",
+		},
+		{
+			name: "OutOfBandIgnored",
+			dir:  Right,
+			cur:  Rect{X: 0, Y: 0, W: 500, H: 400},
+			cands: []Candidate{
+				// Center is 400px lower: beyond 0.75 * 400.
+				{ID: "low", Rect: Rect{X: 600, Y: 400, W: 500, H: 400}},
+				{ID: "level", Rect: Rect{X: 1200, Y: 50, W: 500, H: 400}},
+			},
+			want: "level",
+		},
+		{
+			name: "StackedMovingDown",
+			dir:  Down,
+			cur:  Rect{X: 0, Y: 0, W: 1080, H: 900},
+			cands: []Candidate{
+				{ID: "below", Rect: Rect{X: 0, Y: 960, W: 1080, H: 900}},
+				{ID: "beside", Rect: Rect{X: 1200, Y: 0, W: 1080, H: 900}},
+			},
+			want: "below",
+		},
+		{
+			name: "StackedMovingUp",
+			dir:  Up,
+			cur:  Rect{X: 0, Y: 960, W: 1080, H: 900},
+			cands: []Candidate{
+				{ID: "above", Rect: Rect{X: 40, Y: 0, W: 1000, H: 900}},
+			},
+			want: "above",
+		},
+		{
+			name:  "NoCandidates",
+			dir:   Left,
+			cur:   left,
+			cands: nil,
+			want:  "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			best, scored := Pick(tt.dir, tt.cur, tt.cands)
+			if len(scored) != len(tt.cands) {
+				t.Fatalf("expected %d scored candidates, got %d", len(tt.cands), len(scored))
+			}
+			got := ""
+			if best >= 0 {
+				got = scored[best].ID
+			}
+			if got != tt.want {
+				t.Errorf("Pick(%v) chose %q, want %q", tt.dir, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWrap(t *testing.T) {
+	// Three windows in a row plus one stacked far below.
+	cur := Rect{X: 2000, Y: 0, W: 900, H: 1000}
+	cands := []Candidate{
+		{ID: "west", Rect: Rect{X: 0, Y: 0, W: 900, H: 1000}},
+		{ID: "middle", Rect: Rect{X: 1000, Y: 0, W: 900, H: 1000}},
+		{ID: "below", Rect: Rect{X: 0, Y: 2000, W: 900, H: 1000}},
+	}
+
+	best, _ := Default.Wrap(Right, cur, cands)
+	if best < 0 || cands[best].ID != "west" {
+		t.Errorf("expected wrap east to land on the far-west window, got index %d", best)
+	}
+
+	best, _ = Default.Wrap(Right, cur, nil)
+	if best != -1 {
+		t.Errorf("expected no wrap target without candidates, got %d", best)
+	}
+
+	// Wrapping west from the far-west window goes to the far-east one.
+	best, _ = Default.Wrap(Left, cands[0].Rect, []Candidate{cands[1], {ID: "east", Rect: cur}})
+	if best != 1 {
+		t.Errorf("expected wrap west to land on the far-east window, got index %d", best)
+	}
+}
+
+func TestPickScores(t *testing.T) {
+	cur := Rect{X: 0, Y: 0, W: 100, H: 100}
+	_, scored := Pick(Left, cur, []Candidate{{ID: "a", Rect: Rect{X: -200, Y: 20, W: 100, H: 100}}})
+	s := scored[0]
+	if s.Dx != -200 || s.Dy != 20 {
+		t.Errorf("expected offset (-200, 20), got (%v, %v)", s.Dx, s.Dy)
+	}
+	if !s.Eligible || s.Distance != 200 || s.Score != 200 {
+		t.Errorf("expected eligible candidate at distance 200, got %+v", s)
+	}
+}
+
+func TestDirection(t *testing.T) {
+	for _, d := range []Direction{Left, Right, Up, Down} {
+		if d.Opposite().Opposite() != d {
+			t.Errorf("%v: Opposite is not an involution", d)
+		}
+		if d.Vertical() != d.Opposite().Vertical() {
+			t.Errorf("%v: Opposite changed axis", d)
+		}
+	}
+}
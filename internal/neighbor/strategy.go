@@ -0,0 +1,90 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package neighbor
+
+import "math"
+
+// Strategy is a named rule for scoring candidates. Lower scores win;
+// candidates the strategy rejects are never chosen.
+type Strategy struct {
+	Name string
+	Doc  string
+
+	score func(g geometry) (float64, bool)
+}
+
+// coneHalfAngle bounds the cone strategy: candidates more than 45 degrees
+// off the axis of travel are ignored.
+const coneHalfAngle = math.Pi / 4
+
+var (
+	// Center is the original heuristic: the nearest center along the axis of
+	// travel, among windows whose center is within Band of ours across it.
+	Center = Strategy{
+		Name: "center",
+		Doc:  "nearest center among windows within 0.75x our size across the axis (default)",
+		score: func(g geometry) (float64, bool) {
+			return g.along, g.along > 0 && g.across <= g.curSpan*Band
+		},
+	}
+
+	// Edge measures the gap between facing edges, so a large window right
+	// next to us beats a small one whose center happens to be closer.
+	Edge = Strategy{
+		Name: "edge",
+		Doc:  "smallest gap between facing edges among windows that overlap us across the axis",
+		score: func(g geometry) (float64, bool) {
+			return math.Max(g.gap, 0), g.along > 0 && g.overlap > 0
+		},
+	}
+
+	// Overlap divides the center distance by how much of the smaller window
+	// is shared across the axis, favoring windows that line up with us.
+	Overlap = Strategy{
+		Name: "overlap",
+		Doc:  "center distance weighted by shared span across the axis",
+		score: func(g geometry) (float64, bool) {
+			if g.along <= 0 || g.overlap <= 0 {
+				return 0, false
+			}
+			frac := g.overlap / math.Min(g.curSpan, g.candSpan)
+			return g.along / frac, true
+		},
+	}
+
+	// Cone accepts any center within 45 degrees of the axis of travel and
+	// penalizes the straight-line distance by how far off-axis it is.
+	Cone = Strategy{
+		Name: "cone",
+		Doc:  "straight-line distance penalized by angle, within a 45 degree cone",
+		score: func(g geometry) (float64, bool) {
+			if g.along <= 0 {
+				return 0, false
+			}
+			angle := math.Atan2(g.across, g.along)
+			if angle > coneHalfAngle {
+				return 0, false
+			}
+			return math.Hypot(g.along, g.across) * (1 + angle/coneHalfAngle), true
+		},
+	}
+
+	// Default is the strategy used when none is configured.
+	Default = Center
+)
+
+// Strategies lists every built-in strategy, default first.
+func Strategies() []Strategy {
+	return []Strategy{Center, Edge, Overlap, Cone}
+}
+
+// LookupStrategy finds a built-in strategy by name.
+func LookupStrategy(name string) (Strategy, bool) {
+	for _, s := range Strategies() {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return Strategy{}, false
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package neighbor
+
+import "testing"
+
+func TestStrategies(t *testing.T) {
+	// rectAt builds a rect from its center and size, which keeps the
+	// layouts below readable.
+	rectAt := func(cx, cy, w, h float64) Rect { return Rect{X: cx - w/2, Y: cy - h/2, W: w, H: h} }
+
+	tests := []struct {
+		name  string
+		dir   Direction
+		cur   Rect
+		cands []Candidate
+		want  map[string]string // strategy name -> expected target ID ("" for none)
+	}{
+		{
+			name: "BigNeighborBehindSmallOne",
+			dir:  Right,
+			cur:  Rect{X: 0, Y: 0, W: 1000, H: 1000},
+			cands: []Candidate{
+				{ID: "big", Rect: Rect{X: 1010, Y: 0, W: 2000, H: 1000}},
+				{ID: "small", Rect: Rect{X: 1600, Y: 400, W: 200, H: 200}},
+			},
+			want: map[string]string{"center": "small", "edge": "big", "overlap": "small", "cone": "small"},
+		},
+		{
+			name: "OffsetBeyondBand",
+			dir:  Right,
+			cur:  Rect{X: 0, Y: 0, W: 1000, H: 600},
+			cands: []Candidate{
+				{ID: "low", Rect: Rect{X: 1100, Y: 500, W: 800, H: 600}},
+			},
+			want: map[string]string{"center": "", "edge": "low", "overlap": "low", "cone": "low"},
+		},
+		{
+			name: "SliverOverlapLosesUnderOverlap",
+			dir:  Right,
+			cur:  Rect{X: 0, Y: 0, W: 1000, H: 1000},
+			cands: []Candidate{
+				{ID: "sliver", Rect: Rect{X: 1100, Y: 900, W: 500, H: 1000}},
+				{ID: "aligned", Rect: Rect{X: 1500, Y: 0, W: 500, H: 1000}},
+			},
+			want: map[string]string{"center": "aligned", "edge": "sliver", "overlap": "aligned", "cone": "aligned"},
+		},
+		{
+			name: "DiagonalOnlyInsideCone",
+			dir:  Down,
+			cur:  rectAt(50, 50, 100, 100),
+			cands: []Candidate{
+				{ID: "diag", Rect: rectAt(230, 250, 100, 100)},
+			},
+			want: map[string]string{"center": "", "edge": "", "overlap": "", "cone": "diag"},
+		},
+		{
+			name: "TooSteepForCone",
+			dir:  Right,
+			cur:  rectAt(50, 50, 100, 100),
+			cands: []Candidate{
+				{ID: "steep", Rect: rectAt(100, 300, 100, 100)},
+			},
+			want: map[string]string{"center": "", "edge": "", "overlap": "", "cone": ""},
+		},
+	}
+
+	for _, tt := range tests {
+		for _, st := range Strategies() {
+			t.Run(tt.name+"/"+st.Name, func(t *testing.T) {
+				best, _ := st.Pick(tt.dir, tt.cur, tt.cands)
+				got := ""
+				if best >= 0 {
+					got = tt.cands[best].ID
+				}
+				if want := tt.want[st.Name]; got != want {
+					t.Errorf("chose %q, want %q", got, want)
+				}
+			})
+		}
+	}
+}
+
+func TestLookupStrategy(t *testing.T) {
+	for _, st := range Strategies() {
+		got, ok := LookupStrategy(st.Name)
+		if !ok || got.Name != st.Name {
+			t.Errorf("LookupStrategy(%q) = %q, %v", st.Name, got.Name, ok)
+		}
+	}
+	if _, ok := LookupStrategy("nearest-ish"); ok {
+		t.Error("expected unknown strategy to be rejected")
+	}
+	if Default.Name != "center" {
+		t.Errorf("expected default strategy to stay center, got %q", Default.Name)
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+// Package niri is a minimal client for niri's IPC socket ($NIRI_SOCKET).
+// A request is one line of JSON, such as "Windows" or {"Action": ...}, and
+// the reply is one line holding {"Ok": {"<Request>": ...}} or {"Err": "..."}.
+package niri
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"time"
+)
+
+// timeout bounds one request, so a wedged compositor can't hang a hop.
+const timeout = 2 * time.Second
+
+// Layout places a window. PosInScrollingLayout is its 1-based (column,
+// tile) index and is nil for floating windows; TilePosInWorkspaceView is
+// its tile's position on the output and is nil when it is off-screen.
+type Layout struct {
+	PosInScrollingLayout   *[2]int     `json:"pos_in_scrolling_layout"`
+	TileSize               [2]float64  `json:"tile_size"`
+	TilePosInWorkspaceView *[2]float64 `json:"tile_pos_in_workspace_view"`
+}
+
+// Window is one entry of the Windows reply. Layout is nil before niri
+// 25.05.
+type Window struct {
+	ID          uint64  `json:"id"`
+	Title       string  `json:"title"`
+	AppID       string  `json:"app_id"`
+	WorkspaceID *uint64 `json:"workspace_id"`
+	IsFocused   bool    `json:"is_focused"`
+	IsFloating  bool    `json:"is_floating"`
+	Layout      *Layout `json:"layout"`
+}
+
+// Workspace is one entry of the Workspaces reply.
+type Workspace struct {
+	ID        uint64 `json:"id"`
+	Idx       int    `json:"idx"`
+	Name      string `json:"name"`
+	Output    string `json:"output"`
+	IsActive  bool   `json:"is_active"`
+	IsFocused bool   `json:"is_focused"`
+}
+
+// Logical is an output's position and size in the global logical space.
+type Logical struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// Output is one entry of the Outputs reply. Logical is nil when the output
+// is disabled.
+type Output struct {
+	Name    string   `json:"name"`
+	Logical *Logical `json:"logical"`
+}
+
+// SocketPath returns $NIRI_SOCKET.
+func SocketPath() string {
+	return os.Getenv("NIRI_SOCKET")
+}
+
+// Request sends req and decodes the Ok payload of the reply into v (when v
+// is non-nil). name is the key the payload is wrapped in, e.g. "Windows".
+func Request(req any, name string, v any) error {
+	path := SocketPath()
+	if path == "" {
+		return errors.New("niri: NIRI_SOCKET is not set")
+	}
+	c, err := net.DialTimeout("unix", path, timeout)
+	if err != nil {
+		return fmt.Errorf("niri: %w", err)
+	}
+	defer func() { _ = c.Close() }()
+	_ = c.SetDeadline(time.Now().Add(timeout))
+	line, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	if _, err := c.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("niri: %w", err)
+	}
+	out, err := bufio.NewReader(c).ReadBytes('\n')
+	if err != nil {
+		return fmt.Errorf("niri: %w", err)
+	}
+	var reply struct {
+		Ok  json.RawMessage `json:"Ok"`
+		Err *string         `json:"Err"`
+	}
+	if err := json.Unmarshal(out, &reply); err != nil {
+		return fmt.Errorf("niri: %w", err)
+	}
+	if reply.Err != nil {
+		return fmt.Errorf("niri: %s", *reply.Err)
+	}
+	if v == nil {
+		return nil
+	}
+	var wrapped map[string]json.RawMessage
+	if err := json.Unmarshal(reply.Ok, &wrapped); err != nil {
+		return fmt.Errorf("niri: %w", err)
+	}
+	payload, ok := wrapped[name]
+	if !ok {
+		return fmt.Errorf("niri: reply to %s has no %s", line, name)
+	}
+	return json.Unmarshal(payload, v)
+}
+
+// Windows lists every window.
+func Windows() ([]Window, error) {
+	var ws []Window
+	err := Request("Windows", "Windows", &ws)
+	return ws, err
+}
+
+// Workspaces lists every workspace.
+func Workspaces() ([]Workspace, error) {
+	var ws []Workspace
+	err := Request("Workspaces", "Workspaces", &ws)
+	return ws, err
+}
+
+// Outputs lists the outputs by name.
+func Outputs() (map[string]Output, error) {
+	var outs map[string]Output
+	err := Request("Outputs", "Outputs", &outs)
+	return outs, err
+}
+
+// FocusWindow focuses the window with the given id, scrolling its column
+// into view.
+func FocusWindow(id uint64) error {
+	return Request(map[string]any{"Action": map[string]any{"FocusWindow": map[string]uint64{"id": id}}}, "", nil)
+}
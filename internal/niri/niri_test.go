@@ -0,0 +1,44 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package niri_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/leejonesio/ttyhop/internal/niri"
+	"github.com/leejonesio/ttyhop/internal/niri/niritest"
+)
+
+func TestRequests(t *testing.T) {
+	srv := niritest.NewServer(t, map[string]string{
+		"Outputs": `{"eDP-1": {"name": "eDP-1", "logical": {"x": 0, "y": 0, "width": 1440, "height": 900}}, "DP-3": {"name": "DP-3", "logical": null}}`,
+	})
+	outs, err := niri.Outputs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l := outs["eDP-1"].Logical; l == nil || l.Width != 1440 || outs["DP-3"].Logical != nil {
+		t.Errorf("unexpected outputs %+v", outs)
+	}
+
+	// Unscripted requests come back as {"Err": ...}.
+	if _, err := niri.Windows(); err == nil || !strings.Contains(err.Error(), "unknown request") {
+		t.Errorf("expected niri's error, got %v", err)
+	}
+
+	if err := niri.FocusWindow(42); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	if a := srv.Actions(); len(a) != 1 || a[0] != `{"Action":{"FocusWindow":{"id":42}}}` {
+		t.Errorf("unexpected actions %q", a)
+	}
+}
+
+func TestNoSocket(t *testing.T) {
+	t.Setenv("NIRI_SOCKET", "")
+	if _, err := niri.Windows(); err == nil {
+		t.Error("expected an error without $NIRI_SOCKET")
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+// Package niritest runs a scripted stand-in for niri's IPC socket, so
+// backends can be tested without a compositor.
+package niritest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Server answers each request with a scripted payload and records the
+// actions it is sent.
+type Server struct {
+	// Path is the socket to point NIRI_SOCKET at.
+	Path string
+
+	mu      sync.Mutex
+	replies map[string]string
+	actions []string
+}
+
+// NewServer starts a stand-in that stops when the test ends and points
+// NIRI_SOCKET at it. replies maps requests (e.g. "Windows") to the JSON
+// payload wrapped in their Ok reply; other requests get an Err. Actions
+// are recorded and handled.
+func NewServer(t testing.TB, replies map[string]string) *Server {
+	t.Helper()
+	// A short directory keeps the socket path under the sun_path limit.
+	dir, err := os.MkdirTemp("", "niri")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	s := &Server{Path: filepath.Join(dir, "niri.sock"), replies: replies}
+	ln, err := net.Listen("unix", s.Path)
+	if err != nil {
+		t.Skipf("cannot listen on a unix socket: %v", err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+	t.Setenv("NIRI_SOCKET", s.Path)
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(c)
+		}
+	}()
+	return s
+}
+
+// Actions returns the Action requests received so far, as raw JSON.
+func (s *Server) Actions() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.actions...)
+}
+
+func (s *Server) serve(c net.Conn) {
+	defer func() { _ = c.Close() }()
+	line, err := bufio.NewReader(c).ReadString('\n')
+	if err != nil {
+		return
+	}
+	req := strings.TrimSpace(line)
+	s.mu.Lock()
+	var reply string
+	if strings.HasPrefix(req, `{"Action":`) {
+		s.actions = append(s.actions, req)
+		reply = `{"Ok":"Handled"}`
+	} else if name := strings.Trim(req, `"`); s.replies[name] != "" {
+		var payload bytes.Buffer
+		if err := json.Compact(&payload, []byte(s.replies[name])); err != nil {
+			payload.WriteString(`"bad script"`)
+		}
+		reply = fmt.Sprintf(`{"Ok":{%q:%s}}`, name, payload.String())
+	} else {
+		reply = fmt.Sprintf(`{"Err":"unknown request %s"}`, strings.ReplaceAll(req, `"`, `'`))
+	}
+	s.mu.Unlock()
+	_, _ = io.WriteString(c, reply+"\n")
+}
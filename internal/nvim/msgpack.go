@@ -0,0 +1,259 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package nvim
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Ext is a msgpack extension value. nvim sends Buffer, Window and Tabpage
+// handles as extensions.
+type Ext struct {
+	Type int8
+	Data []byte
+}
+
+// Encode appends the msgpack encoding of v to b. It handles the values
+// the API calls need: nil, bool, integers, float64, string, []byte, []any,
+// []string and map[string]any.
+func Encode(b []byte, v any) ([]byte, error) {
+	switch v := v.(type) {
+	case nil:
+		return append(b, 0xc0), nil
+	case bool:
+		if v {
+			return append(b, 0xc3), nil
+		}
+		return append(b, 0xc2), nil
+	case int:
+		return encodeInt(b, int64(v)), nil
+	case int64:
+		return encodeInt(b, v), nil
+	case uint32:
+		return encodeInt(b, int64(v)), nil
+	case uint64:
+		if v > math.MaxInt64 {
+			return binary.BigEndian.AppendUint64(append(b, 0xcf), v), nil
+		}
+		return encodeInt(b, int64(v)), nil
+	case float64:
+		return binary.BigEndian.AppendUint64(append(b, 0xcb), math.Float64bits(v)), nil
+	case string:
+		b = encodeLen(b, len(v), 0xa0, 32, 0xd9, 0xda, 0xdb)
+		return append(b, v...), nil
+	case []byte:
+		b = encodeLen(b, len(v), 0, 0, 0xc4, 0xc5, 0xc6)
+		return append(b, v...), nil
+	case []string:
+		b = encodeLen(b, len(v), 0x90, 16, 0, 0xdc, 0xdd)
+		for _, s := range v {
+			b, _ = Encode(b, s)
+		}
+		return b, nil
+	case []any:
+		b = encodeLen(b, len(v), 0x90, 16, 0, 0xdc, 0xdd)
+		for _, e := range v {
+			var err error
+			if b, err = Encode(b, e); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	case map[string]any:
+		b = encodeLen(b, len(v), 0x80, 16, 0, 0xde, 0xdf)
+		for k, e := range v {
+			b, _ = Encode(b, k)
+			var err error
+			if b, err = Encode(b, e); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	}
+	return nil, fmt.Errorf("msgpack: cannot encode %T", v)
+}
+
+func encodeInt(b []byte, n int64) []byte {
+	switch {
+	case n >= 0 && n < 128, n < 0 && n >= -32:
+		return append(b, byte(n))
+	case n >= 0 && n <= math.MaxUint8:
+		return append(b, 0xcc, byte(n))
+	case n >= 0 && n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, 0xcd), uint16(n))
+	case n >= 0 && n <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, 0xce), uint32(n))
+	}
+	return binary.BigEndian.AppendUint64(append(b, 0xd3), uint64(n))
+}
+
+// encodeLen appends a length header: the fix form (fix|n, when n < fixMax)
+// or the 8-, 16- or 32-bit form. A zero code means that form doesn't exist.
+func encodeLen(b []byte, n int, fix byte, fixMax int, c8, c16, c32 byte) []byte {
+	switch {
+	case n < fixMax:
+		return append(b, fix|byte(n))
+	case c8 != 0 && n <= math.MaxUint8:
+		return append(b, c8, byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, c16), uint16(n))
+	}
+	return binary.BigEndian.AppendUint32(append(b, c32), uint32(n))
+}
+
+// Decode reads one msgpack value. Integers decode to int64 (or uint64 when
+// too large), strings to string, binaries to []byte, arrays to []any, maps
+// to map[any]any and extensions to Ext.
+func Decode(r *bufio.Reader) (any, error) {
+	c, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case c <= 0x7f:
+		return int64(c), nil
+	case c >= 0xe0:
+		return int64(int8(c)), nil
+	case c&0xf0 == 0x80:
+		return decodeMap(r, int(c&0x0f))
+	case c&0xf0 == 0x90:
+		return decodeArray(r, int(c&0x0f))
+	case c&0xe0 == 0xa0:
+		return decodeString(r, int(c&0x1f))
+	}
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6:
+		n, err := readUint(r, 1<<(c-0xc4))
+		if err != nil {
+			return nil, err
+		}
+		return readN(r, int(n))
+	case 0xc7, 0xc8, 0xc9:
+		n, err := readUint(r, 1<<(c-0xc7))
+		if err != nil {
+			return nil, err
+		}
+		return decodeExt(r, int(n))
+	case 0xca:
+		n, err := readUint(r, 4)
+		return float64(math.Float32frombits(uint32(n))), err
+	case 0xcb:
+		n, err := readUint(r, 8)
+		return math.Float64frombits(n), err
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		n, err := readUint(r, 1<<(c-0xcc))
+		if n > math.MaxInt64 {
+			return n, err
+		}
+		return int64(n), err
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		size := 1 << (c - 0xd0)
+		n, err := readUint(r, size)
+		shift := 64 - 8*size
+		return int64(n<<shift) >> shift, err
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return decodeExt(r, 1<<(c-0xd4))
+	case 0xd9, 0xda, 0xdb:
+		n, err := readUint(r, 1<<(c-0xd9))
+		if err != nil {
+			return nil, err
+		}
+		return decodeString(r, int(n))
+	case 0xdc, 0xdd:
+		n, err := readUint(r, 2<<(c-0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return decodeArray(r, int(n))
+	case 0xde, 0xdf:
+		n, err := readUint(r, 2<<(c-0xde))
+		if err != nil {
+			return nil, err
+		}
+		return decodeMap(r, int(n))
+	}
+	return nil, fmt.Errorf("msgpack: unknown type 0x%02x", c)
+}
+
+// maxPrealloc bounds what the decoder reserves up front. Lengths come from
+// the peer, so past it buffers grow as data arrives.
+const maxPrealloc = 1024
+
+func readN(r *bufio.Reader, n int) ([]byte, error) {
+	var b bytes.Buffer
+	b.Grow(min(n, maxPrealloc))
+	if _, err := io.CopyN(&b, r, int64(n)); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func readUint(r *bufio.Reader, size int) (uint64, error) {
+	b, err := readN(r, size)
+	if err != nil {
+		return 0, err
+	}
+	var n uint64
+	for _, c := range b {
+		n = n<<8 | uint64(c)
+	}
+	return n, nil
+}
+
+func decodeString(r *bufio.Reader, n int) (any, error) {
+	b, err := readN(r, n)
+	return string(b), err
+}
+
+func decodeExt(r *bufio.Reader, n int) (any, error) {
+	t, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	b, err := readN(r, n)
+	return Ext{Type: int8(t), Data: b}, err
+}
+
+func decodeArray(r *bufio.Reader, n int) (any, error) {
+	a := make([]any, 0, min(n, maxPrealloc))
+	for i := 0; i < n; i++ {
+		v, err := Decode(r)
+		if err != nil {
+			return nil, err
+		}
+		a = append(a, v)
+	}
+	return a, nil
+}
+
+func decodeMap(r *bufio.Reader, n int) (any, error) {
+	m := make(map[any]any, min(n, maxPrealloc))
+	for i := 0; i < n; i++ {
+		k, err := Decode(r)
+		if err != nil {
+			return nil, err
+		}
+		v, err := Decode(r)
+		if err != nil {
+			return nil, err
+		}
+		switch k.(type) {
+		case []any, map[any]any, []byte, Ext:
+			return nil, fmt.Errorf("msgpack: unsupported map key %T", k)
+		}
+		m[k] = v
+	}
+	return m, nil
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+// Package nvim is a minimal msgpack-RPC client for Neovim's API, enough to
+// run Ex commands and evaluate expressions in a running nvim. A request is
+// [0, msgid, method, params] and its response [1, msgid, error, result];
+// notifications ([2, method, params]) are skipped.
+package nvim
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// timeout bounds dialing and each call, so a busy nvim can't hang a hop.
+const timeout = 500 * time.Millisecond
+
+// Client is a connection to one nvim.
+type Client struct {
+	conn  net.Conn
+	r     *bufio.Reader
+	msgID uint32
+}
+
+// Dial connects to an nvim server address: a unix socket path (or named
+// pipe) or host:port, as given to --listen or found in $NVIM.
+func Dial(addr string) (*Client, error) {
+	network := "unix"
+	if !strings.Contains(addr, "/") && strings.Contains(addr, ":") {
+		network = "tcp"
+	}
+	conn, err := net.DialTimeout(network, addr, timeout)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{conn: conn, r: bufio.NewReader(conn)}, nil
+}
+
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Call invokes an API method and returns its result.
+func (c *Client) Call(method string, args ...any) (any, error) {
+	c.msgID++
+	id := c.msgID
+	if args == nil {
+		args = []any{}
+	}
+	req, err := Encode(nil, []any{0, id, method, args})
+	if err != nil {
+		return nil, err
+	}
+	_ = c.conn.SetDeadline(time.Now().Add(timeout))
+	if _, err := c.conn.Write(req); err != nil {
+		return nil, err
+	}
+	for {
+		v, err := Decode(c.r)
+		if err != nil {
+			return nil, err
+		}
+		msg, ok := v.([]any)
+		if !ok || len(msg) == 0 {
+			return nil, errors.New("nvim: malformed message")
+		}
+		if kind, _ := msg[0].(int64); kind != 1 {
+			continue // a notification or request from nvim
+		}
+		if len(msg) != 4 {
+			return nil, errors.New("nvim: malformed response")
+		}
+		if got, _ := msg[1].(int64); got != int64(id) {
+			continue
+		}
+		if msg[2] != nil {
+			return nil, fmt.Errorf("nvim: %s: %s", method, errorMessage(msg[2]))
+		}
+		return msg[3], nil
+	}
+}
+
+// errorMessage extracts the text of an API error, sent as [type, message].
+func errorMessage(e any) string {
+	if a, ok := e.([]any); ok && len(a) == 2 {
+		if s, ok := a[1].(string); ok {
+			return s
+		}
+	}
+	return fmt.Sprint(e)
+}
+
+// Command runs an Ex command, e.g. "wincmd h".
+func (c *Client) Command(cmd string) error {
+	_, err := c.Call("nvim_command", cmd)
+	return err
+}
+
+// EvalInt evaluates a Vimscript expression with an integer result, e.g.
+// "winnr()".
+func (c *Client) EvalInt(expr string) (int64, error) {
+	v, err := c.Call("nvim_eval", expr)
+	if err != nil {
+		return 0, err
+	}
+	switch n := v.(type) {
+	case int64:
+		return n, nil
+	case uint64:
+		return int64(n), nil
+	}
+	return 0, fmt.Errorf("nvim: %s returned %T, not an integer", expr, v)
+}
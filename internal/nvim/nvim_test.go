@@ -0,0 +1,105 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package nvim_test
+
+import (
+	"bufio"
+	"bytes"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/leejonesio/ttyhop/internal/nvim"
+	"github.com/leejonesio/ttyhop/internal/nvim/nvimtest"
+)
+
+func TestRoundTrip(t *testing.T) {
+	long := strings.Repeat("x", 300)
+	tests := []struct {
+		in   any
+		want any
+	}{
+		{nil, nil},
+		{true, true},
+		{false, false},
+		{0, int64(0)},
+		{127, int64(127)},
+		{200, int64(200)},
+		{70000, int64(70000)},
+		{-1, int64(-1)},
+		{-33, int64(-33)},
+		{int64(math.MinInt64), int64(math.MinInt64)},
+		{uint64(math.MaxUint64), uint64(math.MaxUint64)},
+		{1.5, 1.5},
+		{"wincmd h", "wincmd h"},
+		{long, long},
+		{[]byte{1, 2}, []byte{1, 2}},
+		{[]string{"a", "b"}, []any{"a", "b"}},
+		{[]any{0, uint32(7), "nvim_eval", []any{"winnr()"}}, []any{int64(0), int64(7), "nvim_eval", []any{"winnr()"}}},
+		{map[string]any{"mode": "n"}, map[any]any{"mode": "n"}},
+	}
+	for _, tt := range tests {
+		b, err := nvim.Encode(nil, tt.in)
+		if err != nil {
+			t.Fatalf("Encode(%v): %v", tt.in, err)
+		}
+		got, err := nvim.Decode(bufio.NewReader(bytes.NewReader(b)))
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("round trip of %v = %#v, %v; want %#v", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestDecodeExt(t *testing.T) {
+	// A Window handle as nvim sends it: fixext1, type 1, handle 1000 as a uint16.
+	got, err := nvim.Decode(bufio.NewReader(bytes.NewReader([]byte{0xd5, 0x01, 0xcd, 0x03})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (nvim.Ext{Type: 1, Data: []byte{0xcd, 0x03}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v, want %#v", got, want)
+	}
+}
+
+func TestDecodeTruncated(t *testing.T) {
+	// Headers claiming about 4G elements or bytes, with nothing after them,
+	// must fail without reserving room for them first.
+	for _, b := range [][]byte{
+		{0xdd, 0xff, 0xff, 0xff, 0xff},
+		{0xdf, 0xff, 0xff, 0xff, 0xff},
+		{0xc6, 0xff, 0xff, 0xff, 0xff},
+		{0xdb, 0xff, 0xff, 0xff, 0xff, 'a'},
+	} {
+		if _, err := nvim.Decode(bufio.NewReader(bytes.NewReader(b))); err == nil {
+			t.Errorf("Decode(% x): expected an error", b)
+		}
+	}
+}
+
+func TestClient(t *testing.T) {
+	s := nvimtest.NewServer(t, []nvimtest.Window{
+		{Row: 0, Col: 0, Rows: 40, Cols: 80},
+		{Row: 0, Col: 81, Rows: 40, Cols: 80},
+	}, 1)
+	c, err := nvim.Dial(s.Addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if err := c.Command("wincmd l"); err != nil {
+		t.Fatal(err)
+	}
+	n, err := c.EvalInt("winnr()")
+	if err != nil || n != 2 {
+		t.Errorf("winnr() = %d, %v; want 2", n, err)
+	}
+	if err := c.Command("bogus"); err == nil || !strings.Contains(err.Error(), "unsupported") {
+		t.Errorf("expected the server's error, got %v", err)
+	}
+	if _, err := nvim.Dial("/nonexistent/nvim.sock"); err == nil {
+		t.Error("expected dialing a missing socket to fail")
+	}
+}
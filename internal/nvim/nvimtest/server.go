@@ -0,0 +1,166 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+// Package nvimtest runs a stand-in for an nvim RPC server holding a grid
+// of split windows, so split navigation can be tested without Neovim.
+package nvimtest
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/leejonesio/ttyhop/internal/nvim"
+)
+
+// Window is one split, placed in cells like nvim_win_get_position and
+// nvim_win_get_width/height report it. Splits are separated by one cell.
+type Window struct {
+	Row, Col, Rows, Cols int
+}
+
+// Server answers nvim_eval("winnr()") and nvim_command("[N]wincmd
+// h/j/k/l") for its windows and records every call.
+type Server struct {
+	// Addr is the socket to dial.
+	Addr string
+
+	mu      sync.Mutex
+	windows []Window
+	current int
+	calls   []string
+}
+
+var wincmd = regexp.MustCompile(`^(\d*)wincmd ([hjkl])$`)
+
+// NewServer starts a stand-in that stops when the test ends, with windows
+// numbered from 1 in order and window current focused.
+func NewServer(t testing.TB, windows []Window, current int) *Server {
+	t.Helper()
+	// A short directory keeps the socket path under the sun_path limit.
+	dir, err := os.MkdirTemp("", "nvim")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	s := &Server{Addr: filepath.Join(dir, "nvim.sock"), windows: windows, current: current}
+	ln, err := net.Listen("unix", s.Addr)
+	if err != nil {
+		t.Skipf("cannot listen on a unix socket: %v", err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(c)
+		}
+	}()
+	return s
+}
+
+// Current returns the focused window's number.
+func (s *Server) Current() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.current
+}
+
+// Calls returns every call so far as "method arg".
+func (s *Server) Calls() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.calls...)
+}
+
+func (s *Server) serve(c net.Conn) {
+	defer c.Close()
+	r := bufio.NewReader(c)
+	for {
+		v, err := nvim.Decode(r)
+		if err != nil {
+			return
+		}
+		msg, ok := v.([]any)
+		if !ok || len(msg) != 4 {
+			return
+		}
+		method, _ := msg[2].(string)
+		params, _ := msg[3].([]any)
+		result, callErr := s.handle(method, params)
+		var errVal any
+		if callErr != nil {
+			errVal = []any{0, callErr.Error()}
+		}
+		b, err := nvim.Encode(nil, []any{1, msg[1], errVal, result})
+		if err != nil {
+			return
+		}
+		if _, err := c.Write(b); err != nil {
+			return
+		}
+	}
+}
+
+func (s *Server) handle(method string, params []any) (any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var arg string
+	if len(params) > 0 {
+		arg, _ = params[0].(string)
+	}
+	s.calls = append(s.calls, method+" "+arg)
+	switch {
+	case method == "nvim_eval" && arg == "winnr()":
+		return s.current, nil
+	case method == "nvim_command" && wincmd.MatchString(arg):
+		m := wincmd.FindStringSubmatch(arg)
+		count, _ := strconv.Atoi(m[1])
+		for i := 0; i < max(count, 1); i++ {
+			next := s.neighbor(m[2])
+			if next == 0 {
+				break
+			}
+			s.current = next
+		}
+		return nil, nil
+	}
+	return nil, fmt.Errorf("nvimtest: unsupported %s %q", method, arg)
+}
+
+// neighbor returns the window across the separator from the current one
+// in the direction of key, overlapping it the most, or 0 for none.
+func (s *Server) neighbor(key string) int {
+	cur := s.windows[s.current-1]
+	best, bestOverlap := 0, 0
+	for i, w := range s.windows {
+		var adjacent bool
+		var overlap int
+		switch key {
+		case "h":
+			adjacent, overlap = w.Col+w.Cols+1 == cur.Col, span(w.Row, w.Rows, cur.Row, cur.Rows)
+		case "l":
+			adjacent, overlap = cur.Col+cur.Cols+1 == w.Col, span(w.Row, w.Rows, cur.Row, cur.Rows)
+		case "k":
+			adjacent, overlap = w.Row+w.Rows+1 == cur.Row, span(w.Col, w.Cols, cur.Col, cur.Cols)
+		case "j":
+			adjacent, overlap = cur.Row+cur.Rows+1 == w.Row, span(w.Col, w.Cols, cur.Col, cur.Cols)
+		}
+		if adjacent && overlap > bestOverlap {
+			best, bestOverlap = i+1, overlap
+		}
+	}
+	return best
+}
+
+func span(aLo, aLen, bLo, bLen int) int {
+	return max(0, min(aLo+aLen, bLo+bLen)-max(aLo, bLo))
+}
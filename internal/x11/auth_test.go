@@ -0,0 +1,58 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package x11
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseDisplay(t *testing.T) {
+	tests := []struct {
+		in, host, num string
+		screen        int
+	}{
+		{":0", "", "0", 0},
+		{":1.2", "", "1", 2},
+		{"unix:3", "unix", "3", 0},
+		{"remote:10.0", "remote", "10", 0},
+		{"/private/tmp/com.apple.launchd.x/org.xquartz:0", "/private/tmp/com.apple.launchd.x/org.xquartz", "0", 0},
+	}
+	for _, tt := range tests {
+		host, num, screen, err := parseDisplay(tt.in)
+		if err != nil || host != tt.host || num != tt.num || screen != tt.screen {
+			t.Errorf("parseDisplay(%q) = %q, %q, %d, %v", tt.in, host, num, screen, err)
+		}
+	}
+}
+
+func TestReadAuth(t *testing.T) {
+	hostname, _ := os.Hostname()
+	var file []byte
+	entry := func(family uint16, fields ...string) {
+		file = binary.BigEndian.AppendUint16(file, family)
+		for _, f := range fields {
+			file = binary.BigEndian.AppendUint16(file, uint16(len(f)))
+			file = append(file, f...)
+		}
+	}
+	entry(256, hostname, "1", "MIT-MAGIC-COOKIE-1", "wrong-display")
+	entry(256, "elsewhere", "0", "MIT-MAGIC-COOKIE-1", "wrong-host")
+	entry(256, hostname, "0", "XDM-AUTHORIZATION-1", "wrong-scheme")
+	entry(256, hostname, "0", "MIT-MAGIC-COOKIE-1", "cookie")
+	path := filepath.Join(t.TempDir(), "Xauthority")
+	if err := os.WriteFile(path, file, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("XAUTHORITY", path)
+
+	if name, data := readAuth("", "0"); name != "MIT-MAGIC-COOKIE-1" || string(data) != "cookie" {
+		t.Errorf("expected the local cookie for :0, got %q %q", name, data)
+	}
+	if name, _ := readAuth("", "7"); name != "" {
+		t.Errorf("expected no cookie for :7, got %q", name)
+	}
+}
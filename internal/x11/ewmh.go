@@ -0,0 +1,130 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package x11
+
+import (
+	"errors"
+	"strings"
+)
+
+// AllDesktops is the _NET_WM_DESKTOP value of a sticky window.
+const AllDesktops = 0xffffffff
+
+// cardinals decodes a format-32 property.
+func cardinals(format byte, data []byte) []uint32 {
+	if format != 32 {
+		return nil
+	}
+	vs := make([]uint32, len(data)/4)
+	for i := range vs {
+		vs[i] = le.Uint32(data[4*i:])
+	}
+	return vs
+}
+
+// Cardinals reads a list of 32-bit values (CARDINAL, WINDOW or ATOM) from a
+// window property.
+func (c *Conn) Cardinals(win uint32, name string) ([]uint32, error) {
+	_, format, data, err := c.Property(win, name)
+	if err != nil {
+		return nil, err
+	}
+	return cardinals(format, data), nil
+}
+
+// ActiveWindow returns the root's _NET_ACTIVE_WINDOW, or 0 for none.
+func (c *Conn) ActiveWindow() (uint32, error) {
+	vs, err := c.Cardinals(c.Root, "_NET_ACTIVE_WINDOW")
+	if err != nil || len(vs) == 0 {
+		return 0, err
+	}
+	return vs[0], nil
+}
+
+// ClientList returns the managed windows front-most first, from
+// _NET_CLIENT_LIST_STACKING when the window manager keeps it and
+// _NET_CLIENT_LIST otherwise.
+func (c *Conn) ClientList() ([]uint32, error) {
+	wins, err := c.Cardinals(c.Root, "_NET_CLIENT_LIST_STACKING")
+	if err != nil {
+		return nil, err
+	}
+	if len(wins) == 0 {
+		if wins, err = c.Cardinals(c.Root, "_NET_CLIENT_LIST"); err != nil {
+			return nil, err
+		}
+		if len(wins) == 0 {
+			return nil, errors.New("x11: no _NET_CLIENT_LIST; is an EWMH window manager running?")
+		}
+	}
+	// Stacking order is bottom to top.
+	for i, j := 0, len(wins)-1; i < j; i, j = i+1, j-1 {
+		wins[i], wins[j] = wins[j], wins[i]
+	}
+	return wins, nil
+}
+
+// Class returns the instance and class names from WM_CLASS.
+func (c *Conn) Class(win uint32) (instance, class string, err error) {
+	_, _, data, err := c.Property(win, "WM_CLASS")
+	if err != nil {
+		return "", "", err
+	}
+	parts := strings.Split(strings.TrimRight(string(data), "\x00"), "\x00")
+	if len(parts) < 2 {
+		return parts[0], "", nil
+	}
+	return parts[0], parts[1], nil
+}
+
+// Title returns _NET_WM_NAME, falling back to WM_NAME.
+func (c *Conn) Title(win uint32) (string, error) {
+	_, _, data, err := c.Property(win, "_NET_WM_NAME")
+	if err == nil && len(data) == 0 {
+		_, _, data, err = c.Property(win, "WM_NAME")
+	}
+	return string(data), err
+}
+
+// Desktop returns the window's _NET_WM_DESKTOP, or AllDesktops if unset.
+func (c *Conn) Desktop(win uint32) uint32 {
+	return c.first(win, "_NET_WM_DESKTOP")
+}
+
+// CurrentDesktop returns the root's _NET_CURRENT_DESKTOP, or AllDesktops
+// if the window manager has no desktops.
+func (c *Conn) CurrentDesktop() uint32 {
+	return c.first(c.Root, "_NET_CURRENT_DESKTOP")
+}
+
+// first reads the first value of a cardinal property, or AllDesktops.
+func (c *Conn) first(win uint32, name string) uint32 {
+	vs, err := c.Cardinals(win, name)
+	if err != nil || len(vs) == 0 {
+		return AllDesktops
+	}
+	return vs[0]
+}
+
+// Hidden reports whether the window is minimized (_NET_WM_STATE_HIDDEN).
+func (c *Conn) Hidden(win uint32) bool {
+	hidden, err := c.Atom("_NET_WM_STATE_HIDDEN")
+	if err != nil {
+		return false
+	}
+	states, _ := c.Cardinals(win, "_NET_WM_STATE")
+	for _, s := range states {
+		if s == hidden {
+			return true
+		}
+	}
+	return false
+}
+
+// Activate asks the window manager to raise and focus win, on behalf of a
+// pager (source indication 2) so focus-stealing prevention lets it through.
+func (c *Conn) Activate(win uint32) error {
+	cur, _ := c.ActiveWindow()
+	return c.SendClientMessage(win, "_NET_ACTIVE_WINDOW", 2, 0, cur)
+}
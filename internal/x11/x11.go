@@ -0,0 +1,507 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+// Package x11 is a tiny X11 client: just enough of the core protocol (and
+// Xinerama) to list EWMH client windows, read their geometry and class, and
+// ask the window manager to activate one. It speaks the wire protocol
+// directly so ttyhop needs neither cgo nor Xlib.
+package x11
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timeout bounds the connection setup and each request (or wait for an
+// event), so a wedged X server can't hang a hop.
+const timeout = 2 * time.Second
+
+// Core request opcodes.
+const (
+	opCreateWindow           = 1
+	opChangeWindowAttributes = 2
+	opMapWindow              = 8
+	opGetGeometry            = 14
+	opInternAtom             = 16
+	opChangeProperty         = 18
+	opGetProperty            = 20
+	opSendEvent              = 25
+	opTranslateCoordinates   = 40
+	opGetInputFocus          = 43
+	opQueryExtension         = 98
+)
+
+// Event masks used with SendEvent and SelectInput.
+const (
+	SubstructureNotifyMask   = 1 << 19
+	SubstructureRedirectMask = 1 << 20
+	PropertyChangeMask       = 1 << 22
+)
+
+// ClientMessage is the event code of a client message.
+const ClientMessage = 33
+
+var le = binary.LittleEndian
+
+// Error is an X protocol error reply.
+type Error struct {
+	Code  byte
+	Major byte
+	Value uint32
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("x11: error %d from request %d (value %#x)", e.Code, e.Major, e.Value)
+}
+
+// Event is a raw 32-byte event.
+type Event [32]byte
+
+// Code is the event type, without the SendEvent bit.
+func (e Event) Code() byte { return e[0] & 0x7f }
+
+// Conn is a connection to an X server. It is not safe for concurrent use.
+type Conn struct {
+	c      net.Conn
+	r      *bufio.Reader
+	seq    uint16
+	Root   uint32
+	Width  int
+	Height int
+	idBase uint32
+	idMask uint32
+	nextID uint32
+	atoms  map[string]uint32
+	events []Event
+}
+
+// Dial connects to display, or to $DISPLAY when display is empty. Both
+// ":N[.S]" / "host:N[.S]" and the "/path/to/socket:N" form are accepted.
+func Dial(display string) (*Conn, error) {
+	if display == "" {
+		display = os.Getenv("DISPLAY")
+	}
+	if display == "" {
+		return nil, errors.New("x11: DISPLAY is not set")
+	}
+	host, num, screen, err := parseDisplay(display)
+	if err != nil {
+		return nil, err
+	}
+	var nc net.Conn
+	switch {
+	case strings.HasPrefix(host, "/"):
+		nc, err = net.DialTimeout("unix", host, timeout)
+	case host == "" || host == "unix":
+		nc, err = net.DialTimeout("unix", "/tmp/.X11-unix/X"+num, timeout)
+	default:
+		n, _ := strconv.Atoi(num)
+		nc, err = net.DialTimeout("tcp", net.JoinHostPort(host, strconv.Itoa(6000+n)), timeout)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("x11: %w", err)
+	}
+	name, data := readAuth(host, num)
+	c, err := NewConn(nc, name, data, screen)
+	if err != nil {
+		_ = nc.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// parseDisplay splits "host:N.S" into its parts.
+func parseDisplay(d string) (host, num string, screen int, err error) {
+	i := strings.LastIndex(d, ":")
+	if i < 0 {
+		return "", "", 0, fmt.Errorf("x11: bad display %q", d)
+	}
+	host, num = d[:i], d[i+1:]
+	if n, s, ok := strings.Cut(num, "."); ok {
+		num = n
+		if screen, err = strconv.Atoi(s); err != nil {
+			return "", "", 0, fmt.Errorf("x11: bad display %q", d)
+		}
+	}
+	if _, err := strconv.Atoi(num); err != nil {
+		return "", "", 0, fmt.Errorf("x11: bad display %q", d)
+	}
+	return host, num, screen, nil
+}
+
+// readAuth finds the MIT-MAGIC-COOKIE-1 for a display in $XAUTHORITY (or
+// ~/.Xauthority). No cookie means connecting without authorization.
+func readAuth(host, num string) (name string, data []byte) {
+	path := os.Getenv("XAUTHORITY")
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", nil
+		}
+		path = filepath.Join(home, ".Xauthority")
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return "", nil
+	}
+	defer func() { _ = f.Close() }()
+
+	local := host == "" || host == "unix" || strings.HasPrefix(host, "/")
+	hostname, _ := os.Hostname()
+	r := bufio.NewReader(f)
+	field := func() ([]byte, error) {
+		var n [2]byte
+		if _, err := io.ReadFull(r, n[:]); err != nil {
+			return nil, err
+		}
+		b := make([]byte, binary.BigEndian.Uint16(n[:]))
+		_, err := io.ReadFull(r, b)
+		return b, err
+	}
+	for {
+		var fam [2]byte
+		if _, err := io.ReadFull(r, fam[:]); err != nil {
+			return "", nil
+		}
+		addr, err1 := field()
+		dnum, err2 := field()
+		aname, err3 := field()
+		adata, err4 := field()
+		if err1 != nil || err2 != nil || err3 != nil || err4 != nil {
+			return "", nil
+		}
+		family := binary.BigEndian.Uint16(fam[:])
+		hostOK := family == 0xffff || // FamilyWild
+			(family == 256 && local && string(addr) == hostname) || // FamilyLocal
+			(!local && string(addr) == host)
+		if hostOK && (len(dnum) == 0 || string(dnum) == num) && string(aname) == "MIT-MAGIC-COOKIE-1" {
+			return string(aname), adata
+		}
+	}
+}
+
+func pad(n int) int { return (4 - n%4) % 4 }
+
+// NewConn performs the connection setup over nc and selects screen.
+func NewConn(nc net.Conn, authName string, authData []byte, screen int) (*Conn, error) {
+	req := make([]byte, 12, 12+len(authName)+pad(len(authName))+len(authData)+pad(len(authData)))
+	req[0] = 'l'
+	le.PutUint16(req[2:], 11)
+	le.PutUint16(req[6:], uint16(len(authName)))
+	le.PutUint16(req[8:], uint16(len(authData)))
+	req = append(req, authName...)
+	req = append(req, make([]byte, pad(len(authName)))...)
+	req = append(req, authData...)
+	req = append(req, make([]byte, pad(len(authData)))...)
+	_ = nc.SetDeadline(time.Now().Add(timeout))
+	if _, err := nc.Write(req); err != nil {
+		return nil, fmt.Errorf("x11: %w", err)
+	}
+
+	c := &Conn{c: nc, r: bufio.NewReader(nc), atoms: map[string]uint32{}}
+	var head [8]byte
+	if _, err := io.ReadFull(c.r, head[:]); err != nil {
+		return nil, fmt.Errorf("x11: setup: %w", err)
+	}
+	body := make([]byte, int(le.Uint16(head[6:]))*4)
+	if _, err := io.ReadFull(c.r, body); err != nil {
+		return nil, fmt.Errorf("x11: setup: %w", err)
+	}
+	if head[0] != 1 {
+		reason := body
+		if n := int(head[1]); head[0] == 0 && n <= len(body) {
+			reason = body[:n]
+		}
+		return nil, fmt.Errorf("x11: connection refused: %s", strings.TrimSpace(string(reason)))
+	}
+	if len(body) < 32 {
+		return nil, errors.New("x11: short setup reply")
+	}
+	c.idBase, c.idMask = le.Uint32(body[4:]), le.Uint32(body[8:])
+	vendorLen := int(le.Uint16(body[16:]))
+	nScreens, nFormats := int(body[20]), int(body[21])
+	off := 32 + vendorLen + pad(vendorLen) + 8*nFormats
+	if screen >= nScreens {
+		return nil, fmt.Errorf("x11: no screen %d", screen)
+	}
+	for i := 0; ; i++ {
+		if off+40 > len(body) {
+			return nil, errors.New("x11: short setup reply")
+		}
+		if i == screen {
+			c.Root = le.Uint32(body[off:])
+			c.Width, c.Height = int(le.Uint16(body[off+20:])), int(le.Uint16(body[off+22:]))
+			break
+		}
+		nDepths := int(body[off+39])
+		off += 40
+		for d := 0; d < nDepths; d++ {
+			if off+8 > len(body) {
+				return nil, errors.New("x11: short setup reply")
+			}
+			off += 8 + 24*int(le.Uint16(body[off+2:]))
+		}
+	}
+	return c, nil
+}
+
+// Close closes the connection.
+func (c *Conn) Close() error {
+	return c.c.Close()
+}
+
+// request builds a request with opcode op, the byte after it, and body
+// (padded to a multiple of four).
+func request(op, data byte, body ...[]byte) []byte {
+	n := 4
+	for _, b := range body {
+		n += len(b)
+	}
+	req := make([]byte, 4, n+pad(n))
+	req[0], req[1] = op, data
+	for _, b := range body {
+		req = append(req, b...)
+	}
+	req = append(req, make([]byte, pad(n))...)
+	le.PutUint16(req[2:], uint16(len(req)/4))
+	return req
+}
+
+func u32(vs ...uint32) []byte {
+	b := make([]byte, 4*len(vs))
+	for i, v := range vs {
+		le.PutUint32(b[4*i:], v)
+	}
+	return b
+}
+
+func u16(vs ...uint16) []byte {
+	b := make([]byte, 2*len(vs))
+	for i, v := range vs {
+		le.PutUint16(b[2*i:], v)
+	}
+	return b
+}
+
+// send writes a request that has no reply. It restarts the timeout, which
+// roundTrip's wait for the reply falls under too.
+func (c *Conn) send(req []byte) error {
+	c.seq++
+	_ = c.c.SetDeadline(time.Now().Add(timeout))
+	if _, err := c.c.Write(req); err != nil {
+		return fmt.Errorf("x11: %w", err)
+	}
+	return nil
+}
+
+// roundTrip writes a request and returns its reply. Events that arrive
+// first are queued for NextEvent; errors for earlier requests are dropped.
+func (c *Conn) roundTrip(req []byte) ([]byte, error) {
+	if err := c.send(req); err != nil {
+		return nil, err
+	}
+	want := c.seq
+	for {
+		var msg [32]byte
+		if _, err := io.ReadFull(c.r, msg[:]); err != nil {
+			return nil, fmt.Errorf("x11: %w", err)
+		}
+		seq := le.Uint16(msg[2:])
+		switch msg[0] {
+		case 0:
+			if seq == want {
+				return nil, &Error{Code: msg[1], Major: msg[10], Value: le.Uint32(msg[4:])}
+			}
+		case 1:
+			extra := make([]byte, int(le.Uint32(msg[4:]))*4)
+			if _, err := io.ReadFull(c.r, extra); err != nil {
+				return nil, fmt.Errorf("x11: %w", err)
+			}
+			if seq == want {
+				return append(msg[:], extra...), nil
+			}
+		default:
+			c.events = append(c.events, Event(msg))
+		}
+	}
+}
+
+// Sync waits until the server has handled every request sent so far,
+// reporting an error if the most recent one failed.
+func (c *Conn) Sync() error {
+	_, err := c.roundTrip(request(opGetInputFocus, 0))
+	return err
+}
+
+// NextEvent returns the next event, blocking until one arrives or the
+// timeout passes.
+func (c *Conn) NextEvent() (Event, error) {
+	if len(c.events) > 0 {
+		ev := c.events[0]
+		c.events = c.events[1:]
+		return ev, nil
+	}
+	_ = c.c.SetDeadline(time.Now().Add(timeout))
+	for {
+		var msg [32]byte
+		if _, err := io.ReadFull(c.r, msg[:]); err != nil {
+			return Event{}, fmt.Errorf("x11: %w", err)
+		}
+		switch msg[0] {
+		case 0:
+			continue
+		case 1:
+			if _, err := io.CopyN(io.Discard, c.r, int64(le.Uint32(msg[4:]))*4); err != nil {
+				return Event{}, fmt.Errorf("x11: %w", err)
+			}
+		default:
+			return Event(msg), nil
+		}
+	}
+}
+
+// Atom interns name, caching the result.
+func (c *Conn) Atom(name string) (uint32, error) {
+	if a, ok := c.atoms[name]; ok {
+		return a, nil
+	}
+	rep, err := c.roundTrip(request(opInternAtom, 0, u16(uint16(len(name)), 0), []byte(name)))
+	if err != nil {
+		return 0, err
+	}
+	a := le.Uint32(rep[8:])
+	c.atoms[name] = a
+	return a, nil
+}
+
+// Property reads all of a window property. A missing property returns a
+// zero type and no data.
+func (c *Conn) Property(win uint32, name string) (typ uint32, format byte, data []byte, err error) {
+	prop, err := c.Atom(name)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	rep, err := c.roundTrip(request(opGetProperty, 0, u32(win, prop, 0, 0, 1<<20)))
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	format, typ = rep[1], le.Uint32(rep[8:])
+	n := int(le.Uint32(rep[16:])) * int(format) / 8
+	if 32+n > len(rep) {
+		return 0, 0, nil, errors.New("x11: short property reply")
+	}
+	return typ, format, rep[32 : 32+n], nil
+}
+
+// ChangeProperty replaces a window property with data of the given type
+// and format (8, 16 or 32).
+func (c *Conn) ChangeProperty(win uint32, name, typ string, format byte, data []byte) error {
+	prop, err := c.Atom(name)
+	if err != nil {
+		return err
+	}
+	t, err := c.Atom(typ)
+	if err != nil {
+		return err
+	}
+	head := append(u32(win, prop, t), format, 0, 0, 0)
+	return c.send(request(opChangeProperty, 0, head, u32(uint32(len(data)/int(format/8))), data))
+}
+
+// Geometry returns a window's position relative to the root window and its
+// size.
+func (c *Conn) Geometry(win uint32) (x, y, w, h int, err error) {
+	rep, err := c.roundTrip(request(opGetGeometry, 0, u32(win)))
+	if err != nil {
+		return 0, 0, 0, 0, err
+	}
+	w, h = int(le.Uint16(rep[16:])), int(le.Uint16(rep[18:]))
+	rep, err = c.roundTrip(request(opTranslateCoordinates, 0, u32(win, c.Root), u16(0, 0)))
+	if err != nil {
+		return 0, 0, 0, 0, err
+	}
+	return int(int16(le.Uint16(rep[12:]))), int(int16(le.Uint16(rep[14:]))), w, h, nil
+}
+
+// SendClientMessage sends a 32-bit client message about win to the root
+// window, the way EWMH requests are made of the window manager.
+func (c *Conn) SendClientMessage(win uint32, typ string, data ...uint32) error {
+	t, err := c.Atom(typ)
+	if err != nil {
+		return err
+	}
+	var ev Event
+	ev[0], ev[1] = ClientMessage, 32
+	le.PutUint32(ev[4:], win)
+	le.PutUint32(ev[8:], t)
+	for i, d := range data {
+		if i < 5 {
+			le.PutUint32(ev[12+4*i:], d)
+		}
+	}
+	err = c.send(request(opSendEvent, 0, u32(c.Root, SubstructureNotifyMask|SubstructureRedirectMask), ev[:]))
+	if err != nil {
+		return err
+	}
+	return c.Sync()
+}
+
+// Monitors lists the Xinerama screens as x, y, width, height. Without
+// Xinerama the whole root window is the only monitor.
+func (c *Conn) Monitors() ([][4]int, error) {
+	name := "XINERAMA"
+	rep, err := c.roundTrip(request(opQueryExtension, 0, u16(uint16(len(name)), 0), []byte(name)))
+	if err != nil {
+		return nil, err
+	}
+	whole := [][4]int{{0, 0, c.Width, c.Height}}
+	if rep[8] == 0 {
+		return whole, nil
+	}
+	rep, err = c.roundTrip(request(rep[9], 5)) // XineramaQueryScreens
+	if err != nil {
+		return nil, err
+	}
+	n := int(le.Uint32(rep[8:]))
+	if n == 0 || 32+8*n > len(rep) {
+		return whole, nil
+	}
+	mons := make([][4]int, n)
+	for i := range mons {
+		b := rep[32+8*i:]
+		mons[i] = [4]int{int(int16(le.Uint16(b))), int(int16(le.Uint16(b[2:]))), int(le.Uint16(b[4:])), int(le.Uint16(b[6:]))}
+	}
+	return mons, nil
+}
+
+// CreateWindow creates and maps a plain child of the root window. It
+// exists for tests that need real windows on a server such as Xvfb.
+func (c *Conn) CreateWindow(x, y, w, h int) (uint32, error) {
+	c.nextID++
+	id := c.idBase | (c.nextID & c.idMask)
+	err := c.send(request(opCreateWindow, 0, u32(id, c.Root), u16(uint16(int16(x)), uint16(int16(y)), uint16(w), uint16(h), 0, 1), u32(0, 0)))
+	if err != nil {
+		return 0, err
+	}
+	if err := c.send(request(opMapWindow, 0, u32(id))); err != nil {
+		return 0, err
+	}
+	return id, c.Sync()
+}
+
+// SelectInput sets the events this connection receives for win.
+func (c *Conn) SelectInput(win, mask uint32) error {
+	if err := c.send(request(opChangeWindowAttributes, 0, u32(win, 1<<11, mask))); err != nil { // CWEventMask
+		return err
+	}
+	return c.Sync()
+}
@@ -0,0 +1,148 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package x11_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/leejonesio/ttyhop/internal/x11"
+	"github.com/leejonesio/ttyhop/internal/x11/x11test"
+)
+
+// layout serves two Alacritty windows and a browser, stacked
+// browser < 0x2 < 0x1 (front-most last, as EWMH lists them).
+func layout(t *testing.T) (*x11test.Server, *x11.Conn) {
+	t.Helper()
+	srv := x11test.NewServer(t)
+	srv.AddWindow(0x1, 0, 0, 800, 600)
+	srv.AddWindow(0x2, 800, 0, 800, 600)
+	srv.AddWindow(0x3, 100, 700, 400, 300)
+	srv.SetClass(0x1, "Alacritty", "Alacritty")
+	srv.SetClass(0x2, "Alacritty", "Alacritty")
+	srv.SetClass(0x3, "Navigator", "firefox")
+	srv.SetProperty(0x1, "_NET_WM_NAME", "UTF8_STRING", 8, []byte("zsh — ~"))
+	srv.SetCardinals(x11test.Root, "_NET_CLIENT_LIST_STACKING", "WINDOW", 0x3, 0x2, 0x1)
+	srv.SetCardinals(x11test.Root, "_NET_ACTIVE_WINDOW", "WINDOW", 0x1)
+
+	c, err := x11.Dial(srv.Display())
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	return srv, c
+}
+
+func TestEWMH(t *testing.T) {
+	srv, c := layout(t)
+
+	if c.Root != x11test.Root || c.Width != 3840 || c.Height != 2160 {
+		t.Errorf("unexpected screen: root=%#x %dx%d", c.Root, c.Width, c.Height)
+	}
+	if w, err := c.ActiveWindow(); err != nil || w != 0x1 {
+		t.Errorf("expected active window 0x1, got %#x (%v)", w, err)
+	}
+	wins, err := c.ClientList()
+	if err != nil || len(wins) != 3 || wins[0] != 0x1 || wins[2] != 0x3 {
+		t.Errorf("expected clients front-most first, got %#x (%v)", wins, err)
+	}
+	if inst, class, err := c.Class(0x3); err != nil || inst != "Navigator" || class != "firefox" {
+		t.Errorf("expected Navigator/firefox, got %q/%q (%v)", inst, class, err)
+	}
+	if title, _ := c.Title(0x1); title != "zsh — ~" {
+		t.Errorf("unexpected title %q", title)
+	}
+	if x, y, w, h, err := c.Geometry(0x2); err != nil || x != 800 || y != 0 || w != 800 || h != 600 {
+		t.Errorf("unexpected geometry %d,%d %dx%d (%v)", x, y, w, h, err)
+	}
+	if d := c.Desktop(0x2); d != x11.AllDesktops {
+		t.Errorf("expected no desktop, got %d", d)
+	}
+
+	srv.SetAtoms(0x2, "_NET_WM_STATE", "_NET_WM_STATE_MAXIMIZED_VERT", "_NET_WM_STATE_HIDDEN")
+	if !c.Hidden(0x2) || c.Hidden(0x1) {
+		t.Error("expected only 0x2 to be hidden")
+	}
+
+	if err := c.Activate(0x2); err != nil {
+		t.Fatalf("activate: %v", err)
+	}
+	sent := srv.Sent()
+	if len(sent) != 1 || sent[0].Window != 0x2 || sent[0].Type != "_NET_ACTIVE_WINDOW" || sent[0].Data[0] != 2 || sent[0].Data[2] != 0x1 {
+		t.Errorf("unexpected activation request %+v", sent)
+	}
+}
+
+func TestClientListFallback(t *testing.T) {
+	srv := x11test.NewServer(t)
+	c, err := x11.Dial(srv.Display())
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer func() { _ = c.Close() }()
+
+	if _, err := c.ClientList(); err == nil {
+		t.Error("expected an error without a window manager")
+	}
+	srv.SetCardinals(x11test.Root, "_NET_CLIENT_LIST", "WINDOW", 0x5, 0x6)
+	if wins, err := c.ClientList(); err != nil || len(wins) != 2 || wins[0] != 0x6 {
+		t.Errorf("expected _NET_CLIENT_LIST reversed, got %#x (%v)", wins, err)
+	}
+}
+
+func TestGeometryBadWindow(t *testing.T) {
+	_, c := layout(t)
+	_, _, _, _, err := c.Geometry(0x99)
+	var xerr *x11.Error
+	if !errors.As(err, &xerr) || xerr.Major != 14 {
+		t.Errorf("expected a GetGeometry error, got %v", err)
+	}
+	// The connection is still usable afterwards.
+	if _, err := c.ActiveWindow(); err != nil {
+		t.Errorf("expected the connection to survive an error: %v", err)
+	}
+}
+
+func TestMonitors(t *testing.T) {
+	srv, c := layout(t)
+	mons, err := c.Monitors()
+	if err != nil || len(mons) != 1 || mons[0] != [4]int{0, 0, 3840, 2160} {
+		t.Errorf("expected the root window without Xinerama, got %v (%v)", mons, err)
+	}
+
+	srv.Monitors = [][4]int{{0, 0, 1920, 1080}, {1920, -200, 1080, 1920}}
+	mons, err = c.Monitors()
+	if err != nil || len(mons) != 2 || mons[1] != [4]int{1920, -200, 1080, 1920} {
+		t.Errorf("expected the Xinerama screens, got %v (%v)", mons, err)
+	}
+}
+
+func TestCreateWindow(t *testing.T) {
+	_, c := layout(t)
+	id, err := c.CreateWindow(10, 20, 300, 200)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := c.ChangeProperty(id, "WM_CLASS", "STRING", 8, []byte("foot\x00foot\x00")); err != nil {
+		t.Fatalf("change property: %v", err)
+	}
+	if _, class, err := c.Class(id); err != nil || class != "foot" {
+		t.Errorf("expected class foot, got %q (%v)", class, err)
+	}
+	if x, y, w, h, _ := c.Geometry(id); x != 10 || y != 20 || w != 300 || h != 200 {
+		t.Errorf("unexpected geometry %d,%d %dx%d", x, y, w, h)
+	}
+}
+
+func TestDialErrors(t *testing.T) {
+	for _, d := range []string{"nonsense", ":x", ":0.y"} {
+		if _, err := x11.Dial(d); err == nil {
+			t.Errorf("expected an error for DISPLAY=%q", d)
+		}
+	}
+	t.Setenv("DISPLAY", "")
+	if _, err := x11.Dial(""); err == nil {
+		t.Error("expected an error without DISPLAY")
+	}
+}
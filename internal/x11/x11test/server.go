@@ -0,0 +1,342 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+// Package x11test runs a fake X server for tests. It answers the handful of
+// requests package x11 makes from an in-memory window table, so EWMH
+// layouts can be replayed without Xvfb or a window manager.
+package x11test
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/leejonesio/ttyhop/internal/x11"
+)
+
+// Root is the fake root window.
+const Root = 0x100
+
+// xineramaOpcode is the major opcode the fake assigns to XINERAMA.
+const xineramaOpcode = 130
+
+var le = binary.LittleEndian
+
+type property struct {
+	typ    uint32
+	format byte
+	data   []byte
+}
+
+type window struct {
+	x, y, w, h int
+	props      map[uint32]property
+}
+
+// Server is a fake X server listening on a unix socket.
+type Server struct {
+	// Monitors, when set, are reported through Xinerama.
+	Monitors [][4]int
+	// Width and Height size the root window.
+	Width, Height int
+
+	path    string
+	ln      net.Listener
+	mu      sync.Mutex
+	atoms   map[string]uint32
+	windows map[uint32]*window
+	sent    []x11.Event
+}
+
+// NewServer starts a fake server that stops when the test ends.
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+	s := &Server{
+		Width:   3840,
+		Height:  2160,
+		path:    filepath.Join(t.TempDir(), "X0"),
+		atoms:   map[string]uint32{},
+		windows: map[uint32]*window{Root: {props: map[uint32]property{}}},
+	}
+	ln, err := net.Listen("unix", s.path)
+	if err != nil {
+		t.Skipf("cannot listen on a unix socket: %v", err)
+	}
+	s.ln = ln
+	t.Cleanup(func() { _ = ln.Close() })
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(c)
+		}
+	}()
+	return s
+}
+
+// Display is the DISPLAY value that reaches this server.
+func (s *Server) Display() string {
+	return s.path + ":0"
+}
+
+func (s *Server) atom(name string) uint32 {
+	a, ok := s.atoms[name]
+	if !ok {
+		a = uint32(len(s.atoms) + 1)
+		s.atoms[name] = a
+	}
+	return a
+}
+
+// AddWindow adds a top-level window at the given root coordinates.
+func (s *Server) AddWindow(id uint32, x, y, w, h int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.windows[id] = &window{x: x, y: y, w: w, h: h, props: map[uint32]property{}}
+}
+
+// SetProperty sets a raw window property.
+func (s *Server) SetProperty(win uint32, name, typ string, format byte, data []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.windows[win].props[s.atom(name)] = property{typ: s.atom(typ), format: format, data: data}
+}
+
+// SetCardinals sets a format-32 property such as _NET_CLIENT_LIST.
+func (s *Server) SetCardinals(win uint32, name, typ string, vs ...uint32) {
+	data := make([]byte, 4*len(vs))
+	for i, v := range vs {
+		le.PutUint32(data[4*i:], v)
+	}
+	s.SetProperty(win, name, typ, 32, data)
+}
+
+// SetAtoms sets a property listing atoms by name, e.g. _NET_WM_STATE.
+func (s *Server) SetAtoms(win uint32, name string, atoms ...string) {
+	s.mu.Lock()
+	vs := make([]uint32, len(atoms))
+	for i, a := range atoms {
+		vs[i] = s.atom(a)
+	}
+	s.mu.Unlock()
+	s.SetCardinals(win, name, "ATOM", vs...)
+}
+
+// SetClass sets WM_CLASS.
+func (s *Server) SetClass(win uint32, instance, class string) {
+	s.SetProperty(win, "WM_CLASS", "STRING", 8, []byte(instance+"\x00"+class+"\x00"))
+}
+
+// Sent returns the events clients have sent with SendEvent, decoded as
+// (window, message type name, data) for client messages.
+func (s *Server) Sent() []ClientMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var msgs []ClientMessage
+	for _, ev := range s.sent {
+		if ev.Code() != x11.ClientMessage {
+			continue
+		}
+		m := ClientMessage{Window: le.Uint32(ev[4:])}
+		t := le.Uint32(ev[8:])
+		for name, a := range s.atoms {
+			if a == t {
+				m.Type = name
+			}
+		}
+		for i := range m.Data {
+			m.Data[i] = le.Uint32(ev[12+4*i:])
+		}
+		msgs = append(msgs, m)
+	}
+	return msgs
+}
+
+// ClientMessage is a decoded client message sent to the root window.
+type ClientMessage struct {
+	Window uint32
+	Type   string
+	Data   [5]uint32
+}
+
+func (s *Server) serve(c net.Conn) {
+	defer func() { _ = c.Close() }()
+	var head [12]byte
+	if _, err := io.ReadFull(c, head[:]); err != nil || head[0] != 'l' {
+		return
+	}
+	n := int(le.Uint16(head[6:])) + int(le.Uint16(head[8:]))
+	if _, err := io.CopyN(io.Discard, c, int64(n+pad(le.Uint16(head[6:]))+pad(le.Uint16(head[8:])))); err != nil {
+		return
+	}
+	if _, err := c.Write(s.setup()); err != nil {
+		return
+	}
+	var seq uint16
+	for {
+		var h [4]byte
+		if _, err := io.ReadFull(c, h[:]); err != nil {
+			return
+		}
+		body := make([]byte, int(le.Uint16(h[2:]))*4-4)
+		if _, err := io.ReadFull(c, body); err != nil {
+			return
+		}
+		seq++
+		out := s.handle(h[0], h[1], body)
+		if out != nil {
+			le.PutUint16(out[2:], seq)
+			if _, err := c.Write(out); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func pad(n uint16) int { return (4 - int(n)%4) % 4 }
+
+func (s *Server) setup() []byte {
+	vendor := []byte("ttyhop")
+	body := make([]byte, 32)
+	le.PutUint32(body[4:], 0x200000) // resource-id-base
+	le.PutUint32(body[8:], 0x1fffff) // resource-id-mask
+	le.PutUint16(body[16:], uint16(len(vendor)))
+	le.PutUint16(body[18:], 0xffff)
+	body[20], body[21] = 1, 1 // one screen, one format
+	body = append(body, vendor...)
+	body = append(body, make([]byte, pad(uint16(len(vendor))))...)
+	body = append(body, 24, 32, 32, 0, 0, 0, 0, 0) // format
+	scr := make([]byte, 40)
+	le.PutUint32(scr, Root)
+	le.PutUint16(scr[20:], uint16(s.Width))
+	le.PutUint16(scr[22:], uint16(s.Height))
+	scr[38], scr[39] = 24, 1
+	body = append(body, scr...)
+	body = append(body, 24, 0, 0, 0, 0, 0, 0, 0) // depth 24, no visuals
+	head := make([]byte, 8)
+	head[0] = 1
+	le.PutUint16(head[2:], 11)
+	le.PutUint16(head[6:], uint16(len(body)/4))
+	return append(head, body...)
+}
+
+// reply builds a reply whose bytes from offset 8 on are body.
+func reply(data byte, body []byte) []byte {
+	if len(body) < 24 {
+		body = append(body, make([]byte, 24-len(body))...)
+	}
+	body = append(body, make([]byte, pad(uint16(len(body))))...)
+	out := make([]byte, 8, 8+len(body))
+	out[0], out[1] = 1, data
+	le.PutUint32(out[4:], uint32(len(body)-24)/4)
+	return append(out, body...)
+}
+
+func xerror(code, major byte, value uint32) []byte {
+	out := make([]byte, 32)
+	out[1], out[10] = code, major
+	le.PutUint32(out[4:], value)
+	return out
+}
+
+// handle answers one request; nil means no reply.
+func (s *Server) handle(op, data byte, b []byte) []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	win := func(id uint32) *window { return s.windows[id] }
+	switch op {
+	case 1: // CreateWindow
+		id := le.Uint32(b)
+		s.windows[id] = &window{
+			x: int(int16(le.Uint16(b[8:]))), y: int(int16(le.Uint16(b[10:]))),
+			w: int(le.Uint16(b[12:])), h: int(le.Uint16(b[14:])),
+			props: map[uint32]property{},
+		}
+		return nil
+	case 2, 8: // ChangeWindowAttributes, MapWindow
+		return nil
+	case 14: // GetGeometry
+		w := win(le.Uint32(b))
+		if w == nil {
+			return xerror(9, op, le.Uint32(b)) // BadDrawable
+		}
+		r := make([]byte, 24)
+		le.PutUint32(r, Root)
+		le.PutUint16(r[4:], uint16(int16(w.x)))
+		le.PutUint16(r[6:], uint16(int16(w.y)))
+		le.PutUint16(r[8:], uint16(w.w))
+		le.PutUint16(r[10:], uint16(w.h))
+		return reply(24, r)
+	case 16: // InternAtom
+		n := le.Uint16(b)
+		r := make([]byte, 24)
+		le.PutUint32(r, s.atom(string(b[4:4+n])))
+		return reply(0, r)
+	case 18: // ChangeProperty
+		w := win(le.Uint32(b))
+		if w == nil {
+			return xerror(3, op, le.Uint32(b)) // BadWindow
+		}
+		format := b[12]
+		n := int(le.Uint32(b[16:])) * int(format) / 8
+		w.props[le.Uint32(b[4:])] = property{typ: le.Uint32(b[8:]), format: format, data: append([]byte(nil), b[20:20+n]...)}
+		return nil
+	case 20: // GetProperty
+		w := win(le.Uint32(b))
+		if w == nil {
+			return xerror(3, op, le.Uint32(b))
+		}
+		p, ok := w.props[le.Uint32(b[4:])]
+		r := make([]byte, 24)
+		if !ok {
+			return reply(0, r)
+		}
+		le.PutUint32(r, p.typ)
+		le.PutUint32(r[8:], uint32(len(p.data)*8/int(p.format)))
+		return reply(p.format, append(r, p.data...))
+	case 25: // SendEvent
+		var ev x11.Event
+		copy(ev[:], b[8:40])
+		s.sent = append(s.sent, ev)
+		return nil
+	case 40: // TranslateCoordinates
+		w := win(le.Uint32(b))
+		if w == nil {
+			return xerror(3, op, le.Uint32(b))
+		}
+		r := make([]byte, 24)
+		le.PutUint16(r[4:], uint16(int16(w.x+int(int16(le.Uint16(b[8:]))))))
+		le.PutUint16(r[6:], uint16(int16(w.y+int(int16(le.Uint16(b[10:]))))))
+		return reply(1, r)
+	case 43: // GetInputFocus
+		return reply(0, nil)
+	case 98: // QueryExtension
+		n := le.Uint16(b)
+		r := make([]byte, 24)
+		if string(b[4:4+n]) == "XINERAMA" && s.Monitors != nil {
+			r[0], r[1] = 1, xineramaOpcode
+		}
+		return reply(0, r)
+	case xineramaOpcode:
+		if data != 5 { // QueryScreens
+			return xerror(1, op, 0)
+		}
+		r := make([]byte, 24)
+		le.PutUint32(r, uint32(len(s.Monitors)))
+		for _, m := range s.Monitors {
+			e := make([]byte, 8)
+			le.PutUint16(e, uint16(int16(m[0])))
+			le.PutUint16(e[2:], uint16(int16(m[1])))
+			le.PutUint16(e[4:], uint16(m[2]))
+			le.PutUint16(e[6:], uint16(m[3]))
+			r = append(r, e...)
+		}
+		return reply(0, r)
+	}
+	return xerror(1, op, 0) // BadRequest
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package x11_test
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/leejonesio/ttyhop/internal/x11"
+)
+
+// startXvfb runs a throwaway Xvfb and returns its DISPLAY.
+func startXvfb(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("Xvfb"); err != nil {
+		t.Skip("Xvfb not installed")
+	}
+	display := fmt.Sprintf(":%d", 90+os.Getpid()%100)
+	cmd := exec.Command("Xvfb", display, "-nolisten", "tcp", "-screen", "0", "1920x1080x24")
+	if err := cmd.Start(); err != nil {
+		t.Skipf("cannot start Xvfb: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	})
+	for i := 0; i < 50; i++ {
+		if c, err := x11.Dial(display); err == nil {
+			_ = c.Close()
+			return display
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	t.Skip("Xvfb did not come up")
+	return ""
+}
+
+// TestXvfb plays the window manager on a real X server: it publishes the
+// EWMH root properties and catches the activation request.
+func TestXvfb(t *testing.T) {
+	display := startXvfb(t)
+	wm, err := x11.Dial(display)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = wm.Close() }()
+	c, err := x11.Dial(display)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = c.Close() }()
+
+	if c.Width != 1920 || c.Height != 1080 {
+		t.Errorf("unexpected screen %dx%d", c.Width, c.Height)
+	}
+	a, err := wm.CreateWindow(0, 0, 800, 600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := wm.CreateWindow(800, 0, 800, 600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := []byte{}
+	for _, w := range []uint32{b, a} {
+		list = append(list, byte(w), byte(w>>8), byte(w>>16), byte(w>>24))
+	}
+	must := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	must(wm.ChangeProperty(wm.Root, "_NET_CLIENT_LIST_STACKING", "WINDOW", 32, list))
+	must(wm.ChangeProperty(wm.Root, "_NET_ACTIVE_WINDOW", "WINDOW", 32, list[4:]))
+	must(wm.ChangeProperty(b, "WM_CLASS", "STRING", 8, []byte("Alacritty\x00Alacritty\x00")))
+	must(wm.SelectInput(wm.Root, x11.SubstructureRedirectMask))
+	must(wm.Sync())
+
+	wins, err := c.ClientList()
+	if err != nil || len(wins) != 2 || wins[0] != a {
+		t.Errorf("expected %#x front-most, got %#x (%v)", a, wins, err)
+	}
+	if _, class, _ := c.Class(b); class != "Alacritty" {
+		t.Errorf("expected class Alacritty, got %q", class)
+	}
+	if x, y, w, h, err := c.Geometry(b); err != nil || x != 800 || y != 0 || w != 800 || h != 600 {
+		t.Errorf("unexpected geometry %d,%d %dx%d (%v)", x, y, w, h, err)
+	}
+	if mons, err := c.Monitors(); err != nil || len(mons) == 0 {
+		t.Errorf("expected at least one monitor, got %v (%v)", mons, err)
+	}
+
+	must(c.Activate(b))
+	ev, err := wm.NextEvent()
+	if err != nil || ev.Code() != x11.ClientMessage {
+		t.Fatalf("expected a client message, got %v (%v)", ev.Code(), err)
+	}
+}
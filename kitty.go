@@ -0,0 +1,191 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/leejonesio/ttyhop/internal/neighbor"
+)
+
+// ---------- kitty remote control ----------
+//
+// Inside kitty, splits are kitty windows rather than tmux panes. They are
+// reached through kitty's remote control ("kitty @"), which needs
+// allow_remote_control in kitty.conf; from inside tmux it also needs
+// listen_on, so that kitty exports $KITTY_LISTEN_ON.
+
+// kittyOSWindow, kittyTab and kittyWindow are the parts of "kitty @ ls"
+// that ttyhop reads.
+type kittyOSWindow struct {
+	ID        int        `json:"id"`
+	IsFocused bool       `json:"is_focused"`
+	Tabs      []kittyTab `json:"tabs"`
+}
+
+type kittyTab struct {
+	ID        int           `json:"id"`
+	IsFocused bool          `json:"is_focused"`
+	IsActive  bool          `json:"is_active"`
+	Windows   []kittyWindow `json:"windows"`
+}
+
+type kittyWindow struct {
+	ID        int  `json:"id"`
+	IsFocused bool `json:"is_focused"`
+	IsActive  bool `json:"is_active"`
+}
+
+// inKitty reports whether kitty's remote control can be reached.
+func inKitty() bool {
+	return os.Getenv("KITTY_WINDOW_ID") != "" || os.Getenv("KITTY_LISTEN_ON") != ""
+}
+
+type runKittyCmdFunc func(args ...string) (string, error)
+
+// runKittyCmd runs one "kitty @" command; tests swap it for a fake kitty.
+var runKittyCmd runKittyCmdFunc = execKittyCmd
+
+func execKittyCmd(args ...string) (string, error) {
+	cmd := exec.Command("kitty", append([]string{"@"}, args...)...)
+	var out, stderr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", errors.New(msg)
+		}
+		return "", err
+	}
+	return strings.TrimSpace(out.String()), nil
+}
+
+// kittyFocused returns the focused OS window and its active tab and window.
+// ok is false when no kitty OS window has focus.
+func kittyFocused() (kittyOSWindow, kittyTab, kittyWindow, bool, error) {
+	out, err := runKittyCmd("ls")
+	if err != nil {
+		return kittyOSWindow{}, kittyTab{}, kittyWindow{}, false, err
+	}
+	var osws []kittyOSWindow
+	if err := json.Unmarshal([]byte(out), &osws); err != nil {
+		return kittyOSWindow{}, kittyTab{}, kittyWindow{}, false, err
+	}
+	for _, osw := range osws {
+		if !osw.IsFocused {
+			continue
+		}
+		for _, tab := range osw.Tabs {
+			if !tab.IsFocused && !tab.IsActive {
+				continue
+			}
+			for _, win := range tab.Windows {
+				if win.IsFocused || win.IsActive {
+					return osw, tab, win, true, nil
+				}
+			}
+		}
+	}
+	return kittyOSWindow{}, kittyTab{}, kittyWindow{}, false, nil
+}
+
+// kittyNeighbor is the neighbor: match kitty uses for direction dir.
+func kittyNeighbor(dir Direction) string {
+	return "neighbor:" + map[Direction]string{DirLeft: "left", DirRight: "right", DirUp: "top", DirDown: "bottom"}[dir]
+}
+
+// kittyTryMove moves to the kitty window next to the active one in dir;
+// return true if moved. kitty answers a neighbor: match with no window
+// there with an error, which means the active window is at the edge.
+func kittyTryMove(dir Direction) bool {
+	if !inKitty() {
+		return false
+	}
+	_, tab, old, ok, err := kittyFocused()
+	if err != nil || !ok {
+		dbg("kitty: no focused window (%v)", err)
+		return false
+	}
+	if len(tab.Windows) < 2 {
+		return false
+	}
+	if _, err := runKittyCmd("focus-window", "--match", kittyNeighbor(dir)); err != nil {
+		dbg("kitty: at %s edge (%v)", dir, err)
+		return false
+	}
+	_, _, win, ok, _ := kittyFocused()
+	if !ok || win.ID == old.ID {
+		return false
+	}
+	dbg("kitty: window move %s (%d -> %d)", dir, old.ID, win.ID)
+	return true
+}
+
+// kittyFocusedOSWindow returns the id of the focused kitty OS window, or 0
+// outside kitty. Hops record it so landing can tell when focus has moved.
+func kittyFocusedOSWindow() int {
+	if !inKitty() {
+		return 0
+	}
+	osw, _, _, ok, _ := kittyFocused()
+	if !ok {
+		return 0
+	}
+	return osw.ID
+}
+
+// kittySelectEdgeWindow selects the edge kitty window in the OS window a
+// hop just focused: the LEFTMOST when moving right, the TOPMOST when moving
+// down, and so on. from is the OS window the hop started in; it waits up to
+// waitMs for another kitty OS window to take focus and gives up as soon as
+// none has it.
+func kittySelectEdgeWindow(dir Direction, waitMs int, from int) {
+	if !inKitty() {
+		return
+	}
+	waitMs = edgeWaitMs(waitMs)
+	for i := 0; i < waitMs/pollIntervalMs; i++ {
+		time.Sleep(pollIntervalMs * time.Millisecond)
+
+		osw, tab, win, ok, err := kittyFocused()
+		if err != nil || !ok {
+			dbg("kitty: destination is not a kitty window")
+			return
+		}
+		if osw.ID == from {
+			continue
+		}
+		// Each step moves one window back; a tab can't need more steps than
+		// it has windows.
+		for range tab.Windows {
+			if _, err := runKittyCmd("focus-window", "--match", kittyNeighbor(dir.Opposite())); err != nil {
+				break
+			}
+		}
+		_, _, edge, _, _ := kittyFocused()
+		dbg("kitty: landed on %s edge window %d (from %d)", dir.Opposite(), edge.ID, win.ID)
+		return
+	}
+}
+
+// kittyNavigator moves between kitty splits. Landing waits for focus to
+// leave the OS window the hop started in.
+type kittyNavigator struct {
+	waitMs int
+	from   int
+}
+
+func (n *kittyNavigator) Try(dir Direction) bool { return kittyTryMove(dir) }
+
+func (n *kittyNavigator) recordOrigin(Direction, neighbor.Rect, neighbor.Candidate) {
+	n.from = kittyFocusedOSWindow()
+}
+
+func (n *kittyNavigator) LandAtEdge(dir Direction) { kittySelectEdgeWindow(dir, n.waitMs, n.from) }
@@ -0,0 +1,134 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+// fakeKitty stands in for kitty's remote control with three OS windows:
+// 1|2, 3|4|5 and 6. "ls" reports the focused window, and "focus-window
+// --match M" follows moves, failing like kitty does when nothing matches.
+// Window 0 means no kitty window has focus.
+type fakeKitty struct {
+	focused int
+	calls   [][]string
+}
+
+// fakeKittyMoves maps a window and a match to the window it focuses.
+var fakeKittyMoves = map[int]map[string]int{
+	1: {"neighbor:right": 2}, 2: {"neighbor:left": 1},
+	3: {"neighbor:right": 4}, 4: {"neighbor:right": 5, "neighbor:left": 3}, 5: {"neighbor:left": 4},
+}
+
+// newFakeKitty puts a fakeKitty with window focused in place of kitty.
+func newFakeKitty(t *testing.T, focused int) *fakeKitty {
+	t.Helper()
+	f := &fakeKitty{focused: focused}
+	t.Setenv("KITTY_WINDOW_ID", strconv.Itoa(focused))
+	t.Setenv("KITTY_LISTEN_ON", "")
+	original := runKittyCmd
+	t.Cleanup(func() { runKittyCmd = original })
+	runKittyCmd = f.run
+	return f
+}
+
+func (f *fakeKitty) run(args ...string) (string, error) {
+	f.calls = append(f.calls, args)
+	switch args[0] {
+	case "ls":
+		var osws []kittyOSWindow
+		for i, ids := range [][]int{{1, 2}, {3, 4, 5}, {6}} {
+			osw := kittyOSWindow{ID: i + 1, Tabs: []kittyTab{{ID: i + 1, IsActive: true}}}
+			for j, w := range ids {
+				// The first window of each tab is its active one unless
+				// focus is elsewhere in it.
+				active := w == f.focused || (j == 0 && !slices.Contains(ids, f.focused))
+				osw.Tabs[0].Windows = append(osw.Tabs[0].Windows, kittyWindow{ID: w, IsActive: active, IsFocused: w == f.focused})
+				if w == f.focused {
+					osw.IsFocused, osw.Tabs[0].IsFocused = true, true
+				}
+			}
+			osws = append(osws, osw)
+		}
+		b, err := json.Marshal(osws)
+		return string(b), err
+	case "focus-window":
+		next, ok := fakeKittyMoves[f.focused][args[2]]
+		if !ok {
+			return "", errors.New("No matching windows for expression: " + args[2])
+		}
+		f.focused = next
+		return "", nil
+	}
+	return "", fmt.Errorf("fake kitty: unexpected %q", args)
+}
+
+func TestKittyTryMove(t *testing.T) {
+	tests := []struct {
+		name    string
+		focused int
+		dir     Direction
+		moved   bool
+		want    int
+	}{
+		{"Right", 1, DirRight, true, 2},
+		{"AtRightEdge", 2, DirRight, false, 2},
+		{"Left", 5, DirLeft, true, 4},
+		{"NoNeighborAbove", 4, DirUp, false, 4},
+		{"SingleWindowTab", 6, DirLeft, false, 6},
+		{"NotFocused", 0, DirRight, false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeKitty(t, tt.focused)
+			if moved := kittyTryMove(tt.dir); moved != tt.moved {
+				t.Errorf("kittyTryMove(%s) = %v, want %v", tt.dir, moved, tt.moved)
+			}
+			if f.focused != tt.want {
+				t.Errorf("focused window %d, want %d", f.focused, tt.want)
+			}
+		})
+	}
+
+	t.Run("OutsideKitty", func(t *testing.T) {
+		f := newFakeKitty(t, 1)
+		t.Setenv("KITTY_WINDOW_ID", "")
+		if kittyTryMove(DirRight) {
+			t.Error("expected no move outside kitty")
+		}
+		if len(f.calls) != 0 {
+			t.Errorf("expected kitty not to be run outside kitty, got %q", f.calls)
+		}
+	})
+}
+
+func TestKittySelectEdgeWindow(t *testing.T) {
+	tests := []struct {
+		name    string
+		focused int
+		dir     Direction
+		from    int
+		want    int
+	}{
+		{"MovingRightLandsLeftmost", 5, DirRight, 1, 3},
+		{"MovingLeftLandsRightmost", 3, DirLeft, 1, 5},
+		{"FocusNeverMoved", 2, DirRight, 1, 2},
+		{"DestinationNotKitty", 0, DirRight, 1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeKitty(t, tt.focused)
+			kittySelectEdgeWindow(tt.dir, 2*pollIntervalMs, tt.from)
+			if f.focused != tt.want {
+				t.Errorf("focused window %d, want %d", f.focused, tt.want)
+			}
+		})
+	}
+}
@@ -8,9 +8,10 @@ import (
 	"fmt"
 	"os"
 	"runtime/debug"
-	"strconv"
 	"strings"
 	"time"
+
+	"github.com/leejonesio/ttyhop/internal/neighbor"
 )
 
 var (
@@ -20,16 +21,28 @@ var (
 )
 
 func usage() {
-	fmt.Fprintln(os.Stderr, `usage: ttyhop [--check] [-v|--log] [-q|--quiet] [--no-edge] [--edge-steps N] [--wait-ms N] [--version] {left|l|right|r|shell zsh}
-  left/l, right/r      hop between terminal windows
+	fmt.Fprintln(os.Stderr, `usage: ttyhop [--check] [-v|--log] [-q|--quiet] [--no-edge] [--edge-steps N] [--wait-ms N] [--strategy NAME] [--wrap] [--wrap-panes] [--zoom POLICY] [-L|--tmux-socket NAME] [--backend NAME] [--layout FILE] [--terminals LIST] [--chain LIST] [--version] {left|l|right|r|up|k|down|j|display next|prev|N|shell zsh}
+  left/l, right/r      hop between terminal windows horizontally
+  up/k, down/j         hop between terminal windows vertically
+  display next|prev|N  hop to the terminal window on another monitor (numbered left to right)
   shell zsh            print zsh eval script for keybindings
-  --check              print trust + front app info (no focus change)
+  --check              print the backend, trust, front app info and candidate scores (no focus change)
   -v, --log            enable logging (or set TTYHOP_LOG=1)
   -q, --quiet          disable logging
-  --no-edge            don't send C-h/C-l after hop
-  --edge-steps N       how many C-h/l to send (default 5)
+  --no-edge            don't land on the edge pane (tmux, nvim, ...) facing where the hop came from
+  --edge-steps N       ignored; landing no longer sends keys (kept so old configs still parse)
   --wait-ms N          ms to wait for window focus (default 200, env: TTYHOP_EDGE_WAIT_MS)
-  --version            print version and exit`)
+  --strategy NAME      neighbor scoring: center, edge, overlap, cone (default center, env: TTYHOP_STRATEGY)
+  --wrap               with no neighbor, wrap to the far window on the other side (env: TTYHOP_WRAP=1)
+  --wrap-panes         with no window hop, wrap to the far pane of the tmux window (env: TTYHOP_WRAP_PANES=1)
+  --zoom POLICY        zoomed tmux pane: edge (hop windows), unzoom (then move) or keep (move, stay zoomed) (default edge, env: TTYHOP_ZOOM)
+  -L, --tmux-socket S  extra tmux servers to land in, comma-separated socket names or paths (env: TTYHOP_TMUX_SOCKET)
+  --backend NAME       window backend, or auto to detect it (default auto, env: TTYHOP_BACKEND); --check lists them
+  --layout FILE        JSON layout for --backend sim, which prints what a hop would focus
+  --terminals LIST     apps to hop between: bundle:ID, name:NAME, class:WM_CLASS, app_id:ID, title:REGEXP or a bare id, comma-separated (default Alacritty, env: TTYHOP_TERMINALS)
+  --chain LIST         what to try, in order: nvim, emacs, tmux, zellij, screen, kitty, wezterm, windows, comma-separated (default all of them, env: TTYHOP_CHAIN)
+  --version            print version and exit
+Long options can also be set in ~/.config/ttyhop/config (or $TTYHOP_CONFIG), one "key = value" per line.`)
 	os.Exit(64)
 }
 
@@ -85,10 +98,11 @@ func printVersion() {
 	fmt.Printf("ttyhop version %s%s\n", base, detailStr)
 }
 
-// run is the main application logic, separated for testability.
+// run is the main application logic, separated for testability. A nil
+// hopper is replaced by the backend --backend picks.
 func run(hopper Hopper, args []string) int {
-	var flVerbose, flQuiet, flCheck, flNoEdge, flVersion bool
-	var flEdgeSteps string
+	var flVerbose, flQuiet, flCheck, flNoEdge, flVersion, flWrap, flWrapPanes bool
+	var flEdgeSteps, flStrategy, flZoom, flTmuxSocket, flBackend, flLayout, flTerminals, flChain string
 	var flWaitMs int
 
 	fs := flag.NewFlagSet("ttyhop", flag.ContinueOnError)
@@ -97,11 +111,36 @@ func run(hopper Hopper, args []string) int {
 	fs.BoolVar(&flQuiet, "q", false, "quiet")
 	fs.BoolVar(&flQuiet, "quiet", false, "quiet")
 	fs.BoolVar(&flCheck, "check", false, "check only")
-	fs.BoolVar(&flNoEdge, "no-edge", false, "disable tmux edge nudge")
-	fs.StringVar(&flEdgeSteps, "edge-steps", "5", "number of C-h/l presses after hop")
+	fs.BoolVar(&flNoEdge, "no-edge", false, "don't land on the edge pane after a hop")
+	fs.StringVar(&flEdgeSteps, "edge-steps", "5", "ignored, kept for old configs")
 	fs.IntVar(&flWaitMs, "wait-ms", 0, "ms to wait for window focus")
+	fs.StringVar(&flStrategy, "strategy", neighbor.Default.Name, "neighbor scoring strategy")
+	fs.BoolVar(&flWrap, "wrap", false, "wrap to the far window when there is no neighbor")
+	fs.BoolVar(&flWrapPanes, "wrap-panes", false, "wrap within the tmux window when there is no window hop")
+	fs.StringVar(&flZoom, "zoom", string(zoomEdge), "zoomed tmux pane policy: edge, unzoom or keep")
+	fs.StringVar(&flTmuxSocket, "L", "", "extra tmux socket names or paths, comma-separated")
+	fs.StringVar(&flTmuxSocket, "tmux-socket", "", "extra tmux socket names or paths, comma-separated")
+	fs.StringVar(&flBackend, "backend", "auto", "window backend, or auto")
+	fs.StringVar(&flLayout, "layout", "", "layout file for the sim backend")
+	fs.StringVar(&flTerminals, "terminals", defaultTerminals, "terminal apps to hop between, comma-separated")
+	fs.StringVar(&flChain, "chain", defaultChain, "navigators and windows to try, in order, comma-separated")
 	fs.BoolVar(&flVersion, "version", false, "print version and exit")
 
+	// Config file first, then environment, then the command line wins.
+	cfgArgs, err := loadConfigArgs(configPath())
+	if err == nil {
+		err = fs.Parse(cfgArgs)
+	}
+	if err == nil && fs.NArg() > 0 {
+		err = fmt.Errorf("config: unexpected argument %q", fs.Arg(0))
+	}
+	if err == nil {
+		err = applyEnvFlags(fs)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ttyhop:", err)
+		return 64
+	}
 	if err := fs.Parse(args); err != nil {
 		usage()
 		return 64
@@ -112,43 +151,108 @@ func run(hopper Hopper, args []string) int {
 		return 0
 	}
 
+	// A nil hopper means the real one, from the registry.
+	var chosen backend
+	var reason string
+	if hopper == nil {
+		if chosen, reason, err = pickBackend(flBackend); err != nil {
+			fmt.Fprintln(os.Stderr, "ttyhop:", err)
+			return 64
+		}
+		hopper = chosen.open()
+	}
+	if c, ok := hopper.(cleaner); ok {
+		defer c.cleanup()
+	}
+
 	envLog := os.Getenv("TTYHOP_LOG") == "1"
 	debug := !flQuiet && (flVerbose || envLog)
 	hopper.SetDebug(debug)
 	hopper.SetWaitMs(flWaitMs)
 
+	strategy, ok := neighbor.LookupStrategy(flStrategy)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "ttyhop: unknown strategy %q (have: %s)\n", flStrategy, strategyNames())
+		return 64
+	}
+	hopper.SetStrategy(strategy)
+	hopper.SetWrap(flWrap, flWrapPanes)
+
+	zoom, ok := parseZoomPolicy(flZoom)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "ttyhop: unknown zoom policy %q (have: edge, unzoom, keep)\n", flZoom)
+		return 64
+	}
+	hopper.SetZoomPolicy(zoom)
+
+	terminals, err := parseTerminals(flTerminals)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ttyhop:", err)
+		return 64
+	}
+	hopper.SetTerminals(terminals)
+
+	chain, err := parseChain(flChain)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ttyhop:", err)
+		return 64
+	}
+	hopper.SetChain(chain)
+
+	var sockets []string
+	for _, s := range strings.Split(flTmuxSocket, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			sockets = append(sockets, s)
+		}
+	}
+	hopper.SetTmuxSockets(sockets)
+	hopper.SetLayout(flLayout)
+
+	posArgs := fs.Args()
+
 	if flCheck {
+		if chosen.name != "" {
+			fmt.Printf("backend=%s (%s)\n", chosen.name, reason)
+		}
+		fmt.Printf("backends=%s\n", backendNames())
 		trusted := hopper.IsTrusted()
 		bid, name, source := hopper.GetFrontAppInfo()
 		fmt.Printf("trusted=%v front_bid=%q front_name=%q (%s)\n", trusted, bid, name, source)
+		dirs := []Direction{DirLeft, DirRight, DirUp, DirDown}
+		if len(posArgs) > 0 {
+			dir, ok := parseDirection(posArgs[0])
+			if !ok {
+				usage()
+				return 64
+			}
+			dirs = []Direction{dir}
+		}
+		printCheck(hopper, strategy, dirs)
 		return 0
 	}
 
-	posArgs := fs.Args()
 	if len(posArgs) == 0 {
 		usage()
 		return 64
 	}
 
-	steps := 5
-	if n, err := strconv.Atoi(flEdgeSteps); err == nil && n > 0 && n < 50 {
-		steps = n
-	}
 	doEdge := !flNoEdge
 
-	switch posArgs[0] {
-	case "left", "l":
+	if dir, ok := parseDirection(posArgs[0]); ok {
 		if len(posArgs) != 1 {
 			usage()
 			return 64
 		}
-		return hopper.FocusNeighbor(false, debug, doEdge, steps)
-	case "right", "r":
-		if len(posArgs) != 1 {
+		return hopper.FocusNeighbor(dir, debug, doEdge)
+	}
+
+	switch posArgs[0] {
+	case "display":
+		if len(posArgs) != 2 || !parseDisplayTarget(posArgs[1]) {
 			usage()
 			return 64
 		}
-		return hopper.FocusNeighbor(true, debug, doEdge, steps)
+		return hopper.FocusDisplay(posArgs[1], debug)
 	case "shell":
 		if len(posArgs) != 2 {
 			usage()
@@ -168,9 +272,41 @@ func run(hopper Hopper, args []string) int {
 	}
 }
 
+// printCheck reports how each candidate window scores under strategy.
+func printCheck(hopper Hopper, strategy neighbor.Strategy, dirs []Direction) {
+	fmt.Printf("strategy=%s\n", strategy.Name)
+	for _, dir := range dirs {
+		best, scored, rc := hopper.Inspect(dir)
+		if rc != 0 {
+			fmt.Printf("%s: no candidates (exit code %d)\n", dir, rc)
+			continue
+		}
+		if len(scored) == 0 {
+			fmt.Printf("%s: no candidates\n", dir)
+			continue
+		}
+		for i, sc := range scored {
+			mark := ""
+			if i == best {
+				mark = " <- target"
+			}
+			fmt.Printf("%s: %s%s\n", dir, formatScored(sc), mark)
+		}
+	}
+}
+
+func strategyNames() string {
+	var names []string
+	for _, st := range neighbor.Strategies() {
+		names = append(names, st.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
 func main() {
-	hopper := newHopper()
-	os.Exit(run(hopper, os.Args[1:]))
+	rc := run(nil, os.Args[1:])
+	closeTmuxControl()
+	os.Exit(rc)
 }
 
 const zshScript = `
@@ -216,6 +352,46 @@ ttyhop-r() {
 zle -N ttyhop-r
 bindkey '^l' ttyhop-r
 
+
+# --- ttyhop j (^j) ---
+#
+# 1. Discover what '^j' is currently bound to.
+original_j_widget=$(bindkey '^j' | awk '{print $2}')
+
+# 2. Define our new function.
+ttyhop-j() {
+  ttyhop j
+  # 3. If ttyhop fails, execute the original command.
+  #    If nothing was bound, default to accept-line.
+  if [[ $? -ne 0 ]]; then
+    zle "${original_j_widget:-accept-line}"
+  fi
+}
+
+# 4. Register and bind our new function.
+zle -N ttyhop-j
+bindkey '^j' ttyhop-j
+
+
+# --- ttyhop k (^k) ---
+#
+# 1. Discover what '^k' is currently bound to.
+original_k_widget=$(bindkey '^k' | awk '{print $2}')
+
+# 2. Define our new function.
+ttyhop-k() {
+  ttyhop k
+  # 3. If ttyhop fails, execute the original command.
+  #    If nothing was bound, default to kill-line.
+  if [[ $? -ne 0 ]]; then
+    zle "${original_k_widget:-kill-line}"
+  fi
+}
+
+# 4. Register and bind our new function.
+zle -N ttyhop-k
+bindkey '^k' ttyhop-k
+
 # Clean up the temporary variables
-unset original_h_widget original_l_widget
+unset original_h_widget original_l_widget original_j_widget original_k_widget
 `
@@ -6,10 +6,116 @@ package main
 import (
 	"errors"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/leejonesio/ttyhop/internal/neighbor"
 )
 
+// fakeHopper records what run asked of it.
+type fakeHopper struct {
+	hopOptions
+	dir     Direction
+	display string
+	called  bool
+	rc      int
+}
+
+func (f *fakeHopper) FocusNeighbor(dir Direction, debug bool, doEdge bool) int {
+	f.dir, f.called = dir, true
+	return f.rc
+}
+
+func (f *fakeHopper) FocusDisplay(target string, debug bool) int {
+	f.display, f.called = target, true
+	return f.rc
+}
+
+func (f *fakeHopper) Inspect(dir Direction) (int, []neighbor.Scored, int) { return -1, nil, 0 }
+func (f *fakeHopper) SetDebug(debug bool)                                 {}
+func (f *fakeHopper) IsTrusted() bool                                     { return true }
+func (f *fakeHopper) GetFrontAppInfo() (string, string, string)           { return "", "", "fake" }
+
+// withConfig points run at a temporary config file holding contents.
+func withConfig(t *testing.T, contents string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	t.Setenv("TTYHOP_CONFIG", path)
+}
+
+func TestRunWrap(t *testing.T) {
+	withConfig(t, "wrap-panes\n")
+	t.Setenv("TTYHOP_WRAP", "")
+	t.Setenv("TTYHOP_WRAP_PANES", "")
+
+	h := &fakeHopper{}
+	run(h, []string{"r"})
+	if h.wrapWindows || !h.wrapPanes {
+		t.Errorf("expected only pane wrapping from config, got windows=%v panes=%v", h.wrapWindows, h.wrapPanes)
+	}
+
+	t.Setenv("TTYHOP_WRAP", "1")
+	run(h, []string{"r"})
+	if !h.wrapWindows {
+		t.Error("expected TTYHOP_WRAP=1 to enable window wrapping")
+	}
+
+	run(h, []string{"--wrap=false", "r"})
+	if h.wrapWindows {
+		t.Error("expected --wrap=false to override the environment")
+	}
+}
+
+func TestRunStrategy(t *testing.T) {
+	t.Run("Default", func(t *testing.T) {
+		withConfig(t, "")
+		t.Setenv("TTYHOP_STRATEGY", "")
+		h := &fakeHopper{}
+		if rc := run(h, []string{"r"}); rc != 0 {
+			t.Fatalf("expected rc 0, got %d", rc)
+		}
+		if h.strategy.Name != "center" || h.dir != DirRight {
+			t.Errorf("expected center strategy moving right, got %q moving %v", h.strategy.Name, h.dir)
+		}
+	})
+
+	t.Run("PrecedenceFlagOverEnvOverConfig", func(t *testing.T) {
+		withConfig(t, "# tuning\nstrategy = cone\n")
+		t.Setenv("TTYHOP_STRATEGY", "")
+		h := &fakeHopper{}
+		run(h, []string{"l"})
+		if h.strategy.Name != "cone" {
+			t.Errorf("expected config strategy 'cone', got %q", h.strategy.Name)
+		}
+
+		t.Setenv("TTYHOP_STRATEGY", "overlap")
+		run(h, []string{"l"})
+		if h.strategy.Name != "overlap" {
+			t.Errorf("expected env strategy 'overlap', got %q", h.strategy.Name)
+		}
+
+		run(h, []string{"--strategy", "edge", "l"})
+		if h.strategy.Name != "edge" {
+			t.Errorf("expected flag strategy 'edge', got %q", h.strategy.Name)
+		}
+	})
+
+	t.Run("UnknownStrategy", func(t *testing.T) {
+		withConfig(t, "")
+		h := &fakeHopper{}
+		if rc := run(h, []string{"--strategy", "psychic", "r"}); rc != 64 {
+			t.Errorf("expected rc 64 for unknown strategy, got %d", rc)
+		}
+		if h.called {
+			t.Error("expected no hop with an unknown strategy")
+		}
+	})
+}
+
 func TestZshScriptOutput(t *testing.T) {
 	if zshScript == "" {
 		t.Fatal("zshScript constant is empty")
@@ -18,11 +124,17 @@ func TestZshScriptOutput(t *testing.T) {
 	expectedSubstrings := []string{
 		"ttyhop-l()",
 		"ttyhop-r()",
+		"ttyhop-j()",
+		"ttyhop-k()",
 		"bindkey '^h' ttyhop-l",
 		"bindkey '^l' ttyhop-r",
+		"bindkey '^j' ttyhop-j",
+		"bindkey '^k' ttyhop-k",
 		"zle",
 		"original_h_widget",
 		"original_l_widget",
+		"original_j_widget",
+		"original_k_widget",
 	}
 
 	for _, sub := range expectedSubstrings {
@@ -32,6 +144,30 @@ func TestZshScriptOutput(t *testing.T) {
 	}
 }
 
+func TestParseDirection(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Direction
+		ok   bool
+	}{
+		{"left", DirLeft, true},
+		{"l", DirLeft, true},
+		{"right", DirRight, true},
+		{"r", DirRight, true},
+		{"up", DirUp, true},
+		{"k", DirUp, true},
+		{"down", DirDown, true},
+		{"j", DirDown, true},
+		{"sideways", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseDirection(tt.in)
+		if ok != tt.ok || (ok && got != tt.want) {
+			t.Errorf("parseDirection(%q) = %v, %v; want %v, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
 func TestTmuxTryPaneMove(t *testing.T) {
 	// --- Subtest: NoTmux ---
 	t.Run("NoTmux", func(t *testing.T) {
@@ -45,7 +181,7 @@ func TestTmuxTryPaneMove(t *testing.T) {
 			}
 		}()
 
-		if tmuxTryPaneMove(true) {
+		if tmuxTryPaneMove(DirRight, zoomEdge) {
 			t.Error("Expected tmuxTryPaneMove to return false when not in a tmux session, but it returned true")
 		}
 	})
@@ -70,7 +206,7 @@ func TestTmuxTryPaneMove(t *testing.T) {
 			return "%0", nil
 		}
 
-		if tmuxTryPaneMove(true) {
+		if tmuxTryPaneMove(DirRight, zoomEdge) {
 			t.Error("Expected tmuxTryPaneMove to return false when at the right edge, but it returned true")
 		}
 	})
@@ -106,11 +242,52 @@ func TestTmuxTryPaneMove(t *testing.T) {
 			return "", nil
 		}
 
-		if !tmuxTryPaneMove(true) {
+		if !tmuxTryPaneMove(DirRight, zoomEdge) {
 			t.Error("Expected tmuxTryPaneMove to return true on successful pane move, but it returned false")
 		}
 	})
 
+	// --- Subtest: VerticalMove ---
+	t.Run("VerticalMove", func(t *testing.T) {
+		if err := os.Setenv("TMUX", "/tmp/tmux-1000/default,21,0"); err != nil {
+			t.Fatalf("failed to set TMUX: %v", err)
+		}
+		defer func() {
+			if err := os.Unsetenv("TMUX"); err != nil {
+				t.Fatalf("failed to unset TMUX: %v", err)
+			}
+		}()
+
+		originalRunTmux := runTmuxCmd
+		defer func() { runTmuxCmd = originalRunTmux }()
+
+		var paneIDCallCount int
+		var selectArgs string
+		runTmuxCmd = func(args ...string) (string, error) {
+			cmd := strings.Join(args, " ")
+			switch {
+			case strings.Contains(cmd, "pane_at_bottom"):
+				return "0", nil // Not at edge
+			case strings.Contains(cmd, "display -p #{pane_id}"):
+				paneIDCallCount++
+				if paneIDCallCount == 1 {
+					return "%0", nil
+				}
+				return "%1", nil
+			case strings.HasPrefix(cmd, "select-pane"):
+				selectArgs = cmd
+			}
+			return "", nil
+		}
+
+		if !tmuxTryPaneMove(DirDown, zoomEdge) {
+			t.Error("Expected tmuxTryPaneMove to return true on successful downward move, but it returned false")
+		}
+		if selectArgs != "select-pane -D" {
+			t.Errorf("expected 'select-pane -D', got %q", selectArgs)
+		}
+	})
+
 	// --- Subtest: CommandError ---
 	t.Run("CommandError", func(t *testing.T) {
 		if err := os.Setenv("TMUX", "/tmp/tmux-1000/default,21,0"); err != nil {
@@ -128,13 +305,15 @@ func TestTmuxTryPaneMove(t *testing.T) {
 			return "", errors.New("tmux command failed")
 		}
 
-		if tmuxTryPaneMove(true) {
+		if tmuxTryPaneMove(DirRight, zoomEdge) {
 			t.Error("Expected tmuxTryPaneMove to return false when a tmux command fails, but it returned true")
 		}
 	})
 }
 
-func TestPickActiveClient(t *testing.T) {
+func TestTmuxFocusedClientOneServer(t *testing.T) {
+	t.Setenv("TMUX_TMPDIR", t.TempDir())
+	t.Setenv("TMUX", "")
 	originalRunTmux := runTmuxCmd
 	defer func() { runTmuxCmd = originalRunTmux }()
 
@@ -142,7 +321,7 @@ func TestPickActiveClient(t *testing.T) {
 		runTmuxCmd = func(args ...string) (string, error) {
 			return "/dev/ttys001 1 1627840934", nil
 		}
-		tty, err := pickActiveClient()
+		_, tty, err := tmuxFocusedClient(nil)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -155,7 +334,7 @@ func TestPickActiveClient(t *testing.T) {
 		runTmuxCmd = func(args ...string) (string, error) {
 			return "/dev/ttys000 0 1627840930\n/dev/ttys001 1 1627840934\n/dev/ttys002 0 1627840932", nil
 		}
-		tty, err := pickActiveClient()
+		_, tty, err := tmuxFocusedClient(nil)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -168,7 +347,7 @@ func TestPickActiveClient(t *testing.T) {
 		runTmuxCmd = func(args ...string) (string, error) {
 			return "/dev/ttys000 0 1627840930\n/dev/ttys001 0 1627840934\n/dev/ttys002 0 1627840932", nil
 		}
-		tty, err := pickActiveClient()
+		_, tty, err := tmuxFocusedClient(nil)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -181,7 +360,7 @@ func TestPickActiveClient(t *testing.T) {
 		runTmuxCmd = func(args ...string) (string, error) {
 			return "", nil
 		}
-		tty, err := pickActiveClient()
+		_, tty, err := tmuxFocusedClient(nil)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -194,7 +373,7 @@ func TestPickActiveClient(t *testing.T) {
 		runTmuxCmd = func(args ...string) (string, error) {
 			return "", errors.New("tmux command failed")
 		}
-		_, err := pickActiveClient()
+		_, _, err := tmuxFocusedClient(nil)
 		if err == nil {
 			t.Fatal("expected an error, but got nil")
 		}
@@ -229,7 +408,7 @@ func TestTmuxSelectEdgePane(t *testing.T) {
 	t.Run("SelectLeftmostPaneWhenMovingEast", func(t *testing.T) {
 		var selectedPane string
 		runTmuxCmd = mockTmuxSequence(&selectedPane)
-		tmuxSelectEdgePane(true, 50) // east=true
+		tmuxSelectEdgePane(DirRight, nil, hopOptions{waitMs: 50, zoom: zoomEdge})
 		if selectedPane != "%1" {
 			t.Errorf("expected leftmost pane '%%1' to be selected, but got %q", selectedPane)
 		}
@@ -238,12 +417,63 @@ func TestTmuxSelectEdgePane(t *testing.T) {
 	t.Run("SelectRightmostPaneWhenMovingWest", func(t *testing.T) {
 		var selectedPane string
 		runTmuxCmd = mockTmuxSequence(&selectedPane)
-		tmuxSelectEdgePane(false, 50) // east=false
+		tmuxSelectEdgePane(DirLeft, nil, hopOptions{waitMs: 50, zoom: zoomEdge})
 		if selectedPane != "%3" {
 			t.Errorf("expected rightmost pane '%%3' to be selected, but got %q", selectedPane)
 		}
 	})
 
+	t.Run("SelectTopmostPaneWhenMovingSouth", func(t *testing.T) {
+		var selectedPane, format string
+		runTmuxCmd = func(args ...string) (string, error) {
+			if strings.HasPrefix(strings.Join(args, " "), "list-panes") {
+				format = args[len(args)-1]
+			}
+			return mockTmuxSequence(&selectedPane)(args...)
+		}
+		tmuxSelectEdgePane(DirDown, nil, hopOptions{waitMs: 50, zoom: zoomEdge})
+		if !strings.Contains(format, "pane_at_top") {
+			t.Errorf("expected list-panes to query pane_at_top, got format %q", format)
+		}
+		if selectedPane != "%1" {
+			t.Errorf("expected topmost pane '%%1' to be selected, but got %q", selectedPane)
+		}
+	})
+
+	t.Run("RowAlignedLanding", func(t *testing.T) {
+		var selectedPane string
+		runTmuxCmd = func(args ...string) (string, error) {
+			if strings.HasPrefix(strings.Join(args, " "), "list-panes") {
+				// Three panes stacked in the left column, one tall pane on the right.
+				return "%1 1 0 0 10 32\n%2 1 0 11 10 32\n%3 1 0 22 10 32\n%4 0 1 0 32 32", nil
+			}
+			return mockTmuxSequence(&selectedPane)(args...)
+		}
+		// Hopping from the bottom third of the screen.
+		hint := &edgeHint{lo: 700, hi: 960, dest: neighbor.Rect{X: 1000, Y: 0, W: 1000, H: 960}}
+		tmuxSelectEdgePane(DirRight, hint, hopOptions{waitMs: 50, zoom: zoomEdge})
+		if selectedPane != "%3" {
+			t.Errorf("expected bottom-left pane '%%3' to be selected, but got %q", selectedPane)
+		}
+	})
+
+	t.Run("OffsetDestinationLandsClosest", func(t *testing.T) {
+		var selectedPane string
+		runTmuxCmd = func(args ...string) (string, error) {
+			if strings.HasPrefix(strings.Join(args, " "), "list-panes") {
+				return "%1 1 0 0 10 32\n%2 1 0 11 10 32\n%3 1 0 22 10 32\n%4 0 1 0 32 32", nil
+			}
+			return mockTmuxSequence(&selectedPane)(args...)
+		}
+		// The destination window sits wholly below the origin pane, as when
+		// a hop wraps or crosses displays, so no pane overlaps it.
+		hint := &edgeHint{lo: 0, hi: 300, dest: neighbor.Rect{X: 1000, Y: 1200, W: 1000, H: 960}}
+		tmuxSelectEdgePane(DirRight, hint, hopOptions{waitMs: 50, zoom: zoomEdge})
+		if selectedPane != "%1" {
+			t.Errorf("expected the closest edge pane '%%1' to be selected, but got %q", selectedPane)
+		}
+	})
+
 	t.Run("NoActionIfListPanesFails", func(t *testing.T) {
 		var selectedPane string
 		// Override the base mock for this specific scenario
@@ -255,9 +485,254 @@ func TestTmuxSelectEdgePane(t *testing.T) {
 			return mockTmuxSequence(&selectedPane)(args...)
 		}
 
-		tmuxSelectEdgePane(true, 50)
+		tmuxSelectEdgePane(DirRight, nil, hopOptions{waitMs: 50, zoom: zoomEdge})
 		if selectedPane != "" {
 			t.Errorf("expected no pane to be selected, but got %q", selectedPane)
 		}
 	})
 }
+
+func TestTmuxWrapPane(t *testing.T) {
+	t.Setenv("TMUX", "/tmp/tmux-1000/default,21,0")
+	originalRunTmux := runTmuxCmd
+	defer func() { runTmuxCmd = originalRunTmux }()
+
+	// mockWindow serves a window whose active pane %3 sits at the right edge.
+	mockWindow := func(current string, selected *string) func(args ...string) (string, error) {
+		return func(args ...string) (string, error) {
+			cmd := strings.Join(args, " ")
+			switch {
+			case strings.HasPrefix(cmd, "display -p #{pane_id} #{window_id}"):
+				return current, nil
+			case strings.HasPrefix(cmd, "list-panes -t @1"):
+				return "%1 1 0\n%2 0 0\n%3 0 1", nil
+			case strings.HasPrefix(cmd, "select-pane -t"):
+				*selected = args[2]
+				return "", nil
+			}
+			return "", errors.New("unexpected command: " + cmd)
+		}
+	}
+
+	t.Run("WrapsToLeftmost", func(t *testing.T) {
+		var selected string
+		runTmuxCmd = mockWindow("%3 @1 0", &selected)
+		if !tmuxWrapPane(DirRight) {
+			t.Fatal("expected tmuxWrapPane to wrap")
+		}
+		if selected != "%1" {
+			t.Errorf("expected leftmost pane '%%1', got %q", selected)
+		}
+	})
+
+	t.Run("SinglePaneAcross", func(t *testing.T) {
+		var selected string
+		// The active pane is also at the left edge: nothing to wrap to.
+		runTmuxCmd = mockWindow("%3 @1 1", &selected)
+		if tmuxWrapPane(DirRight) {
+			t.Error("expected no wrap when the active pane spans the window")
+		}
+		if selected != "" {
+			t.Errorf("expected no pane to be selected, got %q", selected)
+		}
+	})
+
+	t.Run("NoTmux", func(t *testing.T) {
+		t.Setenv("TMUX", "")
+		if tmuxWrapPane(DirRight) {
+			t.Error("expected no wrap outside tmux")
+		}
+	})
+}
+
+// fakeSource serves a fixed window layout to hop.
+type fakeSource struct {
+	cur      neighbor.Rect
+	cands    []neighbor.Candidate
+	monitors []neighbor.Display
+	focused  string
+}
+
+func (f *fakeSource) windows() (neighbor.Rect, []neighbor.Candidate, int) { return f.cur, f.cands, 0 }
+func (f *fakeSource) displays() []neighbor.Display                        { return f.monitors }
+func (f *fakeSource) focus(id string)                                     { f.focused = id }
+
+func TestHopWrap(t *testing.T) {
+	isolateChain(t)
+	layout := func() *fakeSource {
+		return &fakeSource{
+			cur: neighbor.Rect{X: 2000, Y: 0, W: 900, H: 1000},
+			cands: []neighbor.Candidate{
+				{ID: "west", Rect: neighbor.Rect{X: 0, Y: 0, W: 900, H: 1000}},
+				{ID: "middle", Rect: neighbor.Rect{X: 1000, Y: 0, W: 900, H: 1000}},
+			},
+		}
+	}
+
+	t.Run("Off", func(t *testing.T) {
+		src := layout()
+		if rc := hop(src, DirRight, false, hopOptions{}); rc != 5 {
+			t.Errorf("expected rc 5 at the right edge without wrap, got %d", rc)
+		}
+		if src.focused != "" {
+			t.Errorf("expected no focus change, got %q", src.focused)
+		}
+	})
+
+	t.Run("On", func(t *testing.T) {
+		src := layout()
+		if rc := hop(src, DirRight, false, hopOptions{wrapWindows: true}); rc != 0 {
+			t.Errorf("expected rc 0 when wrapping, got %d", rc)
+		}
+		if src.focused != "west" {
+			t.Errorf("expected to wrap to the far-west window, got %q", src.focused)
+		}
+	})
+}
+
+func TestHopDisplay(t *testing.T) {
+	isolateChain(t)
+	layout := func() *fakeSource {
+		return &fakeSource{
+			cur: neighbor.Rect{X: 0, Y: 0, W: 1000, H: 1000},
+			cands: []neighbor.Candidate{
+				{ID: "front-on-2", Rect: neighbor.Rect{X: 2000, Y: 0, W: 800, H: 1000}},
+				{ID: "back-on-2", Rect: neighbor.Rect{X: 2100, Y: 0, W: 800, H: 1000}},
+				{ID: "on-3", Rect: neighbor.Rect{X: 4000, Y: 0, W: 800, H: 1000}},
+			},
+			// Deliberately out of order: numbering is left to right.
+			monitors: []neighbor.Display{
+				{ID: "c", Rect: neighbor.Rect{X: 3840, Y: 0, W: 1920, H: 1080}},
+				{ID: "a", Rect: neighbor.Rect{X: 0, Y: 0, W: 1920, H: 1080}},
+				{ID: "b", Rect: neighbor.Rect{X: 1920, Y: 0, W: 1920, H: 1080}},
+			},
+		}
+	}
+
+	tests := []struct {
+		target string
+		rc     int
+		want   string
+	}{
+		{"next", 0, "front-on-2"},
+		{"prev", 0, "on-3"},
+		{"3", 0, "on-3"},
+		{"1", 5, ""}, // already there
+		{"4", 5, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			src := layout()
+			if rc := hopDisplay(src, tt.target); rc != tt.rc {
+				t.Errorf("expected rc %d, got %d", tt.rc, rc)
+			}
+			if src.focused != tt.want {
+				t.Errorf("expected %q to be focused, got %q", tt.want, src.focused)
+			}
+		})
+	}
+}
+
+func TestTmuxOriginHint(t *testing.T) {
+	originalRunTmux := runTmuxCmd
+	defer func() { runTmuxCmd = originalRunTmux }()
+	runTmuxCmd = func(args ...string) (string, error) {
+		if strings.Join(args, " ") == "display -p #{pane_top} #{pane_height} #{window_height}" {
+			return "20 12 32", nil
+		}
+		return "", errors.New("unexpected command")
+	}
+	cur := neighbor.Rect{X: 0, Y: 100, W: 800, H: 640}
+	dest := neighbor.Rect{X: 800, Y: 0, W: 800, H: 900}
+
+	t.Setenv("TMUX", "/tmp/tmux-1000/default,21,0")
+	hint := tmuxOriginHint(DirRight, cur, dest)
+	if hint.lo != 500 || hint.hi != 740 || hint.dest != dest {
+		t.Errorf("expected pane span 500..740, got %+v", *hint)
+	}
+
+	t.Setenv("TMUX", "")
+	hint = tmuxOriginHint(DirRight, cur, dest)
+	if hint.lo != 100 || hint.hi != 740 {
+		t.Errorf("expected whole-window span 100..740 outside tmux, got %+v", *hint)
+	}
+}
+
+func TestTmuxZoomPolicy(t *testing.T) {
+	t.Setenv("TMUX", "/tmp/tmux-1000/default,21,0")
+	originalRunTmux := runTmuxCmd
+	defer func() { runTmuxCmd = originalRunTmux }()
+
+	// mockZoomed serves a zoomed window whose active pane %0 has a neighbor
+	// to the right once unzoomed, recording every mutating command.
+	mockZoomed := func(calls *[]string) func(args ...string) (string, error) {
+		paneID := "%0"
+		return func(args ...string) (string, error) {
+			cmd := strings.Join(args, " ")
+			switch {
+			case cmd == "display -p #{pane_id}":
+				return paneID, nil
+			case cmd == "display -p #{window_zoomed_flag}":
+				return "1", nil
+			case strings.Contains(cmd, "pane_current_command"):
+				return "zsh /dev/ttys001", nil
+			case strings.Contains(cmd, "pane_at_right"):
+				return "0", nil
+			case strings.HasPrefix(cmd, "select-pane"):
+				paneID = "%1"
+			}
+			*calls = append(*calls, cmd)
+			return "", nil
+		}
+	}
+
+	tests := []struct {
+		policy zoomPolicy
+		moved  bool
+		calls  []string
+	}{
+		{zoomEdge, false, nil},
+		{zoomUnzoom, true, []string{"resize-pane -Z", "select-pane -R"}},
+		{zoomKeep, true, []string{"resize-pane -Z", "select-pane -R", "resize-pane -Z"}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			var calls []string
+			runTmuxCmd = mockZoomed(&calls)
+			if moved := tmuxTryPaneMove(DirRight, tt.policy); moved != tt.moved {
+				t.Errorf("expected moved=%v, got %v", tt.moved, moved)
+			}
+			if strings.Join(calls, "; ") != strings.Join(tt.calls, "; ") {
+				t.Errorf("expected commands %q, got %q", tt.calls, calls)
+			}
+		})
+	}
+
+	t.Run("LandingKeepsZoom", func(t *testing.T) {
+		var selected string
+		runTmuxCmd = func(args ...string) (string, error) {
+			cmd := strings.Join(args, " ")
+			switch {
+			case strings.HasPrefix(cmd, "list-clients"):
+				return "/dev/ttys001 1 1627840934", nil
+			case strings.HasPrefix(cmd, "display -p -t /dev/ttys001"):
+				return "@1 1", nil
+			case strings.HasPrefix(cmd, "list-panes -t @1"):
+				return "%1 1 0\n%2 0 1", nil
+			case strings.HasPrefix(cmd, "select-pane"):
+				selected = cmd
+			}
+			return "", nil
+		}
+		tmuxSelectEdgePane(DirRight, nil, hopOptions{waitMs: 50, zoom: zoomKeep})
+		if selected != "select-pane -Z -t %1" {
+			t.Errorf("expected zoom-preserving select of %%1, got %q", selected)
+		}
+
+		selected = ""
+		tmuxSelectEdgePane(DirRight, nil, hopOptions{waitMs: 50, zoom: zoomEdge})
+		if selected != "" {
+			t.Errorf("expected the zoomed pane to be left alone, got %q", selected)
+		}
+	})
+}
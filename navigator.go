@@ -0,0 +1,204 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/leejonesio/ttyhop/internal/neighbor"
+)
+
+// Navigator moves focus between the splits or panes of one layer: an
+// editor, a multiplexer or a terminal's own splits. A hop tries each
+// navigator of the chain in turn and hops OS windows at the "windows" step.
+type Navigator interface {
+	// Try moves to the neighbor in dir; it returns false at the edge, and
+	// when ttyhop isn't running inside this layer.
+	Try(dir Direction) bool
+	// LandAtEdge runs after a window hop in dir and focuses the split or
+	// pane on the edge the hop entered from: the LEFTMOST when moving
+	// right, the TOPMOST when moving down, and so on.
+	LandAtEdge(dir Direction)
+}
+
+// originRecorder is a Navigator whose landing needs to know where the hop
+// started. recordOrigin runs before focus moves to the new window; cur is
+// the rect of the window left and dest the window hopped to.
+type originRecorder interface {
+	recordOrigin(dir Direction, cur neighbor.Rect, dest neighbor.Candidate)
+}
+
+// paneWrapper is a Navigator that can wrap around to the far pane when no
+// step of the chain moved (--wrap-panes).
+type paneWrapper interface {
+	wrapPane(dir Direction) bool
+}
+
+// windowsStep is the chain step that hops between OS windows.
+const windowsStep = "windows"
+
+// defaultChain is the order a hop tries things in: editor, multiplexer,
+// terminal splits, then OS windows. A chain that moves nothing exits 5, so
+// the key binding passes the key through.
+const defaultChain = "nvim,emacs,tmux,zellij,screen,kitty,wezterm," + windowsStep
+
+// navigatorKind is a navigator a chain can name. open builds one for a
+// single hop, sharing state with the hop's other navigators.
+type navigatorKind struct {
+	name string
+	open func(opts hopOptions, state *hopState) Navigator
+}
+
+// navigatorKinds lists every navigator. A new multiplexer only needs an
+// entry here.
+var navigatorKinds = []navigatorKind{
+	{"nvim", func(opts hopOptions, state *hopState) Navigator {
+		return &nvimNavigator{opts: opts, state: state}
+	}},
+	{"emacs", func(opts hopOptions, state *hopState) Navigator {
+		return &emacsNavigator{opts: opts, state: state}
+	}},
+	{"tmux", func(opts hopOptions, state *hopState) Navigator { return &tmuxNavigator{opts: opts, state: state} }},
+	{"zellij", func(hopOptions, *hopState) Navigator { return &zellijNavigator{} }},
+	{"screen", func(hopOptions, *hopState) Navigator { return &screenNavigator{} }},
+	{"kitty", func(opts hopOptions, _ *hopState) Navigator { return &kittyNavigator{waitMs: opts.waitMs} }},
+	{"wezterm", func(opts hopOptions, _ *hopState) Navigator { return &weztermNavigator{waitMs: opts.waitMs} }},
+}
+
+// hopState is what the navigators of one hop share.
+type hopState struct {
+	// tmuxLanded is the tmux pane the hop landed in, once known.
+	tmuxLanded *tmuxLanding
+	// tmuxOrigin is the tty of the tmux client the hop left, once
+	// tmuxOriginKnown.
+	tmuxOrigin      string
+	tmuxOriginKnown bool
+}
+
+// tmuxLanding is a pane a window hop landed in: pane of level, or the
+// level's current pane when pane is "".
+type tmuxLanding struct {
+	level tmuxLevel
+	pane  string
+}
+
+// recordTmuxOrigin remembers the focused tmux client before a window hop.
+func (s *hopState) recordTmuxOrigin(opts hopOptions) {
+	if s.tmuxOriginKnown {
+		return
+	}
+	_, s.tmuxOrigin, _ = tmuxFocusedClient(opts.tmuxSockets)
+	s.tmuxOriginKnown = true
+}
+
+// landedTmuxPane returns the tmux pane a window hop landed in, for an
+// editor running there: the one tmux landing selected, else the current
+// pane of the first other client to take focus within opts.waitMs.
+func (s *hopState) landedTmuxPane(opts hopOptions) (*tmuxLanding, bool) {
+	if s.tmuxLanded == nil {
+		l, ok := tmuxLandedLevel(opts, s.tmuxOrigin)
+		if !ok {
+			return nil, false
+		}
+		s.tmuxLanded = &tmuxLanding{level: l}
+	}
+	return s.tmuxLanded, true
+}
+
+// defaultNavigatorChain is defaultChain, parsed.
+var defaultNavigatorChain = mustParseChain(defaultChain)
+
+// chainSteps lists the names a chain can use.
+func chainSteps() string {
+	names := make([]string, 0, len(navigatorKinds)+1)
+	for _, k := range navigatorKinds {
+		names = append(names, k.name)
+	}
+	return strings.Join(append(names, windowsStep), ", ")
+}
+
+// parseChain reads a comma-separated chain of navigator names and
+// "windows". Leaving a step out turns it off.
+func parseChain(spec string) ([]string, error) {
+	var chain []string
+	seen := map[string]bool{}
+	for _, step := range strings.Split(spec, ",") {
+		step = strings.ToLower(strings.TrimSpace(step))
+		if step == "" {
+			continue
+		}
+		known := step == windowsStep
+		for _, k := range navigatorKinds {
+			known = known || k.name == step
+		}
+		if !known {
+			return nil, fmt.Errorf("chain: unknown step %q (have: %s)", step, chainSteps())
+		}
+		if seen[step] {
+			return nil, fmt.Errorf("chain: %q listed twice", step)
+		}
+		seen[step] = true
+		chain = append(chain, step)
+	}
+	if len(chain) == 0 {
+		return nil, fmt.Errorf("chain: no steps")
+	}
+	return chain, nil
+}
+
+func mustParseChain(spec string) []string {
+	chain, err := parseChain(spec)
+	if err != nil {
+		panic(err)
+	}
+	return chain
+}
+
+// openChain builds the navigators of the --chain in opts for one hop, in
+// order. The windows step becomes windows, and is dropped when windows is
+// nil.
+func openChain(opts hopOptions, windows Navigator) []Navigator {
+	chain := opts.chain
+	if chain == nil {
+		chain = defaultNavigatorChain
+	}
+	state := &hopState{}
+	var navs []Navigator
+	for _, step := range chain {
+		if step == windowsStep {
+			if windows != nil {
+				navs = append(navs, windows)
+			}
+			continue
+		}
+		for _, k := range navigatorKinds {
+			if k.name == step {
+				navs = append(navs, k.open(opts, state))
+			}
+		}
+	}
+	return navs
+}
+
+// wrapChain wraps around in the first navigator that can; return true if
+// one did.
+func wrapChain(navs []Navigator, dir Direction) bool {
+	for _, n := range navs {
+		if w, ok := n.(paneWrapper); ok && w.wrapPane(dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// tryChain tries each navigator in turn; return true once one moved.
+func tryChain(navs []Navigator, dir Direction) bool {
+	for _, n := range navs {
+		if n.Try(dir) {
+			return true
+		}
+	}
+	return false
+}
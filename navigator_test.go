@@ -0,0 +1,126 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/leejonesio/ttyhop/internal/neighbor"
+)
+
+func TestParseChain(t *testing.T) {
+	for _, spec := range []string{"", " , ", "tmux,vscode", "tmux,windows,tmux"} {
+		if _, err := parseChain(spec); err == nil {
+			t.Errorf("parseChain(%q): expected an error", spec)
+		}
+	}
+	chain, err := parseChain(" Tmux, windows ,kitty ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(chain, ","); got != "tmux,windows,kitty" {
+		t.Errorf("unexpected chain %q", got)
+	}
+	if got := strings.Join(mustParseChain(defaultChain), ","); got != defaultChain {
+		t.Errorf("default chain parsed to %q", got)
+	}
+}
+
+// fakeNavigator records what a hop asked of it in calls.
+type fakeNavigator struct {
+	name  string
+	moves bool
+	calls *[]string
+}
+
+func (n fakeNavigator) Try(dir Direction) bool {
+	*n.calls = append(*n.calls, n.name+" try "+dir.String())
+	return n.moves
+}
+
+func (n fakeNavigator) LandAtEdge(dir Direction) {
+	*n.calls = append(*n.calls, n.name+" land "+dir.String())
+}
+
+func (n fakeNavigator) wrapPane(dir Direction) bool {
+	*n.calls = append(*n.calls, n.name+" wrap "+dir.String())
+	return n.moves
+}
+
+func (n fakeNavigator) recordOrigin(dir Direction, cur neighbor.Rect, dest neighbor.Candidate) {
+	*n.calls = append(*n.calls, n.name+" origin "+dir.String()+" to "+dest.Title)
+}
+
+func TestHopChain(t *testing.T) {
+	layout := func() *fakeSource {
+		return &fakeSource{
+			cur:   neighbor.Rect{X: 0, Y: 0, W: 900, H: 1000},
+			cands: []neighbor.Candidate{{ID: "east", Rect: neighbor.Rect{X: 1000, Y: 0, W: 900, H: 1000}, Title: "vim"}},
+		}
+	}
+	withKinds := func(t *testing.T, calls *[]string, moving string) {
+		t.Helper()
+		originalKinds := navigatorKinds
+		t.Cleanup(func() { navigatorKinds = originalKinds })
+		navigatorKinds = nil
+		for _, name := range []string{"editor", "mux", "splits"} {
+			n := fakeNavigator{name: name, moves: name == moving, calls: calls}
+			navigatorKinds = append(navigatorKinds, navigatorKind{name, func(hopOptions, *hopState) Navigator { return n }})
+		}
+	}
+
+	t.Run("FirstMoveWins", func(t *testing.T) {
+		var calls []string
+		withKinds(t, &calls, "mux")
+		src := layout()
+		if rc := hop(src, DirRight, true, hopOptions{chain: mustParseChain("editor,mux,splits,windows")}); rc != 0 {
+			t.Errorf("expected rc 0, got %d", rc)
+		}
+		if got := strings.Join(calls, "; "); got != "editor try right; mux try right" || src.focused != "" {
+			t.Errorf("unexpected calls %q, focus %q", got, src.focused)
+		}
+	})
+
+	t.Run("WindowHopLandsOutsideIn", func(t *testing.T) {
+		var calls []string
+		withKinds(t, &calls, "")
+		src := layout()
+		if rc := hop(src, DirRight, true, hopOptions{chain: mustParseChain("editor,mux,windows,splits")}); rc != 0 || src.focused != "east" {
+			t.Errorf("expected a hop east, got rc %d and focus %q", rc, src.focused)
+		}
+		want := "editor try right; mux try right; editor origin right to vim; mux origin right to vim; splits origin right to vim; " +
+			"splits land right; mux land right; editor land right"
+		if got := strings.Join(calls, "; "); got != want {
+			t.Errorf("got calls %q, want %q", got, want)
+		}
+	})
+
+	t.Run("NoWindowsStep", func(t *testing.T) {
+		var calls []string
+		withKinds(t, &calls, "")
+		src := layout()
+		if rc := hop(src, DirRight, true, hopOptions{chain: mustParseChain("splits")}); rc != 5 || src.focused != "" {
+			t.Errorf("expected rc 5 without a windows step, got rc %d and focus %q", rc, src.focused)
+		}
+	})
+
+	t.Run("WrapsOnlyInChain", func(t *testing.T) {
+		var calls []string
+		withKinds(t, &calls, "")
+		if rc := hop(layout(), DirLeft, true, hopOptions{wrapPanes: true, chain: mustParseChain("editor,windows,mux")}); rc != 5 {
+			t.Errorf("expected rc 5, got %d", rc)
+		}
+		want := "editor try left; mux try left; editor wrap left; mux wrap left"
+		if got := strings.Join(calls, "; "); got != want {
+			t.Errorf("got calls %q, want %q", got, want)
+		}
+
+		calls = nil
+		hop(layout(), DirLeft, true, hopOptions{wrapPanes: true, chain: mustParseChain("editor,windows")})
+		if got := strings.Join(calls, "; "); got != "editor try left; editor wrap left" {
+			t.Errorf("expected no wrap outside the chain, got %q", got)
+		}
+	})
+}
@@ -0,0 +1,168 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/leejonesio/ttyhop/internal/neighbor"
+	"github.com/leejonesio/ttyhop/internal/nvim"
+)
+
+// ---------- Neovim splits ----------
+
+// nvimWincmd is the wincmd key that moves in direction dir.
+func nvimWincmd(dir Direction) string {
+	return map[Direction]string{DirLeft: "h", DirRight: "l", DirUp: "k", DirDown: "j"}[dir]
+}
+
+// nvimAddress finds the RPC address of the nvim a hop should steer first:
+// $NVIM when ttyhop runs inside nvim (a :terminal or a job), else the nvim
+// in the active pane of the innermost tmux.
+func nvimAddress() string {
+	if addr := os.Getenv("NVIM"); addr != "" {
+		return addr
+	}
+	if os.Getenv("TMUX") == "" {
+		return ""
+	}
+	levels := tmuxNesting()
+	return nvimPaneAddress(levels[len(levels)-1], "")
+}
+
+// nvimPaneAddress finds the RPC address of the nvim running in pane of the
+// tmux level l (a pane id, or "" for the level's current pane).
+func nvimPaneAddress(l tmuxLevel, pane string) string {
+	var out string
+	var err error
+	if pane == "" {
+		out, err = l.display("#{pane_current_command} #{pane_tty}")
+	} else {
+		out, err = l.server.run("display", "-p", "-t", pane, "#{pane_current_command} #{pane_tty}")
+	}
+	f := strings.Fields(out)
+	if err != nil || len(f) < 2 || f[0] != "nvim" {
+		return ""
+	}
+	return nvimTTYAddress(f[len(f)-1])
+}
+
+// nvimTTYAddress finds the server address of the nvim in the foreground on
+// tty: its --listen argument, else the default socket named after its pid.
+// Since nvim 0.9 the server is an "nvim --embed" child of the one started,
+// which is in the same foreground group.
+func nvimTTYAddress(tty string) string {
+	procs := foregroundProcesses(tty)
+	for _, p := range procs {
+		if filepath.Base(p.args[0]) != "nvim" {
+			continue
+		}
+		for i, a := range p.args {
+			if a == "--listen" && i+1 < len(p.args) {
+				return p.args[i+1]
+			}
+		}
+	}
+	for _, p := range procs {
+		if filepath.Base(p.args[0]) != "nvim" {
+			continue
+		}
+		for _, dir := range nvimSocketDirs() {
+			if m, _ := filepath.Glob(filepath.Join(dir, "nvim."+p.pid+".*")); len(m) > 0 {
+				return m[0]
+			}
+		}
+	}
+	return ""
+}
+
+// nvimSocketDirs are where nvim puts its default sockets, stdpath("run"):
+// $XDG_RUNTIME_DIR, else a per-user directory under the temp directory.
+func nvimSocketDirs() []string {
+	var dirs []string
+	if d := os.Getenv("XDG_RUNTIME_DIR"); d != "" {
+		dirs = append(dirs, d)
+	}
+	user := os.Getenv("USER")
+	for _, tmp := range []string{os.TempDir(), "/tmp"} {
+		dirs = append(dirs, filepath.Join(tmp, "nvim."+user, "*"))
+	}
+	return dirs
+}
+
+// nvimTryMove moves to the nvim split next to the current one in dir;
+// return true if moved. wincmd stays put at the edge, so an unchanged
+// winnr() means the caller should move tmux panes or hop windows.
+func nvimTryMove(dir Direction) bool {
+	addr := nvimAddress()
+	if addr == "" {
+		return false
+	}
+	c, err := nvim.Dial(addr)
+	if err != nil {
+		dbg("nvim: cannot connect to %s: %v", addr, err)
+		return false
+	}
+	defer c.Close()
+
+	before, err := c.EvalInt("winnr()")
+	if err != nil {
+		dbg("nvim: %v", err)
+		return false
+	}
+	if err := c.Command("wincmd " + nvimWincmd(dir)); err != nil {
+		dbg("nvim: %v", err)
+		return false
+	}
+	after, err := c.EvalInt("winnr()")
+	if err != nil || after == before {
+		dbg("nvim: at %s edge", dir)
+		return false
+	}
+	dbg("nvim: split move %s (%d -> %d)", dir, before, after)
+	return true
+}
+
+// nvimSelectEdgeSplit focuses the edge split of the nvim in the tmux pane a
+// hop landed on: the LEFTMOST when moving right, the TOPMOST when moving
+// down, and so on. A count of 999 makes wincmd go as far as it can.
+func nvimSelectEdgeSplit(l tmuxLevel, pane string, dir Direction) {
+	addr := nvimPaneAddress(l, pane)
+	if addr == "" {
+		return
+	}
+	c, err := nvim.Dial(addr)
+	if err != nil {
+		dbg("nvim: cannot connect to %s: %v", addr, err)
+		return
+	}
+	defer c.Close()
+
+	if err := c.Command("999wincmd " + nvimWincmd(dir.Opposite())); err != nil {
+		dbg("nvim: %v", err)
+		return
+	}
+	dbg("nvim: landed on %s edge split", dir.Opposite())
+}
+
+// nvimNavigator moves between nvim splits, and lands a window hop on the
+// edge split of the nvim in the tmux pane it landed on.
+type nvimNavigator struct {
+	opts  hopOptions
+	state *hopState
+}
+
+func (n *nvimNavigator) Try(dir Direction) bool { return nvimTryMove(dir) }
+
+func (n *nvimNavigator) recordOrigin(Direction, neighbor.Rect, neighbor.Candidate) {
+	n.state.recordTmuxOrigin(n.opts)
+}
+
+func (n *nvimNavigator) LandAtEdge(dir Direction) {
+	if p, ok := n.state.landedTmuxPane(n.opts); ok {
+		nvimSelectEdgeSplit(p.level, p.pane, dir)
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/leejonesio/ttyhop/internal/nvim/nvimtest"
+)
+
+// nvimSplits is window 1 down the left, beside window 2 over window 3.
+var nvimSplits = []nvimtest.Window{
+	{Row: 0, Col: 0, Rows: 40, Cols: 80},
+	{Row: 0, Col: 81, Rows: 20, Cols: 80},
+	{Row: 21, Col: 81, Rows: 19, Cols: 80},
+}
+
+func TestNvimTryMove(t *testing.T) {
+	tests := []struct {
+		name    string
+		current int
+		dir     Direction
+		moved   bool
+		want    int
+	}{
+		{"Right", 1, DirRight, true, 2},
+		{"AtLeftEdge", 1, DirLeft, false, 1},
+		{"Down", 2, DirDown, true, 3},
+		{"AtRightEdge", 3, DirRight, false, 3},
+		{"Left", 3, DirLeft, true, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := nvimtest.NewServer(t, nvimSplits, tt.current)
+			t.Setenv("NVIM", s.Addr)
+			if moved := nvimTryMove(tt.dir); moved != tt.moved {
+				t.Errorf("nvimTryMove(%s) = %v, want %v", tt.dir, moved, tt.moved)
+			}
+			if got := s.Current(); got != tt.want {
+				t.Errorf("current window %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("NoNvim", func(t *testing.T) {
+		t.Setenv("NVIM", "")
+		t.Setenv("TMUX", "")
+		if nvimTryMove(DirRight) {
+			t.Error("expected no move without an nvim")
+		}
+	})
+
+	t.Run("BeforeTmux", func(t *testing.T) {
+		s := nvimtest.NewServer(t, nvimSplits, 1)
+		t.Setenv("NVIM", s.Addr)
+		t.Setenv("TMUX", "")
+		if rc := (&tmuxHopper{}).FocusNeighbor(DirRight, false, true); rc != 0 || s.Current() != 2 {
+			t.Errorf("expected the split move to win, got rc %d and window %d", rc, s.Current())
+		}
+	})
+}
+
+func TestNvimAddress(t *testing.T) {
+	runtime := t.TempDir()
+	socket := filepath.Join(runtime, "nvim.201.0")
+	if err := os.WriteFile(socket, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		command string
+		ps      string
+		want    string
+	}{
+		{"Listen", "nvim", "300 300 300 nvim --listen /tmp/edit.sock main.go", "/tmp/edit.sock"},
+		{"DefaultSocketOfEmbeddedServer", "nvim", "200 200 200 nvim main.go\n201 200 200 nvim --embed", socket},
+		{"NoSocket", "nvim", "400 400 400 nvim main.go", ""},
+		{"NotNvim", "zsh", "500 500 500 -zsh", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("NVIM", "")
+			t.Setenv("TMUX", "/tmp/tmux-1000/default,21,0")
+			t.Setenv("XDG_RUNTIME_DIR", runtime)
+			t.Setenv("TMPDIR", t.TempDir())
+			originalRunTmux, originalRunPs := runTmuxCmd, runPsCmd
+			defer func() { runTmuxCmd, runPsCmd = originalRunTmux, originalRunPs }()
+			runTmuxCmd = func(args ...string) (string, error) {
+				return tt.command + " /dev/pts/7", nil
+			}
+			runPsCmd = func(args ...string) (string, error) {
+				if strings.Join(args, " ") != "-t pts/7 -o pid=,pgid=,tpgid=,args=" {
+					return "", nil
+				}
+				return "100 100 300 -zsh\n" + tt.ps, nil
+			}
+			if got := nvimAddress(); got != tt.want {
+				t.Errorf("nvimAddress() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNvimSelectEdgeSplit(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		current int
+		dir     Direction
+		want    int
+	}{
+		{"MovingRightLandsLeftmost", "nvim", 3, DirRight, 1},
+		{"MovingLeftLandsRightmost", "nvim", 1, DirLeft, 2},
+		{"MovingDownLandsTopmost", "nvim", 3, DirDown, 2},
+		{"NotNvim", "zsh", 3, DirRight, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := nvimtest.NewServer(t, nvimSplits, tt.current)
+			originalRunTmux, originalRunPs := runTmuxCmd, runPsCmd
+			defer func() { runTmuxCmd, runPsCmd = originalRunTmux, originalRunPs }()
+			runTmuxCmd = func(args ...string) (string, error) {
+				if strings.Join(args, " ") != "-L inner display -p -t %8 #{pane_current_command} #{pane_tty}" {
+					return "", nil
+				}
+				return tt.command + " /dev/pts/7", nil
+			}
+			runPsCmd = func(args ...string) (string, error) {
+				return "300 300 300 nvim --listen " + s.Addr, nil
+			}
+			nvimSelectEdgeSplit(tmuxLevel{server: tmuxServer{args: []string{"-L", "inner"}}}, "%8", tt.dir)
+			if got := s.Current(); got != tt.want {
+				t.Errorf("current window %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/leejonesio/ttyhop/internal/neighbor"
+)
+
+// ---------- GNU screen ----------
+//
+// screen can't say whether a region is at the edge, so moves compare the
+// focused region before and after "screen -X focus", like zellij. The
+// region comes from "screen -Q info" (screen 4.2 or later).
+
+// screenMaxLandSteps bounds the moves landing makes to reach the edge region.
+const screenMaxLandSteps = 16
+
+// inScreen reports whether ttyhop runs inside a screen session.
+func inScreen() bool {
+	return os.Getenv("STY") != ""
+}
+
+// runScreen runs screen against session, or against the session ttyhop
+// runs in when session is "". The output is returned even on error.
+func runScreen(session string, args ...string) (string, error) {
+	if session != "" {
+		args = append([]string{"-S", session}, args...)
+	}
+	cmd := exec.Command("screen", args...)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	err := cmd.Run()
+	return strings.TrimSpace(out.String()), err
+}
+
+// screenRegion describes session's focused region from "screen -Q info",
+// e.g. "(1,5)/(80,24)+1000 +flow UTF-8 2(vim)": its size and the window it
+// shows. The leading cursor position is dropped, since the program in the
+// window can move it at any time. screen can't report where a region is,
+// so two regions of the same size showing the same window look alike, and
+// a move between them is taken for the edge.
+func screenRegion(session string) (string, error) {
+	out, err := runScreen(session, "-Q", "info")
+	if err != nil {
+		return "", err
+	}
+	_, region, _ := strings.Cut(out, "/")
+	return region, nil
+}
+
+// screenDirection is the focus argument for dir.
+func screenDirection(dir Direction) string {
+	return map[Direction]string{DirLeft: "left", DirRight: "right", DirUp: "up", DirDown: "down"}[dir]
+}
+
+// screenTryMove focuses the screen region next to the current one in dir;
+// return true if moved. An unchanged region means the caller should hop
+// windows.
+func screenTryMove(dir Direction) bool {
+	if !inScreen() {
+		return false
+	}
+	before, err := screenRegion("")
+	if err != nil || before == "" {
+		dbg("screen: no focused region (%v)", err)
+		return false
+	}
+	if _, err := runScreen("", "-X", "focus", screenDirection(dir)); err != nil {
+		return false
+	}
+	after, _ := screenRegion("")
+	if after == "" || after == before {
+		dbg("screen: at %s edge", dir)
+		return false
+	}
+	dbg("screen: region move %s", dir)
+	return true
+}
+
+// screenTitleSession returns the session a window shows, from a window
+// title starting "screen (NAME)". screen only sets the terminal's title
+// when its hardstatus is set up to, e.g. hardstatus string "screen (%S)".
+// It is "" for any other title.
+func screenTitleSession(title string) string {
+	rest, ok := strings.CutPrefix(title, "screen (")
+	if !ok {
+		return ""
+	}
+	name, _, ok := strings.Cut(rest, ")")
+	if !ok {
+		return ""
+	}
+	return name
+}
+
+// screenSelectEdgeRegion focuses the edge region of the screen session
+// shown in the window a hop landed on, named by its title: the LEFTMOST
+// when moving right, the TOPMOST when moving down, and so on. It moves
+// back until the region stops changing.
+func screenSelectEdgeRegion(dir Direction, title string) {
+	session := screenTitleSession(title)
+	if session == "" {
+		dbg("screen: window title %q names no session; not landing", title)
+		return
+	}
+	prev, err := screenRegion(session)
+	if err != nil || prev == "" {
+		dbg("screen: no region in session %s (%v); not landing", session, err)
+		return
+	}
+	for i := 0; i < screenMaxLandSteps; i++ {
+		if _, err := runScreen(session, "-X", "focus", screenDirection(dir.Opposite())); err != nil {
+			break
+		}
+		cur, err := screenRegion(session)
+		if err != nil || cur == prev {
+			break
+		}
+		prev = cur
+	}
+	dbg("screen: landed on %s edge region %s (session %s)", dir.Opposite(), prev, session)
+}
+
+// screenNavigator moves between GNU screen regions. Landing reads the
+// session from the title of the window hopped to.
+type screenNavigator struct {
+	title string
+}
+
+func (n *screenNavigator) Try(dir Direction) bool { return screenTryMove(dir) }
+
+func (n *screenNavigator) recordOrigin(_ Direction, _ neighbor.Rect, dest neighbor.Candidate) {
+	n.title = dest.Title
+}
+
+func (n *screenNavigator) LandAtEdge(dir Direction) { screenSelectEdgeRegion(dir, n.title) }
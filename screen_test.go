@@ -0,0 +1,103 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fakeScreenScript stands in for GNU screen, after fakeMuxShell. "-Q
+// info" describes the focused region, named by its window number, and "-X
+// focus" moves it. Like screen, -S also takes a session's name without its
+// pid.
+const fakeScreenScript = `s=$STY
+if [ "$1" = "-S" ]; then s=$2; shift 2; fi
+for f in "$d"/*."$s".focus; do [ -f "$f" ] && s=$(basename "$f" .focus); done
+[ -f "$d/$s.focus" ] || { echo "No screen session found." >&2; exit 1; }
+cur=$(cat "$d/$s.focus")
+case "$1 $2" in
+"-Q info") echo "($$,1)/(80,24)+1000 +flow UTF-8 $cur(zsh)" ;;
+"-X focus") move_focus "$3" ;;
+*) exit 1 ;;
+esac
+`
+
+// fakeScreen puts a fake screen first on $PATH, running in session
+// 100.main with regions 0 | 1 focused on region. Session 200.side has
+// regions 0 | 1 | 2 focused on 1, and 300.old regions 0 | 1 focused on 0.
+// It returns the fake's directory.
+func fakeScreen(t *testing.T, region string) string {
+	t.Helper()
+	dir := fakeMux(t, "screen", fakeScreenScript,
+		fakeSession{"100.main", region, []string{"0", "1"}},
+		fakeSession{"200.side", "1", []string{"0", "1", "2"}},
+		fakeSession{"300.old", "0", []string{"0", "1"}})
+	t.Setenv("STY", "100.main")
+	return dir
+}
+
+func TestScreenTryMove(t *testing.T) {
+	tests := []struct {
+		name   string
+		region string
+		dir    Direction
+		moved  bool
+		want   string
+	}{
+		{"Right", "0", DirRight, true, "1"},
+		{"AtRightEdge", "1", DirRight, false, "1"},
+		{"Left", "1", DirLeft, true, "0"},
+		{"AtBottomEdge", "0", DirDown, false, "0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := fakeScreen(t, tt.region)
+			if moved := screenTryMove(tt.dir); moved != tt.moved {
+				t.Errorf("screenTryMove(%s) = %v, want %v", tt.dir, moved, tt.moved)
+			}
+			if got := readFile(t, filepath.Join(dir, "100.main.focus")); got != tt.want {
+				t.Errorf("focused region %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("OutsideScreen", func(t *testing.T) {
+		dir := fakeScreen(t, "0")
+		t.Setenv("STY", "")
+		if screenTryMove(DirRight) {
+			t.Error("expected no move outside screen")
+		}
+		if _, err := os.Stat(filepath.Join(dir, "log")); err == nil {
+			t.Error("expected screen not to be run outside screen")
+		}
+	})
+}
+
+func TestScreenSelectEdgeRegion(t *testing.T) {
+	tests := []struct {
+		name  string
+		dir   Direction
+		title string
+		want  string
+	}{
+		{"MovingRightLandsLeftmost", DirRight, "screen (200.side) zsh", "0"},
+		{"MovingLeftLandsRightmost", DirLeft, "screen (side)", "2"},
+		{"OtherWindow", DirRight, "zsh", "1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := fakeScreen(t, "1")
+			t.Setenv("STY", "")
+			screenSelectEdgeRegion(tt.dir, tt.title)
+			if got := readFile(t, filepath.Join(dir, "200.side.focus")); got != tt.want {
+				t.Errorf("200.side focused region %s, want %s", got, tt.want)
+			}
+			if got := readFile(t, filepath.Join(dir, "100.main.focus")); got != "1" {
+				t.Errorf("expected the other session to keep its region, got %s", got)
+			}
+		})
+	}
+}
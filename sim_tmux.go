@@ -0,0 +1,238 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// simTmux stands in for tmux under the sim backend. It answers the
+// commands ttyhop sends (display, list-panes, list-clients, select-pane
+// and resize-pane) from the panes in a simLayout, so pane moves and
+// landing run their real code paths. Each window with panes has one tmux
+// window and one client, on the tty /dev/sim/<window id>; commands without
+// a target act on the window ttyhop was started in, like $TMUX does.
+type simTmux struct {
+	layout *simLayout
+	origin *simWindow
+}
+
+// simFormatVar matches a format variable or a conditional whose branches
+// hold no further variables; expand applies it until those are gone too.
+var simFormatVar = regexp.MustCompile(`#\{(\?)?([a-z_]+)(?:,([^,}#]*),([^}#]*))?\}`)
+
+func simClientTTY(w *simWindow) string {
+	return "/dev/sim/" + w.ID
+}
+
+func (t *simTmux) run(args ...string) (string, error) {
+	// Only the default server exists; -L/-S name another one.
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return "", errors.New("sim: no such tmux server")
+	}
+	cmd, target, rest := args[0], "", []string{}
+	var flags []string
+	for i := 1; i < len(args); i++ {
+		switch {
+		case (args[i] == "-t" || args[i] == "-F") && i+1 < len(args):
+			if args[i] == "-t" {
+				target = args[i+1]
+			} else {
+				rest = append(rest, args[i+1])
+			}
+			i++
+		case strings.HasPrefix(args[i], "-"):
+			flags = append(flags, args[i])
+		default:
+			rest = append(rest, args[i])
+		}
+	}
+
+	switch cmd {
+	case "display":
+		w, p := t.resolve(target)
+		if p == nil || len(rest) == 0 {
+			return "", fmt.Errorf("sim: can't find pane %s", target)
+		}
+		return t.expand(rest[0], w, p), nil
+	case "list-panes":
+		w, _ := t.resolve(target)
+		if w == nil || len(rest) == 0 {
+			return "", fmt.Errorf("sim: can't find window %s", target)
+		}
+		var lines []string
+		for _, p := range w.Tmux.Panes {
+			lines = append(lines, t.expand(rest[0], w, p))
+		}
+		return strings.Join(lines, "\n"), nil
+	case "list-clients":
+		var lines []string
+		for _, w := range t.layout.Windows {
+			if w.Tmux != nil && len(rest) > 0 {
+				lines = append(lines, t.expand(rest[0], w, w.activePane()))
+			}
+		}
+		return strings.Join(lines, "\n"), nil
+	case "select-pane":
+		w, p := t.resolve(target)
+		if p == nil {
+			return "", fmt.Errorf("sim: can't find pane %s", target)
+		}
+		for _, f := range flags {
+			switch f {
+			case "-L", "-R", "-U", "-D":
+				if w.Tmux.Zoomed {
+					return "", nil
+				}
+				if next := w.Tmux.neighbor(p, f); next != nil {
+					p = next
+				}
+			case "-Z":
+				w.Tmux.Zoomed = true
+			}
+		}
+		w.Tmux.activate(p)
+		return "", nil
+	case "resize-pane":
+		w, p := t.resolve(target)
+		if p == nil {
+			return "", fmt.Errorf("sim: can't find pane %s", target)
+		}
+		w.Tmux.activate(p)
+		w.Tmux.Zoomed = !w.Tmux.Zoomed
+		return "", nil
+	}
+	return "", fmt.Errorf("sim: unknown command %s", cmd)
+}
+
+// resolve finds the window and pane a -t target names: a client tty, a
+// pane id, a window id, or nothing for the origin window.
+func (t *simTmux) resolve(target string) (*simWindow, *simPane) {
+	for _, w := range t.layout.Windows {
+		if w.Tmux == nil {
+			continue
+		}
+		switch {
+		case target == "" && w == t.origin,
+			target == simClientTTY(w),
+			target == w.Tmux.id:
+			return w, w.activePane()
+		}
+		for _, p := range w.Tmux.Panes {
+			if p.ID == target {
+				return w, p
+			}
+		}
+	}
+	return nil, nil
+}
+
+// expand fills in a tmux format for pane p of window w. A zoomed window's
+// active pane fills it.
+func (t *simTmux) expand(format string, w *simWindow, p *simPane) string {
+	left, top, width, height := p.Left, p.Top, p.Width, p.Height
+	if w.Tmux.Zoomed && p.Active {
+		left, top, width, height = 0, 0, w.Tmux.Width, w.Tmux.Height
+	}
+	flag := func(b bool) string {
+		if b {
+			return "1"
+		}
+		return "0"
+	}
+	focused := 0
+	if w.Focused {
+		focused = 1
+	}
+	vars := map[string]string{
+		"pane_id":              p.ID,
+		"pane_tty":             "/dev/sim/" + strings.TrimPrefix(p.ID, "%"),
+		"pane_current_command": p.Command,
+		"pane_left":            strconv.Itoa(left),
+		"pane_top":             strconv.Itoa(top),
+		"pane_width":           strconv.Itoa(width),
+		"pane_height":          strconv.Itoa(height),
+		"pane_at_left":         flag(left == 0),
+		"pane_at_top":          flag(top == 0),
+		"pane_at_right":        flag(left+width >= w.Tmux.Width),
+		"pane_at_bottom":       flag(top+height >= w.Tmux.Height),
+		"window_id":            w.Tmux.id,
+		"window_width":         strconv.Itoa(w.Tmux.Width),
+		"window_height":        strconv.Itoa(w.Tmux.Height),
+		"window_zoomed_flag":   flag(w.Tmux.Zoomed),
+		"client_tty":           simClientTTY(w),
+		"client_control_mode":  "0",
+		"client_active":        flag(w.Focused),
+		"client_activity":      strconv.Itoa(focused),
+	}
+	for {
+		out := simFormatVar.ReplaceAllStringFunc(format, func(m string) string {
+			sub := simFormatVar.FindStringSubmatch(m)
+			v := vars[sub[2]]
+			if sub[1] == "" {
+				return v
+			}
+			if v != "" && v != "0" {
+				return sub[3]
+			}
+			return sub[4]
+		})
+		if out == format {
+			return out
+		}
+		format = out
+	}
+}
+
+func (w *simWindow) activePane() *simPane {
+	if w.Tmux == nil || len(w.Tmux.Panes) == 0 {
+		return nil
+	}
+	for _, p := range w.Tmux.Panes {
+		if p.Active {
+			return p
+		}
+	}
+	return w.Tmux.Panes[0]
+}
+
+func (tw *simTmuxWindow) activate(p *simPane) {
+	for _, o := range tw.Panes {
+		o.Active = o == p
+	}
+}
+
+// neighbor finds the pane next to p that select-pane flag (-L, -R, -U or
+// -D) moves to: one across the shared border, overlapping p the most.
+func (tw *simTmuxWindow) neighbor(p *simPane, flag string) *simPane {
+	var best *simPane
+	bestOverlap := 0
+	for _, o := range tw.Panes {
+		var gap, overlap int
+		switch flag {
+		case "-L":
+			gap, overlap = p.Left-(o.Left+o.Width), span(p.Top, p.Height, o.Top, o.Height)
+		case "-R":
+			gap, overlap = o.Left-(p.Left+p.Width), span(p.Top, p.Height, o.Top, o.Height)
+		case "-U":
+			gap, overlap = p.Top-(o.Top+o.Height), span(p.Left, p.Width, o.Left, o.Width)
+		case "-D":
+			gap, overlap = o.Top-(p.Top+p.Height), span(p.Left, p.Width, o.Left, o.Width)
+		}
+		// Panes are separated by a one-cell border.
+		if o != p && gap >= 0 && gap <= 1 && overlap > bestOverlap {
+			best, bestOverlap = o, overlap
+		}
+	}
+	return best
+}
+
+// span is the length two ranges share.
+func span(aLo, aLen, bLo, bLen int) int {
+	return max(0, min(aLo+aLen, bLo+bLen)-max(aLo, bLo))
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// appInfo identifies a window for the terminal allowlist. Each backend
+// fills in what its platform knows and leaves the rest empty.
+type appInfo struct {
+	bundle   string // macOS bundle identifier
+	name     string // macOS application name
+	class    string // X11 WM_CLASS class
+	instance string // X11 WM_CLASS instance
+	appID    string // Wayland app_id
+	title    string // window title
+}
+
+// termMatcher is one entry of the terminal allowlist.
+type termMatcher struct {
+	field string // bundle, name, class, app_id, title, or "" for any of the ids
+	value string
+	re    *regexp.Regexp // for title
+}
+
+// defaultTerminals is the allowlist when --terminals isn't given.
+const defaultTerminals = "bundle:org.alacritty,bundle:io.alacritty,Alacritty"
+
+// defaultTerminalMatchers is defaultTerminals, parsed.
+var defaultTerminalMatchers = mustParseTerminals(defaultTerminals)
+
+// parseTerminals reads a comma-separated allowlist. Each entry is
+// "bundle:ID", "name:NAME", "class:WM_CLASS", "app_id:ID" or
+// "title:REGEXP"; a bare entry matches any of the ids (bundle id, name,
+// WM_CLASS or app_id). Ids match case-insensitively.
+func parseTerminals(spec string) ([]termMatcher, error) {
+	var ms []termMatcher
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		m := termMatcher{value: entry}
+		if field, value, ok := strings.Cut(entry, ":"); ok {
+			switch field {
+			case "bundle", "name", "class", "app_id", "title":
+				m.field, m.value = field, value
+			}
+		}
+		if m.value == "" {
+			return nil, fmt.Errorf("terminal %q: empty %s", entry, m.field)
+		}
+		if m.field == "title" {
+			re, err := regexp.Compile(m.value)
+			if err != nil {
+				return nil, fmt.Errorf("terminal %q: %w", entry, err)
+			}
+			m.re = re
+		}
+		ms = append(ms, m)
+	}
+	if len(ms) == 0 {
+		return nil, fmt.Errorf("no terminals in %q", spec)
+	}
+	return ms, nil
+}
+
+func mustParseTerminals(spec string) []termMatcher {
+	ms, err := parseTerminals(spec)
+	if err != nil {
+		panic(err)
+	}
+	return ms
+}
+
+func (m termMatcher) match(a appInfo) bool {
+	is := func(s string) bool { return s != "" && strings.EqualFold(s, m.value) }
+	switch m.field {
+	case "bundle":
+		return is(a.bundle)
+	case "name":
+		return is(a.name)
+	case "class":
+		return is(a.class) || is(a.instance)
+	case "app_id":
+		return is(a.appID)
+	case "title":
+		return m.re.MatchString(a.title)
+	}
+	return is(a.bundle) || is(a.name) || is(a.class) || is(a.instance) || is(a.appID)
+}
+
+// allowlist returns the --terminals allowlist windows are checked against.
+func (o *hopOptions) allowlist() []termMatcher {
+	if o.terminals == nil {
+		return defaultTerminalMatchers
+	}
+	return o.terminals
+}
+
+// isTerminal reports whether a window belongs to an allowed terminal.
+func (o *hopOptions) isTerminal(a appInfo) bool {
+	for _, m := range o.allowlist() {
+		if m.match(a) {
+			return true
+		}
+	}
+	return false
+}
+
+// terminalsNeedTitles reports whether the allowlist matches on window
+// titles, so an app can't be ruled out by its ids alone.
+func (o *hopOptions) terminalsNeedTitles() bool {
+	for _, m := range o.allowlist() {
+		if m.field == "title" {
+			return true
+		}
+	}
+	return false
+}
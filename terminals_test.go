@@ -0,0 +1,50 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package main
+
+import "testing"
+
+func TestParseTerminals(t *testing.T) {
+	for _, spec := range []string{"", " , ", "bundle:", "title:(", "title:"} {
+		if _, err := parseTerminals(spec); err == nil {
+			t.Errorf("parseTerminals(%q): expected an error", spec)
+		}
+	}
+	ms, err := parseTerminals(" kitty, class:foot ,title:^vim ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ms) != 3 || ms[0].field != "" || ms[1].field != "class" || ms[1].value != "foot" || ms[2].re == nil {
+		t.Errorf("unexpected matchers %+v", ms)
+	}
+}
+
+func TestIsTerminal(t *testing.T) {
+	opts := hopOptions{terminals: mustParseTerminals("bundle:net.kovidgoyal.kitty,class:foot,app_id:org.wezfurlong.wezterm,title:^vim ,WezTerm")}
+
+	tests := []struct {
+		name string
+		app  appInfo
+		want bool
+	}{
+		{"Bundle", appInfo{bundle: "net.kovidgoyal.kitty", name: "kitty"}, true},
+		{"BundleIsNotName", appInfo{name: "net.kovidgoyal.kitty"}, false},
+		{"ClassInstance", appInfo{class: "Foot", instance: "foot"}, true},
+		{"ClassIgnoresCase", appInfo{class: "FOOT"}, true},
+		{"AppID", appInfo{appID: "org.wezfurlong.wezterm"}, true},
+		{"BareMatchesAnyID", appInfo{class: "wezterm"}, true},
+		{"Title", appInfo{appID: "firefox", title: "vim notes.txt"}, true},
+		{"TitleIsAnchored", appInfo{appID: "firefox", title: "about vim "}, false},
+		{"Other", appInfo{bundle: "org.mozilla.firefox", name: "Firefox"}, false},
+		{"Empty", appInfo{}, false},
+	}
+	for _, tt := range tests {
+		if got := opts.isTerminal(tt.app); got != tt.want {
+			t.Errorf("%s: isTerminal(%+v) = %v, want %v", tt.name, tt.app, got, tt.want)
+		}
+	}
+	if !opts.terminalsNeedTitles() {
+		t.Error("expected a title: entry to need titles")
+	}
+}
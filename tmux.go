@@ -0,0 +1,495 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/leejonesio/ttyhop/internal/neighbor"
+)
+
+// ---------- tmux IPC helpers ----------
+
+type runTmuxCmdFunc func(args ...string) (string, error)
+
+var runTmuxCmd runTmuxCmdFunc = defaultRunTmuxCmd
+
+// execTmuxCmd runs one tmux command in its own process. defaultRunTmuxCmd
+// prefers a control-mode connection and falls back to this.
+func execTmuxCmd(args ...string) (string, error) {
+	cmd := exec.Command("tmux", args...)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	err := cmd.Run()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out.String()), nil
+}
+
+// tmuxServer addresses one tmux server by its socket flags, e.g.
+// ["-L", "work"] or ["-S", "/tmp/tmux-501/inner"]. The zero value is the
+// server the environment points at ($TMUX, else tmux's default socket).
+type tmuxServer struct {
+	args []string
+}
+
+func (s tmuxServer) run(args ...string) (string, error) {
+	if len(s.args) == 0 {
+		return runTmuxCmd(args...)
+	}
+	return runTmuxCmd(append(append([]string(nil), s.args...), args...)...)
+}
+
+func (s tmuxServer) String() string {
+	if len(s.args) == 0 {
+		return "default"
+	}
+	return strings.Join(s.args, " ")
+}
+
+// tmuxLevel is one tmux server in a stack of nested ones, plus the client
+// whose current pane moves act on. An empty client means the client the
+// environment points at, which is how the outermost level is addressed.
+type tmuxLevel struct {
+	server tmuxServer
+	client string
+}
+
+// display prints format for the level's current pane.
+func (l tmuxLevel) display(format string) (string, error) {
+	if l.client == "" {
+		return l.server.run("display", "-p", format)
+	}
+	return l.server.run("display", "-p", "-t", l.client, format)
+}
+
+// paneCmd runs a pane command (select-pane, resize-pane) against pane,
+// which is only named explicitly for nested levels.
+func (l tmuxLevel) paneCmd(pane string, args ...string) {
+	if l.client != "" {
+		args = append(args, "-t", pane)
+	}
+	_, _ = l.server.run(args...)
+}
+
+// tmuxFlag is the select-pane flag that moves in direction dir.
+func tmuxFlag(dir Direction) string {
+	return map[Direction]string{DirLeft: "-L", DirRight: "-R", DirUp: "-U", DirDown: "-D"}[dir]
+}
+
+// tmuxEdgeFormat is the tmux format that expands to 1 when the active pane
+// has no neighbor in direction dir.
+func tmuxEdgeFormat(dir Direction) string {
+	return "#{pane_at_" + map[Direction]string{DirLeft: "left", DirRight: "right", DirUp: "top", DirDown: "bottom"}[dir] + "}"
+}
+
+// zoomPolicy decides how hops treat a zoomed tmux window.
+type zoomPolicy string
+
+const (
+	zoomEdge   zoomPolicy = "edge"   // a zoomed pane is the edge: hop straight to the next OS window
+	zoomUnzoom zoomPolicy = "unzoom" // unzoom, then move between panes
+	zoomKeep   zoomPolicy = "keep"   // move between panes and zoom the new one
+)
+
+func parseZoomPolicy(s string) (zoomPolicy, bool) {
+	switch p := zoomPolicy(s); p {
+	case zoomEdge, zoomUnzoom, zoomKeep:
+		return p, true
+	}
+	return "", false
+}
+
+// Try to move tmux pane first; return true if moved. When the active pane
+// runs a nested tmux, the innermost server gets the first try and each
+// outer one the next, so moves bubble out at every edge.
+func tmuxTryPaneMove(dir Direction, zoom zoomPolicy) bool {
+	if os.Getenv("TMUX") == "" {
+		return false
+	}
+	levels := tmuxNesting()
+	for i := len(levels) - 1; i >= 0; i-- {
+		if levels[i].tryPaneMove(dir, zoom) {
+			return true
+		}
+		if i > 0 {
+			dbg("tmux: nested server %s at %s edge; trying the outer server", levels[i].server, dir)
+		}
+	}
+	return false
+}
+
+// tryPaneMove moves from the level's current pane; return true if moved.
+// NOTE: #{pane_at_left/right/top/bottom} == 1 means you are AT the outer edge (no neighbor that way).
+func (l tmuxLevel) tryPaneMove(dir Direction, zoom zoomPolicy) bool {
+	// Active pane before move
+	oldID, err := l.display("#{pane_id}")
+	if err != nil || strings.TrimSpace(oldID) == "" {
+		return false
+	}
+
+	// A zoomed pane fills the window, so pane_at_* reports every edge until
+	// it is unzoomed; only the unzoom/keep policies look past it.
+	zoomFlag, _ := l.display("#{window_zoomed_flag}")
+	zoomed := strings.TrimSpace(zoomFlag) == "1"
+	if zoomed {
+		if zoom != zoomUnzoom && zoom != zoomKeep {
+			dbg("tmux: pane is zoomed; treating as edge")
+			return false
+		}
+		l.paneCmd(oldID, "resize-pane", "-Z")
+	}
+	rezoom := func() {
+		if zoomed {
+			l.paneCmd(oldID, "resize-pane", "-Z")
+		}
+	}
+
+	// Edge check (1 = at edge, no neighbor; 0 = has neighbor)
+	edge, err := l.display(tmuxEdgeFormat(dir))
+	if err != nil {
+		rezoom()
+		return false
+	}
+	if strings.TrimSpace(edge) == "1" {
+		rezoom()     // leave the window as we found it
+		return false // at edge, let caller hop windows
+	}
+
+	// Move relative to the active pane (no -t at the outer level)
+	l.paneCmd(oldID, "select-pane", tmuxFlag(dir))
+
+	// Verify it actually changed pane
+	newID, _ := l.display("#{pane_id}")
+	if strings.TrimSpace(newID) == "" || newID == oldID {
+		rezoom()
+		return false
+	}
+	if zoom == zoomKeep && zoomed {
+		l.paneCmd(newID, "resize-pane", "-Z") // zoom the pane we moved to
+	}
+
+	dbg("tmux: pane move %s via IPC (server %s)", dir, l.server)
+	return true
+}
+
+// tmuxClient is one attached client as reported by list-clients.
+type tmuxClient struct {
+	server   tmuxServer
+	tty      string
+	active   bool
+	activity int64
+}
+
+// clients lists the server's attached clients, leaving out control-mode
+// ones (ttyhop's own among them), which no window shows: they print
+// "control" for their tty. Not every tmux knows client_active or
+// client_control_mode, so they are wrapped in conditionals that always
+// print something.
+func (s tmuxServer) clients() ([]tmuxClient, error) {
+	out, err := s.run("list-clients", "-F", "#{?client_control_mode,control,#{client_tty}} #{?client_active,1,0} #{client_activity}")
+	if err != nil || out == "" {
+		return nil, err
+	}
+	var clients []tmuxClient
+	for _, ln := range strings.Split(out, "\n") {
+		f := strings.Fields(ln)
+		if len(f) < 3 || f[0] == "control" {
+			continue
+		}
+		a, _ := strconv.ParseInt(f[2], 10, 64)
+		clients = append(clients, tmuxClient{server: s, tty: f[0], active: (f[1] == "1"), activity: a})
+	}
+	return clients, nil
+}
+
+// pickClient prefers an active client, then the most recently active one.
+func pickClient(clients []tmuxClient) (tmuxClient, bool) {
+	var best tmuxClient
+	var have bool
+	for _, c := range clients {
+		if c.active {
+			return c, true
+		}
+		if !have || c.activity > best.activity {
+			best, have = c, true
+		}
+	}
+	return best, have
+}
+
+// edgeHint lines the landing pane up with where a hop started. lo and hi
+// bound the origin pane across the axis of travel, in the same units as
+// dest, the destination window's rect.
+type edgeHint struct {
+	lo, hi float64
+	dest   neighbor.Rect
+}
+
+// pick returns which of n edge panes lines up best with the origin: the one
+// overlapping it most, or when none does the one closest to it. span places
+// pane i in the units of dest; a pane it can't place loses to any it can.
+// It returns -1 when n is 0.
+func (h *edgeHint) pick(n int, span func(i int) (lo, hi float64, ok bool)) int {
+	best, bestOverlap := -1, math.Inf(-1)
+	for i := 0; i < n; i++ {
+		overlap := math.Inf(-1)
+		if lo, hi, ok := span(i); ok {
+			// Negative when they don't meet: minus the gap between them.
+			overlap = min(hi, h.hi) - max(lo, h.lo)
+		}
+		if best < 0 || overlap > bestOverlap {
+			best, bestOverlap = i, overlap
+		}
+	}
+	return best
+}
+
+// tmuxSpanFormat expands to a pane's offset and size across the axis of
+// travel, followed by the window's size on that axis.
+func tmuxSpanFormat(dir Direction) string {
+	if dir.Vertical() {
+		return "#{pane_left} #{pane_width} #{window_width}"
+	}
+	return "#{pane_top} #{pane_height} #{window_height}"
+}
+
+// spanIn maps a pane extent (offset, size, window size in cells, as printed
+// by tmuxSpanFormat) onto r across the axis of travel.
+func spanIn(dir Direction, r neighbor.Rect, f []string) (lo, hi float64, ok bool) {
+	if len(f) != 3 {
+		return 0, 0, false
+	}
+	off, err1 := strconv.ParseFloat(f[0], 64)
+	size, err2 := strconv.ParseFloat(f[1], 64)
+	total, err3 := strconv.ParseFloat(f[2], 64)
+	if err1 != nil || err2 != nil || err3 != nil {
+		return 0, 0, false
+	}
+	return spanAt(dir, r, off, size, total)
+}
+
+// spanAt maps an extent of off..off+size out of total cells onto r across
+// the axis of travel.
+func spanAt(dir Direction, r neighbor.Rect, off, size, total float64) (lo, hi float64, ok bool) {
+	if total <= 0 {
+		return 0, 0, false
+	}
+	start, length := r.Y, r.H
+	if dir.Vertical() {
+		start, length = r.X, r.W
+	}
+	return start + length*off/total, start + length*(off+size)/total, true
+}
+
+// tmuxOriginHint describes the active pane's position on screen before a
+// hop from the window at cur to the window at dest. Outside tmux the whole
+// window is the origin.
+func tmuxOriginHint(dir Direction, cur, dest neighbor.Rect) *edgeHint {
+	lo, hi := cur.Y, cur.Y+cur.H
+	if dir.Vertical() {
+		lo, hi = cur.X, cur.X+cur.W
+	}
+	if os.Getenv("TMUX") != "" {
+		if out, err := runTmuxCmd("display", "-p", tmuxSpanFormat(dir)); err == nil {
+			if pLo, pHi, ok := spanIn(dir, cur, strings.Fields(out)); ok {
+				lo, hi = pLo, pHi
+			}
+		}
+	}
+	return &edgeHint{lo: lo, hi: hi, dest: dest}
+}
+
+// edgeWaitMs is how long landing waits for the destination window: waitMs,
+// else $TTYHOP_EDGE_WAIT_MS, else the default.
+func edgeWaitMs(waitMs int) int {
+	if waitMs > 0 {
+		return waitMs
+	}
+	if v, err := strconv.Atoi(os.Getenv("TTYHOP_EDGE_WAIT_MS")); err == nil && v > 0 {
+		return v
+	}
+	return defaultWaitMs
+}
+
+// tmuxSelectEdgePane lands a window hop in dir on the edge pane of the
+// newly focused tmux client, and of any tmux nested in it. It returns the
+// innermost level landed in and its pane ("" for the level's current
+// pane), or false when no client answered within opts.waitMs.
+func tmuxSelectEdgePane(dir Direction, hint *edgeHint, opts hopOptions) (tmuxLevel, string, bool) {
+	// Wait briefly for the newly focused terminal window's tmux client to become active.
+	waitMs := edgeWaitMs(opts.waitMs)
+	dbg("using edge wait: %dms", waitMs)
+
+	pollInterval := pollIntervalMs * time.Millisecond
+	numPolls := waitMs / pollIntervalMs
+
+	for i := 0; i < numPolls; i++ {
+		time.Sleep(pollInterval)
+
+		server, tty, err := tmuxFocusedClient(opts.tmuxSockets)
+		if err != nil || strings.TrimSpace(tty) == "" {
+			continue
+		}
+
+		l := tmuxLevel{server: server, client: tty}
+		target, err := tmuxLand(l, dir, hint, opts.zoom)
+		if err != nil {
+			continue
+		}
+		if target != "" {
+			l, target = tmuxLandNested(server, target, dir, hint, opts.zoom)
+		}
+		return l, target, true
+	}
+	return tmuxLevel{}, "", false
+}
+
+// tmuxLand selects the edge pane in the current window of the level's
+// client. It returns the pane it selected ("" for none), or an error when
+// the client can't answer yet and the caller should retry.
+func tmuxLand(l tmuxLevel, dir Direction, hint *edgeHint, zoom zoomPolicy) (string, error) {
+	// Query that client's current window
+	out, err := l.display("#{window_id} #{window_zoomed_flag}")
+	f := strings.Fields(out)
+	if err != nil || len(f) == 0 {
+		return "", errors.New("tmux: no current window")
+	}
+	win, zoomed := f[0], len(f) > 1 && f[1] == "1"
+
+	if zoomed && zoom != zoomUnzoom && zoom != zoomKeep {
+		dbg("tmux: destination window is zoomed; staying on its zoomed pane")
+		return "", nil
+	}
+	target, err := tmuxEdgePane(l.server, win, dir, hint)
+	if err != nil || target == "" {
+		return "", err
+	}
+	if zoomed && zoom == zoomKeep {
+		_, _ = l.server.run("select-pane", "-Z", "-t", target)
+	} else {
+		if zoomed {
+			_, _ = l.server.run("resize-pane", "-Z", "-t", win)
+		}
+		_, _ = l.server.run("select-pane", "-t", target)
+	}
+	dbg("tmux: landed on %s edge pane (server %s)", dir.Opposite(), l.server)
+	return target, nil
+}
+
+// tmuxEdgePane returns the pane in window win that a hop in dir should land
+// on: the LEFTMOST pane when moving right, the TOPMOST when moving down, and
+// so on. When several panes share that edge (e.g. a stacked left column),
+// hint picks the one that best overlaps the origin pane; without a hint the
+// first is used. Returns "" if no pane reports being at that edge.
+func tmuxEdgePane(server tmuxServer, win string, dir Direction, hint *edgeHint) (string, error) {
+	// List panes in that window; use the pane_at_* pair for the axis of travel (1 = outer edge)
+	edgeFmt := "#{pane_id} #{pane_at_left} #{pane_at_right} "
+	if dir.Vertical() {
+		edgeFmt = "#{pane_id} #{pane_at_top} #{pane_at_bottom} "
+	}
+	panes, err := server.run("list-panes", "-t", win, "-F", edgeFmt+tmuxSpanFormat(dir))
+	if err != nil || strings.TrimSpace(panes) == "" {
+		return "", errors.New("tmux: no panes listed")
+	}
+
+	var ids []string
+	var spans [][]string
+	for _, ln := range strings.Split(panes, "\n") {
+		f := strings.Fields(ln)
+		if len(f) < 3 {
+			continue
+		}
+		id, atNear, atFar := f[0], f[1], f[2]
+		onEdge := false
+		if (dir == DirRight || dir == DirDown) && atNear == "1" { // moving right/down -> land on LEFTMOST/TOPMOST pane
+			onEdge = true
+		}
+		if (dir == DirLeft || dir == DirUp) && atFar == "1" { // moving left/up -> land on RIGHTMOST/BOTTOMMOST pane
+			onEdge = true
+		}
+		if !onEdge {
+			continue
+		}
+		if hint == nil {
+			return id, nil
+		}
+		ids, spans = append(ids, id), append(spans, f[3:])
+	}
+	i := hint.pick(len(ids), func(i int) (float64, float64, bool) { return spanIn(dir, hint.dest, spans[i]) })
+	if i < 0 {
+		return "", nil
+	}
+	return ids[i], nil
+}
+
+// tmuxWrapPane wraps around inside the current tmux window: moving right off
+// the rightmost pane selects the leftmost one, and so on. Returns true if the
+// active pane changed.
+func tmuxWrapPane(dir Direction) bool {
+	if os.Getenv("TMUX") == "" {
+		return false
+	}
+	// The active pane spanning both edges means nothing to wrap to on this axis.
+	cur, err := runTmuxCmd("display", "-p", "#{pane_id} #{window_id} "+tmuxEdgeFormat(dir.Opposite())+" "+tmuxSpanFormat(dir))
+	f := strings.Fields(cur)
+	if err != nil || len(f) < 3 || f[2] == "1" {
+		return false
+	}
+	// Stay on the same row (or column): in cell units the window is its own rect.
+	var hint *edgeHint
+	if len(f) == 6 {
+		total, _ := strconv.ParseFloat(f[5], 64)
+		win := neighbor.Rect{W: total, H: total}
+		if lo, hi, ok := spanIn(dir, win, f[3:]); ok {
+			hint = &edgeHint{lo: lo, hi: hi, dest: win}
+		}
+	}
+	target, err := tmuxEdgePane(tmuxServer{}, f[1], dir, hint)
+	if err != nil || target == "" || target == f[0] {
+		return false
+	}
+	if _, err := runTmuxCmd("select-pane", "-t", target); err != nil {
+		return false
+	}
+	dbg("tmux: wrapped %s to %s edge pane", dir, dir.Opposite())
+	return true
+}
+
+// tmuxNavigator moves between tmux panes, and lands a window hop on the
+// edge pane lined up with the pane it left.
+type tmuxNavigator struct {
+	opts  hopOptions
+	state *hopState
+	hint  *edgeHint
+}
+
+func (n *tmuxNavigator) Try(dir Direction) bool {
+	if tmuxTryPaneMove(dir, n.opts.zoom) {
+		dbg("tmux: moved pane %s", dir)
+		return true
+	}
+	return false
+}
+
+func (n *tmuxNavigator) recordOrigin(dir Direction, cur neighbor.Rect, dest neighbor.Candidate) {
+	n.hint = tmuxOriginHint(dir, cur, dest.Rect)
+}
+
+func (n *tmuxNavigator) wrapPane(dir Direction) bool { return tmuxWrapPane(dir) }
+
+func (n *tmuxNavigator) LandAtEdge(dir Direction) {
+	if l, pane, ok := tmuxSelectEdgePane(dir, n.hint, n.opts); ok {
+		n.state.tmuxLanded = &tmuxLanding{level: l, pane: pane}
+	}
+}
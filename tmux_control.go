@@ -0,0 +1,236 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tmuxControlTimeout bounds how long one command waits for its reply.
+const tmuxControlTimeout = 500 * time.Millisecond
+
+// errTmuxControl marks a broken control connection, as opposed to a tmux
+// command that failed.
+var errTmuxControl = errors.New("tmux control")
+
+// tmuxControl is one tmux -C client kept open for the life of the process,
+// so a hop costs one tmux exec instead of one per command. Only the $TMUX
+// server gets one, attached to ttyhop's own session: attaching to another
+// server would pick an arbitrary session, fire its client-attached hooks
+// and reorder its sessions.
+// Commands are written as lines and answered by %begin ... %end (or
+// %error) blocks; everything else the server sends is a notification.
+type tmuxControl struct {
+	in    io.WriteCloser
+	lines chan string
+	wait  func() error
+}
+
+// startTmuxControl launches a control client for the server named by
+// socket args. It is a variable so tests can swap in a fake tmux.
+var startTmuxControl = defaultStartTmuxControl
+
+func defaultStartTmuxControl(socket []string) (*tmuxControl, error) {
+	// Attach to our own session so untargeted commands act on our pane.
+	args := append(append([]string(nil), socket...), "-C", "attach", "-f", "ignore-size,no-output", "-t", tmuxControlTarget(socket))
+	cmd := exec.Command("tmux", args...)
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	return newTmuxControl(in, out, cmd.Wait), nil
+}
+
+// tmuxControlTarget is the session the control client attaches to: the
+// pane (or $TMUX session) ttyhop runs in, for the $TMUX server only. It is
+// "" when there is no such session to attach to.
+func tmuxControlTarget(socket []string) string {
+	if len(socket) > 0 || os.Getenv("TMUX") == "" {
+		return ""
+	}
+	if p := os.Getenv("TMUX_PANE"); p != "" {
+		return p
+	}
+	if f := strings.Split(os.Getenv("TMUX"), ","); len(f) == 3 && f[2] != "" {
+		return "$" + f[2]
+	}
+	return ""
+}
+
+// newTmuxControl wraps a control client's pipes, feeding its output lines
+// to reply.
+func newTmuxControl(in io.WriteCloser, out io.Reader, wait func() error) *tmuxControl {
+	c := &tmuxControl{in: in, lines: make(chan string, 64), wait: wait}
+	go func() {
+		sc := bufio.NewScanner(out)
+		sc.Buffer(make([]byte, 64*1024), 1024*1024)
+		for sc.Scan() {
+			c.lines <- sc.Text()
+		}
+		close(c.lines)
+	}()
+	return c
+}
+
+// reply reads the next %begin ... %end/%error block, skipping
+// notifications in between.
+func (c *tmuxControl) reply() (string, error) {
+	timeout := time.After(tmuxControlTimeout)
+	var out []string
+	var num string
+	for {
+		var ln string
+		var ok bool
+		select {
+		case ln, ok = <-c.lines:
+		case <-timeout:
+			return "", fmt.Errorf("%w: timed out", errTmuxControl)
+		}
+		if !ok {
+			return "", fmt.Errorf("%w: connection closed", errTmuxControl)
+		}
+		f := strings.Fields(ln)
+		switch {
+		case num == "" && len(f) >= 3 && f[0] == "%begin":
+			num = f[2]
+		case num == "":
+			// notification, e.g. %session-changed
+		case len(f) >= 3 && (f[0] == "%end" || f[0] == "%error") && f[2] == num:
+			text := strings.TrimSpace(strings.Join(out, "\n"))
+			if f[0] == "%error" {
+				return "", fmt.Errorf("tmux: %s", text)
+			}
+			return text, nil
+		default:
+			out = append(out, ln)
+		}
+	}
+}
+
+// run sends one command and returns its output, like defaultRunTmuxCmd.
+func (c *tmuxControl) run(args ...string) (string, error) {
+	if _, err := io.WriteString(c.in, tmuxQuote(args)+"\n"); err != nil {
+		return "", fmt.Errorf("%w: %v", errTmuxControl, err)
+	}
+	return c.reply()
+}
+
+func (c *tmuxControl) close() {
+	_ = c.in.Close() // an empty stdin detaches the client
+	done := make(chan struct{})
+	go func() {
+		_ = c.wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(tmuxControlTimeout):
+	}
+}
+
+// tmuxQuote renders args as a tmux command line. Double quotes keep format
+// strings whole and stop "#" starting a comment.
+func tmuxQuote(args []string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`)
+	q := make([]string, len(args))
+	for i, a := range args {
+		q[i] = `"` + r.Replace(a) + `"`
+	}
+	return strings.Join(q, " ")
+}
+
+// splitSocketArgs separates leading -L/-S socket flags from a command.
+func splitSocketArgs(args []string) (socket, rest []string) {
+	for len(args) >= 2 && (args[0] == "-L" || args[0] == "-S") {
+		socket, args = append(socket, args[0], args[1]), args[2:]
+	}
+	return socket, args
+}
+
+var (
+	tmuxControlMu sync.Mutex
+	// tmuxControls holds one client per server; a nil entry means control
+	// mode failed for that server and commands go through exec.
+	tmuxControls = map[string]*tmuxControl{}
+)
+
+// tmuxControlEnabled reports whether control mode may be used; set
+// TTYHOP_TMUX_CONTROL=0 to always exec.
+func tmuxControlEnabled() bool {
+	return os.Getenv("TTYHOP_TMUX_CONTROL") != "0"
+}
+
+// defaultRunTmuxCmd runs a command over the $TMUX server's control client,
+// falling back to execTmuxCmd for other servers and when control mode isn't
+// available.
+func defaultRunTmuxCmd(args ...string) (string, error) {
+	socket, rest := splitSocketArgs(args)
+	if len(rest) == 0 || !tmuxControlEnabled() || tmuxControlTarget(socket) == "" || strings.ContainsAny(strings.Join(rest, ""), "\n") {
+		return execTmuxCmd(args...)
+	}
+	key := strings.Join(socket, " ")
+
+	tmuxControlMu.Lock()
+	defer tmuxControlMu.Unlock()
+	c, seen := tmuxControls[key]
+	if !seen {
+		c = openTmuxControl(socket)
+		tmuxControls[key] = c
+	}
+	if c == nil {
+		return execTmuxCmd(args...)
+	}
+	out, err := c.run(rest...)
+	if errors.Is(err, errTmuxControl) {
+		// The connection broke rather than the command failing.
+		dbg("tmux: control mode for %s failed (%v); using exec", tmuxServer{args: socket}, err)
+		c.close()
+		tmuxControls[key] = nil
+		return execTmuxCmd(args...)
+	}
+	return out, err
+}
+
+// openTmuxControl starts a control client and waits for the reply to the
+// attach itself, which tells us the server accepted it.
+func openTmuxControl(socket []string) *tmuxControl {
+	c, err := startTmuxControl(socket)
+	if err != nil {
+		dbg("tmux: control mode for %s unavailable (%v); using exec", tmuxServer{args: socket}, err)
+		return nil
+	}
+	if _, err := c.reply(); err != nil {
+		dbg("tmux: control mode for %s unavailable (%v); using exec", tmuxServer{args: socket}, err)
+		c.close()
+		return nil
+	}
+	return c
+}
+
+// closeTmuxControl detaches every control client.
+func closeTmuxControl() {
+	tmuxControlMu.Lock()
+	defer tmuxControlMu.Unlock()
+	for key, c := range tmuxControls {
+		if c != nil {
+			c.close()
+		}
+		delete(tmuxControls, key)
+	}
+}
@@ -0,0 +1,222 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeTmuxControl stands in for tmux -C: it unquotes each command line,
+// answers it with handler and wraps the result in a %begin/%end block.
+func fakeTmuxControl(t *testing.T, handler runTmuxCmdFunc, started *int) {
+	t.Helper()
+	originalStart := startTmuxControl
+	t.Cleanup(func() {
+		closeTmuxControl()
+		startTmuxControl = originalStart
+	})
+	startTmuxControl = func(socket []string) (*tmuxControl, error) {
+		*started++
+		inR, inW := io.Pipe()
+		outR, outW := io.Pipe()
+		go func() {
+			defer func() { _ = outW.Close() }()
+			n := 100
+			fmt.Fprintf(outW, "%%begin 1 %d 0\n%%end 1 %d 0\n%%session-changed $0 main\n", n, n)
+			sc := bufio.NewScanner(inR)
+			for sc.Scan() {
+				n++
+				args := unquoteTmux(sc.Text())
+				out, err := handler(append(append([]string(nil), socket...), args...)...)
+				fmt.Fprintf(outW, "%%begin 1 %d 1\n", n)
+				end := "%end"
+				if err != nil {
+					out, end = err.Error(), "%error"
+				}
+				if out != "" {
+					fmt.Fprintln(outW, out)
+				}
+				fmt.Fprintf(outW, "%s 1 %d 1\n%%window-pane-changed @0 %%0\n", end, n)
+			}
+		}()
+		return newTmuxControl(inW, outR, func() error { return inR.Close() }), nil
+	}
+}
+
+// unquoteTmux undoes tmuxQuote.
+func unquoteTmux(line string) []string {
+	var args []string
+	var cur strings.Builder
+	in, esc := false, false
+	for _, r := range line {
+		switch {
+		case esc:
+			cur.WriteRune(r)
+			esc = false
+		case r == '\\':
+			esc = true
+		case r == '"' && in:
+			args = append(args, cur.String())
+			cur.Reset()
+			in = false
+		case r == '"':
+			in = true
+		case in:
+			cur.WriteRune(r)
+		}
+	}
+	return args
+}
+
+func TestTmuxQuote(t *testing.T) {
+	args := []string{"display", "-p", `#{pane_id} "$HOME" \n`}
+	line := tmuxQuote(args)
+	if line != `"display" "-p" "#{pane_id} \"\$HOME\" \\n"` {
+		t.Errorf("unexpected quoting: %s", line)
+	}
+	if got := unquoteTmux(line); strings.Join(got, "|") != strings.Join(args, "|") {
+		t.Errorf("expected %q back, got %q", args, got)
+	}
+}
+
+func TestTmuxControl(t *testing.T) {
+	t.Setenv("TTYHOP_TMUX_CONTROL", "")
+	// Only the server ttyhop runs in gets a control client.
+	t.Setenv("TMUX", filepath.Join(t.TempDir(), "default")+",21,0")
+	t.Setenv("TMUX_PANE", "%0")
+	originalRunTmux := runTmuxCmd
+	defer func() { runTmuxCmd = originalRunTmux }()
+	runTmuxCmd = defaultRunTmuxCmd
+
+	t.Run("SelectEdgePaneOverOneConnection", func(t *testing.T) {
+		// The mock from TestTmuxSelectEdgePane, served over control mode.
+		var selectedPane string
+		var started int
+		fakeTmuxControl(t, func(args ...string) (string, error) {
+			cmd := strings.Join(args, " ")
+			switch {
+			case strings.HasPrefix(cmd, "list-clients"):
+				return "/dev/ttys001 1 1627840934", nil
+			case strings.HasPrefix(cmd, "display -p -t /dev/ttys001"):
+				return "@1", nil
+			case strings.HasPrefix(cmd, "list-panes -t @1"):
+				return "%1 1 0\n%2 0 0\n%3 0 1", nil
+			case strings.HasPrefix(cmd, "select-pane -t"):
+				selectedPane = args[2]
+				return "", nil
+			}
+			return "", errors.New("unexpected command: " + cmd)
+		}, &started)
+		t.Setenv("TMUX_TMPDIR", t.TempDir())
+
+		tmuxSelectEdgePane(DirRight, nil, hopOptions{waitMs: 50, zoom: zoomEdge})
+		if selectedPane != "%1" {
+			t.Errorf("expected leftmost pane '%%1' to be selected, but got %q", selectedPane)
+		}
+		if started != 1 {
+			t.Errorf("expected one control client, started %d", started)
+		}
+	})
+
+	t.Run("CommandErrorKeepsConnection", func(t *testing.T) {
+		var started int
+		fakeTmuxControl(t, func(args ...string) (string, error) {
+			if args[0] == "bogus" {
+				return "", errors.New("unknown command: bogus")
+			}
+			return "%7", nil
+		}, &started)
+		if _, err := runTmuxCmd("bogus"); err == nil || !strings.Contains(err.Error(), "unknown command") {
+			t.Errorf("expected the tmux error, got %v", err)
+		}
+		if out, err := runTmuxCmd("display", "-p", "#{pane_id}"); err != nil || out != "%7" {
+			t.Errorf("expected %%7 over the same connection, got %q, %v", out, err)
+		}
+		if started != 1 {
+			t.Errorf("expected one control client, started %d", started)
+		}
+	})
+
+	t.Run("FallsBackToExec", func(t *testing.T) {
+		originalStart := startTmuxControl
+		defer func() {
+			closeTmuxControl()
+			startTmuxControl = originalStart
+		}()
+		startTmuxControl = func(socket []string) (*tmuxControl, error) {
+			return nil, errors.New("no control mode")
+		}
+		// With no tmux server on the $TMUX socket the exec fails too, but
+		// it must be tried, and only once per command.
+		if _, err := runTmuxCmd("list-clients"); err == nil {
+			t.Error("expected exec against a missing server to fail")
+		}
+		if c, seen := tmuxControls[""]; !seen || c != nil {
+			t.Error("expected control mode to be marked unavailable for the server")
+		}
+	})
+
+	t.Run("OtherServersUseExec", func(t *testing.T) {
+		var started int
+		fakeTmuxControl(t, func(args ...string) (string, error) { return "", nil }, &started)
+		sock := filepath.Join(t.TempDir(), "none")
+		if _, err := runTmuxCmd("-S", sock, "list-clients"); err == nil {
+			t.Error("expected exec against a missing server to fail")
+		}
+		t.Setenv("TMUX", "")
+		if _, err := runTmuxCmd("list-clients"); err == nil {
+			t.Error("expected exec against a missing server to fail")
+		}
+		if started != 0 {
+			t.Errorf("expected no control client outside the $TMUX server, started %d", started)
+		}
+	})
+}
+
+// TestTmuxControlReal compares control mode against exec on a throwaway
+// tmux server, standing in for the one in $TMUX.
+func TestTmuxControlReal(t *testing.T) {
+	if _, err := exec.LookPath("tmux"); err != nil {
+		t.Skip("tmux not installed")
+	}
+	t.Setenv("TMUX", "")
+	t.Setenv("TTYHOP_TMUX_CONTROL", "")
+	sock := filepath.Join(t.TempDir(), "s")
+	if _, err := execTmuxCmd("-S", sock, "-f", "/dev/null", "new-session", "-d", "-x", "80", "-y", "24"); err != nil {
+		t.Skipf("cannot start tmux: %v", err)
+	}
+	defer func() { _, _ = execTmuxCmd("-S", sock, "kill-server") }()
+	defer closeTmuxControl()
+	t.Setenv("TMUX", sock+",1,0")
+	t.Setenv("TMUX_PANE", "")
+
+	format := "#{pane_id} #{pane_at_left} #{window_id}"
+	want, _ := execTmuxCmd("-S", sock, "display", "-p", "-t", "$0", format)
+	got, err := defaultRunTmuxCmd("display", "-p", "-t", "$0", format)
+	if err != nil || got != want {
+		t.Errorf("expected %q from control mode, got %q (%v)", want, got, err)
+	}
+	if tmuxControls[""] == nil {
+		t.Fatal("expected a control connection")
+	}
+	if _, err := defaultRunTmuxCmd("no-such-command"); err == nil {
+		t.Error("expected an error for an unknown command")
+	}
+
+	// The control client is listed, but never as a landing candidate.
+	if out, _ := execTmuxCmd("-S", sock, "list-clients", "-F", "#{client_control_mode}"); out != "1" {
+		t.Errorf("expected one control client, got %q", out)
+	}
+	clients, err := tmuxServer{}.clients()
+	if err != nil || len(clients) != 0 {
+		t.Errorf("expected no tty clients, got %+v (%v)", clients, err)
+	}
+}
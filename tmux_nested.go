@@ -0,0 +1,189 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package main
+
+import (
+	"bytes"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// maxTmuxNesting bounds how deep tmux-in-tmux detection looks.
+const maxTmuxNesting = 4
+
+// ---------- process inspection ----------
+
+type runPsCmdFunc func(args ...string) (string, error)
+
+var runPsCmd runPsCmdFunc = defaultRunPsCmd
+
+func defaultRunPsCmd(args ...string) (string, error) {
+	cmd := exec.Command("ps", args...)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	err := cmd.Run()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out.String()), nil
+}
+
+// fgProcess is a process in a tty's foreground process group.
+type fgProcess struct {
+	pid  string
+	args []string
+}
+
+// foregroundProcesses lists the foreground process group on tty, in the
+// order ps prints it.
+func foregroundProcesses(tty string) []fgProcess {
+	out, err := runPsCmd("-t", strings.TrimPrefix(tty, "/dev/"), "-o", "pid=,pgid=,tpgid=,args=")
+	if err != nil {
+		return nil
+	}
+	var procs []fgProcess
+	for _, ln := range strings.Split(out, "\n") {
+		f := strings.Fields(ln)
+		if len(f) >= 4 && f[1] == f[2] {
+			procs = append(procs, fgProcess{pid: f[0], args: f[3:]})
+		}
+	}
+	return procs
+}
+
+// foregroundArgs returns the command line of the foreground process on tty.
+func foregroundArgs(tty string) ([]string, bool) {
+	procs := foregroundProcesses(tty)
+	if len(procs) == 0 {
+		return nil, false
+	}
+	return procs[0].args, true
+}
+
+// tmuxClientServer works out which server a tmux client command line talks
+// to from its -L/-S flags. A client without them uses the default socket,
+// which is addressed explicitly because our own $TMUX may point elsewhere.
+func tmuxClientServer(args []string) (tmuxServer, bool) {
+	if len(args) == 0 || !strings.HasPrefix(filepath.Base(args[0]), "tmux") {
+		return tmuxServer{}, false
+	}
+	for i := 1; i < len(args); i++ {
+		a := args[i]
+		switch {
+		case (a == "-L" || a == "-S") && i+1 < len(args):
+			return tmuxServer{args: []string{a, args[i+1]}}, true
+		case strings.HasPrefix(a, "-L") && len(a) > 2, strings.HasPrefix(a, "-S") && len(a) > 2:
+			return tmuxServer{args: []string{a[:2], a[2:]}}, true
+		case !strings.HasPrefix(a, "-"):
+			// First non-flag word is the tmux command; flags after it aren't ours.
+			return tmuxServer{args: []string{"-S", filepath.Join(tmuxSocketDir(), "default")}}, true
+		}
+	}
+	return tmuxServer{args: []string{"-S", filepath.Join(tmuxSocketDir(), "default")}}, true
+}
+
+// ---------- nesting ----------
+
+// innerTmux reports the tmux server running inside pane (a pane id, or ""
+// for the level's current pane), addressed through the client on the
+// pane's tty. Only local servers are reachable; a tmux behind ssh is not.
+func innerTmux(server tmuxServer, pane string, outer tmuxLevel) (tmuxLevel, bool) {
+	var out string
+	var err error
+	if pane == "" {
+		out, err = outer.display("#{pane_current_command} #{pane_tty}")
+	} else {
+		out, err = server.run("display", "-p", "-t", pane, "#{pane_current_command} #{pane_tty}")
+	}
+	f := strings.Fields(out)
+	// pane_current_command may be "tmux" or "tmux: client"; the tty is last.
+	if err != nil || len(f) < 2 || !strings.HasPrefix(f[0], "tmux") {
+		return tmuxLevel{}, false
+	}
+	tty := f[len(f)-1]
+	args, ok := foregroundArgs(tty)
+	if !ok {
+		return tmuxLevel{}, false
+	}
+	inner, ok := tmuxClientServer(args)
+	if !ok || inner.socket() == server.socket() {
+		return tmuxLevel{}, false
+	}
+	return tmuxLevel{server: inner, client: tty}, true
+}
+
+// tmuxNesting returns the stack of tmux servers under the active pane,
+// outermost first. The first entry is always the $TMUX server.
+func tmuxNesting() []tmuxLevel {
+	return tmuxNestingFrom(tmuxLevel{})
+}
+
+// tmuxNestingFrom returns the stack of tmux servers under the current pane
+// of top, outermost (top itself) first.
+func tmuxNestingFrom(top tmuxLevel) []tmuxLevel {
+	levels := []tmuxLevel{top}
+	for len(levels) < maxTmuxNesting {
+		last := levels[len(levels)-1]
+		inner, ok := innerTmux(last.server, "", last)
+		if !ok {
+			break
+		}
+		dbg("tmux: nested server %s in client %s", inner.server, inner.client)
+		levels = append(levels, inner)
+	}
+	return levels
+}
+
+// tmuxLandNested follows a landing on pane into any tmux running inside it,
+// selecting the matching edge pane of each nested server in turn. It
+// returns the innermost level landed in and its pane ("" for the level's
+// current pane).
+func tmuxLandNested(server tmuxServer, pane string, dir Direction, hint *edgeHint, zoom zoomPolicy) (tmuxLevel, string) {
+	for depth := 1; depth < maxTmuxNesting; depth++ {
+		inner, ok := innerTmux(server, pane, tmuxLevel{server: server})
+		if !ok {
+			break
+		}
+		// The nested window fills the outer pane, so line up against that.
+		if hint != nil {
+			if out, err := server.run("display", "-p", "-t", pane, tmuxSpanFormat(dir)); err == nil {
+				if lo, hi, ok := spanIn(dir, hint.dest, strings.Fields(out)); ok {
+					dest := hint.dest
+					if dir.Vertical() {
+						dest.X, dest.W = lo, hi-lo
+					} else {
+						dest.Y, dest.H = lo, hi-lo
+					}
+					hint = &edgeHint{lo: hint.lo, hi: hint.hi, dest: dest}
+				}
+			}
+		}
+		target, err := tmuxLand(inner, dir, hint, zoom)
+		if err != nil || target == "" {
+			return inner, ""
+		}
+		server, pane = inner.server, target
+	}
+	return tmuxLevel{server: server}, pane
+}
+
+// tmuxLandedLevel waits, like tmuxSelectEdgePane, for a tmux client other
+// than origin (the tty of the client a hop left) to take focus, and
+// returns the innermost level under it.
+func tmuxLandedLevel(opts hopOptions, origin string) (tmuxLevel, bool) {
+	waitMs := edgeWaitMs(opts.waitMs)
+	for i := 0; i < waitMs/pollIntervalMs; i++ {
+		time.Sleep(pollIntervalMs * time.Millisecond)
+
+		server, tty, err := tmuxFocusedClient(opts.tmuxSockets)
+		if err != nil || strings.TrimSpace(tty) == "" || tty == origin {
+			continue
+		}
+		levels := tmuxNestingFrom(tmuxLevel{server: server, client: tty})
+		return levels[len(levels)-1], true
+	}
+	return tmuxLevel{}, false
+}
@@ -0,0 +1,177 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/leejonesio/ttyhop/internal/neighbor"
+)
+
+func TestTmuxClientServer(t *testing.T) {
+	t.Setenv("TMUX_TMPDIR", "/run/t")
+	tests := []struct {
+		args []string
+		want string
+		ok   bool
+	}{
+		{[]string{"tmux", "-L", "inner", "attach"}, "-L inner", true},
+		{[]string{"tmux", "-Linner"}, "-L inner", true},
+		{[]string{"/usr/bin/tmux", "-S", "/tmp/s", "new"}, "-S /tmp/s", true},
+		{[]string{"tmux", "-2", "new", "-L", "ignored"}, "-S " + tmuxSocketDir() + "/default", true},
+		{[]string{"tmux"}, "-S " + tmuxSocketDir() + "/default", true},
+		{[]string{"vim", "-L", "x"}, "", false},
+	}
+	for _, tt := range tests {
+		got, ok := tmuxClientServer(tt.args)
+		if ok != tt.ok || (ok && got.String() != tt.want) {
+			t.Errorf("tmuxClientServer(%q) = %q, %v; want %q, %v", tt.args, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+// mockNested serves an outer server whose pane %1 runs a client of the
+// "inner" server on /dev/pts/2. Each server's active pane is tracked in
+// active, and every select-pane is recorded in calls.
+func mockNested(t *testing.T, active map[string]string, edges map[string]string, calls *[]string) {
+	t.Helper()
+	t.Setenv("TMUX", "/tmp/tmux-1000/default,21,0")
+	originalRunTmux, originalRunPs := runTmuxCmd, runPsCmd
+	t.Cleanup(func() { runTmuxCmd, runPsCmd = originalRunTmux, originalRunPs })
+
+	runPsCmd = func(args ...string) (string, error) {
+		if strings.Join(args, " ") != "-t pts/2 -o pid=,pgid=,tpgid=,args=" {
+			return "", nil
+		}
+		return "100 100 200 -zsh\n200 200 200 tmux -L inner attach", nil
+	}
+	runTmuxCmd = func(args ...string) (string, error) {
+		server := "outer"
+		if len(args) > 1 && args[0] == "-L" {
+			server, args = args[1], args[2:]
+		}
+		cmd := strings.Join(args, " ")
+		switch {
+		case strings.HasPrefix(cmd, "select-pane"):
+			*calls = append(*calls, server+": "+cmd)
+			active[server] = args[len(args)-1]
+			if args[1] != "-t" {
+				active[server] = edges[server+" "+args[1]]
+			}
+		case strings.HasSuffix(cmd, "#{pane_current_command} #{pane_tty}"):
+			if server == "outer" && (active["outer"] == "%1" || strings.Contains(cmd, "-t %1")) {
+				return "tmux /dev/pts/2", nil
+			}
+			return "zsh /dev/pts/9", nil
+		case strings.HasSuffix(cmd, "#{pane_id}"):
+			return active[server], nil
+		case strings.HasSuffix(cmd, "#{window_id} #{window_zoomed_flag}"):
+			return "@" + server + " 0", nil
+		case strings.HasPrefix(cmd, "list-clients"):
+			return "/dev/ttys001 1 1", nil
+		case strings.HasPrefix(cmd, "list-panes -t @inner"):
+			return "%7 1 0\n%8 0 1", nil
+		case strings.HasPrefix(cmd, "list-panes -t @outer"):
+			return "%0 1 0\n%1 0 1", nil
+		case strings.Contains(cmd, "pane_at_"):
+			return edges[server+" "+active[server]+" "+args[len(args)-1]], nil
+		}
+		return "", nil
+	}
+}
+
+func TestTmuxNestedMove(t *testing.T) {
+	t.Run("InnerFirst", func(t *testing.T) {
+		var calls []string
+		active := map[string]string{"outer": "%1", "inner": "%7"}
+		edges := map[string]string{
+			"inner %7 #{pane_at_right}": "0",
+			"inner -R":                  "%8",
+		}
+		mockNested(t, active, edges, &calls)
+		if !tmuxTryPaneMove(DirRight, zoomEdge) {
+			t.Fatal("expected the inner server to move")
+		}
+		if strings.Join(calls, "; ") != "inner: select-pane -R -t %7" {
+			t.Errorf("expected only the inner server to move, got %q", calls)
+		}
+	})
+
+	t.Run("BubblesOut", func(t *testing.T) {
+		var calls []string
+		active := map[string]string{"outer": "%1", "inner": "%7"}
+		edges := map[string]string{
+			"inner %7 #{pane_at_left}": "1",
+			"outer %1 #{pane_at_left}": "0",
+			"outer -L":                 "%0",
+		}
+		mockNested(t, active, edges, &calls)
+		if !tmuxTryPaneMove(DirLeft, zoomEdge) {
+			t.Fatal("expected the outer server to move")
+		}
+		if strings.Join(calls, "; ") != "outer: select-pane -L" {
+			t.Errorf("expected the outer server to move, got %q", calls)
+		}
+	})
+
+	t.Run("AllAtEdge", func(t *testing.T) {
+		var calls []string
+		active := map[string]string{"outer": "%1", "inner": "%8"}
+		edges := map[string]string{
+			"inner %8 #{pane_at_right}": "1",
+			"outer %1 #{pane_at_right}": "1",
+		}
+		mockNested(t, active, edges, &calls)
+		if tmuxTryPaneMove(DirRight, zoomEdge) {
+			t.Error("expected no move when every server is at its edge")
+		}
+		if len(calls) != 0 {
+			t.Errorf("expected no select-pane, got %q", calls)
+		}
+	})
+}
+
+func TestTmuxLandNested(t *testing.T) {
+	var calls []string
+	active := map[string]string{"outer": "%0", "inner": "%7"}
+	mockNested(t, active, nil, &calls)
+
+	// Hopping left lands on the outer window's rightmost pane, %1, and then
+	// on the rightmost pane of the tmux running inside it.
+	l, pane, ok := tmuxSelectEdgePane(DirLeft, nil, hopOptions{waitMs: 50, zoom: zoomEdge})
+	want := "outer: select-pane -t %1; inner: select-pane -t %8"
+	if strings.Join(calls, "; ") != want {
+		t.Errorf("expected %q, got %q", want, calls)
+	}
+	// Editors land in the inner tmux's pane, not the outer one's.
+	if !ok || l.server.String() != "-L inner" || pane != "%8" {
+		t.Errorf("expected landing in %%8 on -L inner, got %q on %q (%v)", pane, l.server, ok)
+	}
+
+	t.Run("RowAligned", func(t *testing.T) {
+		dest := neighbor.Rect{X: 0, Y: 0, W: 100, H: 100}
+		hint := &edgeHint{lo: 30, hi: 45, dest: dest}
+		originalRunTmux := runTmuxCmd
+		runTmuxCmd = func(args ...string) (string, error) {
+			cmd := strings.Join(args, " ")
+			switch {
+			// The outer pane %1 is the top half of its window...
+			case cmd == "display -p -t %1 #{pane_top} #{pane_height} #{window_height}":
+				return "0 10 20", nil
+			// ...and the inner window has a stacked right column.
+			case strings.HasPrefix(cmd, "-L inner list-panes"):
+				return "%7 0 1 0 5 10\n%8 0 1 5 5 10", nil
+			}
+			return originalRunTmux(args...)
+		}
+		calls = nil
+		tmuxLandNested(tmuxServer{}, "%1", DirLeft, hint, zoomEdge)
+		// The inner window fills the outer pane's 0..50, so %8 is 25..50 and
+		// overlaps the origin 30..45 most.
+		if strings.Join(calls, "; ") != "inner: select-pane -t %8" {
+			t.Errorf("expected the lower inner pane, got %q", calls)
+		}
+	})
+}
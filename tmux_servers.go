@@ -0,0 +1,108 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// tmuxSocketServer addresses a --tmux-socket value: a path if it contains a
+// slash, otherwise a socket name.
+func tmuxSocketServer(s string) tmuxServer {
+	if strings.Contains(s, "/") {
+		return tmuxServer{args: []string{"-S", s}}
+	}
+	return tmuxServer{args: []string{"-L", s}}
+}
+
+// ---------- sockets ----------
+
+// tmuxSocketDir is where tmux keeps its sockets: $TMUX_TMPDIR (or /tmp)
+// plus tmux-UID.
+func tmuxSocketDir() string {
+	base := os.Getenv("TMUX_TMPDIR")
+	if base == "" {
+		base = "/tmp"
+	}
+	return filepath.Join(base, fmt.Sprintf("tmux-%d", os.Getuid()))
+}
+
+// tmuxEnvSocket returns the socket path from $TMUX ("path,pid,session").
+func tmuxEnvSocket() string {
+	path, _, _ := strings.Cut(os.Getenv("TMUX"), ",")
+	return path
+}
+
+// socket resolves the server's socket path.
+func (s tmuxServer) socket() string {
+	for i := 0; i+1 < len(s.args); i++ {
+		switch s.args[i] {
+		case "-S":
+			return s.args[i+1]
+		case "-L":
+			return filepath.Join(tmuxSocketDir(), s.args[i+1])
+		}
+	}
+	if p := tmuxEnvSocket(); p != "" {
+		return p
+	}
+	return filepath.Join(tmuxSocketDir(), "default")
+}
+
+// tmuxServers lists every server a window hop might land in: the one in
+// $TMUX (the zero value), those named by --tmux-socket, then any other
+// socket in tmux's socket directory. Stale sockets just fail to answer.
+func tmuxServers(sockets []string) []tmuxServer {
+	servers := []tmuxServer{{}}
+	seen := map[string]bool{tmuxServer{}.socket(): true}
+	add := func(s tmuxServer) {
+		if p := s.socket(); !seen[p] {
+			seen[p] = true
+			servers = append(servers, s)
+		}
+	}
+	for _, name := range sockets {
+		add(tmuxSocketServer(name))
+	}
+	dir := tmuxSocketDir()
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		if e.Type()&fs.ModeSocket != 0 {
+			add(tmuxServer{args: []string{"-S", filepath.Join(dir, e.Name())}})
+		}
+	}
+	return servers
+}
+
+// tmuxFocusedClient finds the client the user is looking at across every
+// server: an active one if any server reports it, else the one with the
+// most recent activity. It returns an error only when no server answers.
+func tmuxFocusedClient(sockets []string) (tmuxServer, string, error) {
+	var all []tmuxClient
+	var firstErr error
+	answered := false
+	for _, s := range tmuxServers(sockets) {
+		clients, err := s.clients()
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		answered = true
+		all = append(all, clients...)
+	}
+	if !answered {
+		return tmuxServer{}, "", firstErr
+	}
+	c, _ := pickClient(all)
+	if len(c.server.args) > 0 {
+		dbg("tmux: focused client %s is on server %s", c.tty, c.server)
+	}
+	return c.server, c.tty, nil
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package main
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunTmuxSocket(t *testing.T) {
+	withConfig(t, "tmux-socket = work\n")
+	t.Setenv("TTYHOP_TMUX_SOCKET", "")
+
+	h := &fakeHopper{}
+	run(h, []string{"r"})
+	if strings.Join(h.tmuxSockets, ",") != "work" {
+		t.Errorf("expected the config socket, got %q", h.tmuxSockets)
+	}
+	h = &fakeHopper{}
+	run(h, []string{"-L", "work, /tmp/s", "r"})
+	if strings.Join(h.tmuxSockets, ",") != "work,/tmp/s" {
+		t.Errorf("expected both sockets from -L, got %q", h.tmuxSockets)
+	}
+}
+
+func TestTmuxServers(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("TMUX_TMPDIR", tmp)
+	t.Setenv("TMUX", filepath.Join(tmuxSocketDir(), "default")+",21,0")
+
+	dir := tmuxSocketDir()
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"default", "work", "scratch"} {
+		l, err := net.Listen("unix", filepath.Join(dir, name))
+		if err != nil {
+			t.Skipf("cannot create unix socket: %v", err)
+		}
+		defer func() { _ = l.Close() }()
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes"), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, s := range tmuxServers([]string{"work", "/elsewhere/sock"}) {
+		got = append(got, s.String())
+	}
+	// $TMUX first, then --tmux-socket, then the rest of the directory;
+	// "default" and "work" aren't repeated and plain files are skipped.
+	want := []string{"default", "-L work", "-S /elsewhere/sock", "-S " + filepath.Join(dir, "scratch")}
+	if strings.Join(got, "; ") != strings.Join(want, "; ") {
+		t.Errorf("expected servers %q, got %q", want, got)
+	}
+}
+
+func TestTmuxFocusedClient(t *testing.T) {
+	t.Setenv("TMUX_TMPDIR", t.TempDir())
+	t.Setenv("TMUX", "")
+	originalRunTmux := runTmuxCmd
+	defer func() { runTmuxCmd = originalRunTmux }()
+	sockets := []string{"work", "scratch"}
+
+	// mockServers answers list-clients per server, failing for the others.
+	mockServers := func(clients map[string]string, calls *[]string) {
+		runTmuxCmd = func(args ...string) (string, error) {
+			server := "default"
+			if args[0] == "-L" {
+				server, args = args[1], args[2:]
+			}
+			cmd := strings.Join(args, " ")
+			switch {
+			case strings.HasPrefix(cmd, "list-clients"):
+				out, ok := clients[server]
+				if !ok {
+					return "", errors.New("no server running")
+				}
+				return out, nil
+			case strings.HasPrefix(cmd, "display -p -t /dev/ttys002"):
+				return "@3 0", nil
+			case strings.HasPrefix(cmd, "list-panes -t @3"):
+				return "%5 1 0\n%6 0 1", nil
+			case strings.HasPrefix(cmd, "select-pane"):
+				*calls = append(*calls, server+": "+cmd)
+			}
+			return "", nil
+		}
+	}
+
+	t.Run("MostRecentAcrossServers", func(t *testing.T) {
+		mockServers(map[string]string{
+			"work":    "/dev/ttys001 0 1627840930",
+			"scratch": "/dev/ttys002 0 1627840934",
+		}, nil)
+		server, tty, err := tmuxFocusedClient(sockets)
+		if err != nil || tty != "/dev/ttys002" || server.String() != "-L scratch" {
+			t.Errorf("expected /dev/ttys002 on -L scratch, got %q on %q (%v)", tty, server, err)
+		}
+	})
+
+	t.Run("NoServerAnswers", func(t *testing.T) {
+		mockServers(map[string]string{}, nil)
+		if _, _, err := tmuxFocusedClient(sockets); err == nil {
+			t.Error("expected an error when no server answers")
+		}
+	})
+
+	t.Run("LandsOnOwningServer", func(t *testing.T) {
+		var calls []string
+		mockServers(map[string]string{
+			"default": "/dev/ttys000 0 1627840920",
+			"scratch": "/dev/ttys002 0 1627840934",
+		}, &calls)
+		tmuxSelectEdgePane(DirRight, nil, hopOptions{waitMs: 50, zoom: zoomEdge, tmuxSockets: sockets})
+		if strings.Join(calls, "; ") != "scratch: select-pane -t %5" {
+			t.Errorf("expected landing on the scratch server, got %q", calls)
+		}
+	})
+
+	t.Run("LandedLevelWaitsPastOrigin", func(t *testing.T) {
+		// The origin client stays the most recent for a few polls, until
+		// the window hopped to takes focus.
+		polls := 0
+		runTmuxCmd = func(args ...string) (string, error) {
+			cmd := strings.Join(args, " ")
+			switch {
+			case strings.HasPrefix(cmd, "-L work list-clients"):
+				polls++
+				if polls > 2 {
+					return "/dev/ttys001 0 1627840940", nil
+				}
+				return "/dev/ttys001 0 1627840920", nil
+			case strings.HasPrefix(cmd, "-L scratch list-clients"):
+				return "/dev/ttys002 0 1627840930", nil
+			case strings.HasPrefix(cmd, "list-clients"):
+				return "", errors.New("no server running")
+			}
+			return "", nil
+		}
+		l, ok := tmuxLandedLevel(hopOptions{waitMs: 500, tmuxSockets: sockets}, "/dev/ttys002")
+		if !ok || l.client != "/dev/ttys001" || l.server.String() != "-L work" {
+			t.Errorf("expected /dev/ttys001 on -L work, got %q on %q (%v)", l.client, l.server, ok)
+		}
+		if _, ok := tmuxLandedLevel(hopOptions{waitMs: 50, tmuxSockets: sockets}, "/dev/ttys001"); ok {
+			t.Error("expected no landing while the origin keeps focus")
+		}
+	})
+}
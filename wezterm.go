@@ -0,0 +1,324 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/leejonesio/ttyhop/internal/neighbor"
+)
+
+// ---------- WezTerm multiplexer ----------
+
+type runWeztermCmdFunc func(args ...string) (string, error)
+
+// runWeztermCmd runs one "wezterm cli" command; tests swap it for canned
+// output.
+var runWeztermCmd runWeztermCmdFunc = execWeztermCmd
+
+func execWeztermCmd(args ...string) (string, error) {
+	cmd := exec.Command("wezterm", append([]string{"cli"}, args...)...)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err := cmd.Run(); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out.String()), nil
+}
+
+// weztermPane is one entry of "wezterm cli list --format json", sized and
+// placed in cells within its tab.
+type weztermPane struct {
+	WindowID int `json:"window_id"`
+	TabID    int `json:"tab_id"`
+	PaneID   int `json:"pane_id"`
+	Size     struct {
+		Rows int `json:"rows"`
+		Cols int `json:"cols"`
+	} `json:"size"`
+	LeftCol  int  `json:"left_col"`
+	TopRow   int  `json:"top_row"`
+	IsActive bool `json:"is_active"`
+	IsZoomed bool `json:"is_zoomed"`
+}
+
+// weztermClient is one entry of "wezterm cli list-clients --format json".
+type weztermClient struct {
+	FocusedPaneID int `json:"focused_pane_id"`
+	IdleTime      struct {
+		Secs  int64 `json:"secs"`
+		Nanos int64 `json:"nanos"`
+	} `json:"idle_time"`
+}
+
+// inWezterm reports whether ttyhop runs in a WezTerm pane.
+func inWezterm() bool {
+	return os.Getenv("WEZTERM_PANE") != ""
+}
+
+func weztermPanes() ([]weztermPane, error) {
+	out, err := runWeztermCmd("list", "--format", "json")
+	if err != nil {
+		return nil, err
+	}
+	var panes []weztermPane
+	if err := json.Unmarshal([]byte(out), &panes); err != nil {
+		return nil, err
+	}
+	return panes, nil
+}
+
+// weztermActive returns the panes of the tab holding pane id and that
+// tab's active pane.
+func weztermActive(panes []weztermPane, id int) (tab []weztermPane, active weztermPane, ok bool) {
+	tabID := -1
+	for _, p := range panes {
+		if p.PaneID == id {
+			tabID = p.TabID
+		}
+	}
+	for _, p := range panes {
+		if p.TabID != tabID {
+			continue
+		}
+		tab = append(tab, p)
+		if p.IsActive {
+			active, ok = p, true
+		}
+	}
+	return tab, active, ok
+}
+
+// weztermTabSize returns the width and height of the tab holding panes, in
+// cells.
+func weztermTabSize(tab []weztermPane) (width, height int) {
+	for _, o := range tab {
+		width = max(width, o.LeftCol+o.Size.Cols)
+		height = max(height, o.TopRow+o.Size.Rows)
+	}
+	return width, height
+}
+
+// weztermSpan maps pane p onto r, the rect of its window, across the axis
+// of travel.
+func weztermSpan(tab []weztermPane, p weztermPane, dir Direction, r neighbor.Rect) (lo, hi float64, ok bool) {
+	width, height := weztermTabSize(tab)
+	if dir.Vertical() {
+		return spanAt(dir, r, float64(p.LeftCol), float64(p.Size.Cols), float64(width))
+	}
+	return spanAt(dir, r, float64(p.TopRow), float64(p.Size.Rows), float64(height))
+}
+
+// weztermAtEdge reports whether pane p touches the side of its tab in
+// direction dir, like tmux's #{pane_at_*}.
+func weztermAtEdge(tab []weztermPane, p weztermPane, dir Direction) bool {
+	width, height := weztermTabSize(tab)
+	switch dir {
+	case DirLeft:
+		return p.LeftCol == 0
+	case DirRight:
+		return p.LeftCol+p.Size.Cols >= width
+	case DirUp:
+		return p.TopRow == 0
+	}
+	return p.TopRow+p.Size.Rows >= height
+}
+
+// weztermDirection is the activate-pane-direction argument for dir.
+func weztermDirection(dir Direction) string {
+	return map[Direction]string{DirLeft: "Left", DirRight: "Right", DirUp: "Up", DirDown: "Down"}[dir]
+}
+
+// weztermTryMove moves to the WezTerm pane next to the active one in dir;
+// return true if moved. As with tmux, the pane's position in its tab tells
+// whether it is at the edge, and a zoomed pane always is.
+func weztermTryMove(dir Direction) bool {
+	if !inWezterm() {
+		return false
+	}
+	self, _ := strconv.Atoi(os.Getenv("WEZTERM_PANE"))
+	panes, err := weztermPanes()
+	if err != nil {
+		dbg("wezterm: cannot list panes: %v", err)
+		return false
+	}
+	tab, old, ok := weztermActive(panes, self)
+	if !ok {
+		return false
+	}
+	if old.IsZoomed {
+		dbg("wezterm: pane is zoomed; treating as edge")
+		return false
+	}
+	if weztermAtEdge(tab, old, dir) {
+		return false
+	}
+	if _, err := runWeztermCmd("activate-pane-direction", "--pane-id", strconv.Itoa(old.PaneID), weztermDirection(dir)); err != nil {
+		return false
+	}
+
+	// Verify it actually changed pane
+	panes, err = weztermPanes()
+	if err != nil {
+		return false
+	}
+	_, cur, ok := weztermActive(panes, self)
+	if !ok || cur.PaneID == old.PaneID {
+		return false
+	}
+	dbg("wezterm: pane move %s (%d -> %d)", dir, old.PaneID, cur.PaneID)
+	return true
+}
+
+// weztermFocusedPane returns the pane the most recently active WezTerm
+// client has focused.
+func weztermFocusedPane() (int, bool) {
+	out, err := runWeztermCmd("list-clients", "--format", "json")
+	if err != nil {
+		return 0, false
+	}
+	var clients []weztermClient
+	if err := json.Unmarshal([]byte(out), &clients); err != nil || len(clients) == 0 {
+		return 0, false
+	}
+	best := clients[0]
+	for _, c := range clients[1:] {
+		if c.IdleTime.Secs < best.IdleTime.Secs || (c.IdleTime.Secs == best.IdleTime.Secs && c.IdleTime.Nanos < best.IdleTime.Nanos) {
+			best = c
+		}
+	}
+	return best.FocusedPaneID, true
+}
+
+// weztermFocusedTab returns the tab of the focused WezTerm pane, or -1
+// outside WezTerm. Hops record it so landing can tell when focus has moved.
+func weztermFocusedTab() int {
+	if !inWezterm() {
+		return -1
+	}
+	id, ok := weztermFocusedPane()
+	if !ok {
+		return -1
+	}
+	panes, err := weztermPanes()
+	if err != nil {
+		return -1
+	}
+	for _, p := range panes {
+		if p.PaneID == id {
+			return p.TabID
+		}
+	}
+	return -1
+}
+
+// weztermOriginHint describes the active WezTerm pane's position on screen
+// before a hop from the window at cur to the window at dest. Outside
+// WezTerm the whole window is the origin.
+func weztermOriginHint(dir Direction, cur, dest neighbor.Rect) *edgeHint {
+	lo, hi := cur.Y, cur.Y+cur.H
+	if dir.Vertical() {
+		lo, hi = cur.X, cur.X+cur.W
+	}
+	if inWezterm() {
+		self, _ := strconv.Atoi(os.Getenv("WEZTERM_PANE"))
+		if panes, err := weztermPanes(); err == nil {
+			if tab, active, ok := weztermActive(panes, self); ok {
+				if pLo, pHi, ok := weztermSpan(tab, active, dir, cur); ok {
+					lo, hi = pLo, pHi
+				}
+			}
+		}
+	}
+	return &edgeHint{lo: lo, hi: hi, dest: dest}
+}
+
+// weztermEdgePane returns the pane of tab a hop in dir should land on: the
+// LEFTMOST when moving right, the TOPMOST when moving down, and so on. When
+// several panes share that edge, hint picks the one that best overlaps the
+// origin pane; without a hint the active pane is kept when it is on the
+// edge, else the first is used.
+func weztermEdgePane(tab []weztermPane, active weztermPane, dir Direction, hint *edgeHint) weztermPane {
+	if hint == nil && weztermAtEdge(tab, active, dir.Opposite()) {
+		return active
+	}
+	var edge []weztermPane
+	for _, p := range tab {
+		if !weztermAtEdge(tab, p, dir.Opposite()) {
+			continue
+		}
+		if hint == nil {
+			return p
+		}
+		edge = append(edge, p)
+	}
+	i := hint.pick(len(edge), func(i int) (float64, float64, bool) { return weztermSpan(tab, edge[i], dir, hint.dest) })
+	if i < 0 {
+		return active
+	}
+	return edge[i]
+}
+
+// weztermSelectEdgePane activates the edge pane in the WezTerm tab a hop
+// just focused (see weztermEdgePane). fromTab is the tab the hop started
+// in; it waits up to waitMs for focus to reach another tab.
+func weztermSelectEdgePane(dir Direction, waitMs int, fromTab int, hint *edgeHint) {
+	if !inWezterm() {
+		return
+	}
+	waitMs = edgeWaitMs(waitMs)
+	for i := 0; i < waitMs/pollIntervalMs; i++ {
+		time.Sleep(pollIntervalMs * time.Millisecond)
+
+		id, ok := weztermFocusedPane()
+		if !ok {
+			return
+		}
+		panes, err := weztermPanes()
+		if err != nil {
+			return
+		}
+		tab, active, ok := weztermActive(panes, id)
+		if !ok || active.TabID == fromTab {
+			continue
+		}
+		if active.IsZoomed {
+			dbg("wezterm: destination pane is zoomed; staying on it")
+			return
+		}
+		target := weztermEdgePane(tab, active, dir, hint)
+		if target.PaneID != active.PaneID {
+			_, _ = runWeztermCmd("activate-pane", "--pane-id", strconv.Itoa(target.PaneID))
+		}
+		dbg("wezterm: landed on %s edge pane %d", dir.Opposite(), target.PaneID)
+		return
+	}
+}
+
+// weztermNavigator moves between WezTerm panes. Landing waits for focus to
+// leave the tab the hop started in, and lines the pane up with the one it
+// left.
+type weztermNavigator struct {
+	waitMs int
+	from   int
+	hint   *edgeHint
+}
+
+func (n *weztermNavigator) Try(dir Direction) bool { return weztermTryMove(dir) }
+
+func (n *weztermNavigator) recordOrigin(dir Direction, cur neighbor.Rect, dest neighbor.Candidate) {
+	n.from = weztermFocusedTab()
+	n.hint = weztermOriginHint(dir, cur, dest.Rect)
+}
+
+func (n *weztermNavigator) LandAtEdge(dir Direction) {
+	weztermSelectEdgePane(dir, n.waitMs, n.from, n.hint)
+}
@@ -0,0 +1,231 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/leejonesio/ttyhop/internal/neighbor"
+)
+
+// fakeWezterm answers wezterm cli commands from testdata/wezterm_list.json:
+// window 0's tab 0 holds pane 0 beside pane 1 stacked over pane 2, and
+// window 1's tab 1 holds panes 3 | 4.
+type fakeWezterm struct {
+	panes   []weztermPane
+	focused int
+	calls   [][]string
+}
+
+func newFakeWezterm(t *testing.T, edit func([]weztermPane)) *fakeWezterm {
+	t.Helper()
+	b, err := os.ReadFile("testdata/wezterm_list.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fakeWezterm{}
+	if err := json.Unmarshal(b, &f.panes); err != nil {
+		t.Fatal(err)
+	}
+	if edit != nil {
+		edit(f.panes)
+	}
+
+	t.Setenv("WEZTERM_PANE", "0")
+	original := runWeztermCmd
+	t.Cleanup(func() { runWeztermCmd = original })
+	runWeztermCmd = f.run
+	return f
+}
+
+func (f *fakeWezterm) run(args ...string) (string, error) {
+	f.calls = append(f.calls, args)
+	switch args[0] {
+	case "list":
+		b, err := json.Marshal(f.panes)
+		return string(b), err
+	case "list-clients":
+		return fmt.Sprintf(`[{"username": "me", "hostname": "host", "pid": 42, "workspace": "default",
+			"connection_elapsed": {"secs": 100, "nanos": 0}, "idle_time": {"secs": 0, "nanos": 5000},
+			"focused_pane_id": %d}]`, f.focused), nil
+	case "activate-pane-direction":
+		id, _ := strconv.Atoi(args[2])
+		p := f.pane(id)
+		for _, o := range f.panes {
+			if o.TabID != p.TabID || o.PaneID == p.PaneID {
+				continue
+			}
+			var next bool
+			switch args[3] {
+			case "Left":
+				next = o.LeftCol+o.Size.Cols+1 == p.LeftCol && span(o.TopRow, o.Size.Rows, p.TopRow, p.Size.Rows) > 0
+			case "Right":
+				next = p.LeftCol+p.Size.Cols+1 == o.LeftCol && span(o.TopRow, o.Size.Rows, p.TopRow, p.Size.Rows) > 0
+			case "Up":
+				next = o.TopRow+o.Size.Rows+1 == p.TopRow && span(o.LeftCol, o.Size.Cols, p.LeftCol, p.Size.Cols) > 0
+			case "Down":
+				next = p.TopRow+p.Size.Rows+1 == o.TopRow && span(o.LeftCol, o.Size.Cols, p.LeftCol, p.Size.Cols) > 0
+			}
+			if next {
+				f.activate(o.PaneID)
+				break
+			}
+		}
+		return "", nil
+	case "activate-pane":
+		id, _ := strconv.Atoi(args[2])
+		f.activate(id)
+		return "", nil
+	}
+	return "", fmt.Errorf("unknown command %v", args)
+}
+
+func (f *fakeWezterm) pane(id int) weztermPane {
+	for _, p := range f.panes {
+		if p.PaneID == id {
+			return p
+		}
+	}
+	return weztermPane{}
+}
+
+func (f *fakeWezterm) activate(id int) {
+	tab := f.pane(id).TabID
+	for i := range f.panes {
+		if f.panes[i].TabID == tab {
+			f.panes[i].IsActive = f.panes[i].PaneID == id
+		}
+	}
+	f.focused = id
+}
+
+func (f *fakeWezterm) active(tab int) int {
+	for _, p := range f.panes {
+		if p.TabID == tab && p.IsActive {
+			return p.PaneID
+		}
+	}
+	return -1
+}
+
+func TestWeztermTryMove(t *testing.T) {
+	tests := []struct {
+		name   string
+		active int
+		dir    Direction
+		moved  bool
+		want   int
+	}{
+		{"Right", 0, DirRight, true, 1},
+		{"AtLeftEdge", 0, DirLeft, false, 0},
+		{"Down", 1, DirDown, true, 2},
+		{"AtRightEdge", 2, DirRight, false, 2},
+		{"Left", 2, DirLeft, true, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeWezterm(t, nil)
+			f.activate(tt.active)
+			if moved := weztermTryMove(tt.dir); moved != tt.moved {
+				t.Errorf("weztermTryMove(%s) = %v, want %v", tt.dir, moved, tt.moved)
+			}
+			if got := f.active(0); got != tt.want {
+				t.Errorf("active pane %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("ZoomedIsEdge", func(t *testing.T) {
+		f := newFakeWezterm(t, func(panes []weztermPane) { panes[0].IsZoomed = true })
+		if weztermTryMove(DirRight) {
+			t.Error("expected a zoomed pane to be the edge")
+		}
+		if len(f.calls) != 1 {
+			t.Errorf("expected only a list, got %v", f.calls)
+		}
+	})
+
+	t.Run("OutsideWezterm", func(t *testing.T) {
+		f := newFakeWezterm(t, nil)
+		t.Setenv("WEZTERM_PANE", "")
+		if weztermTryMove(DirRight) || len(f.calls) != 0 {
+			t.Errorf("expected no wezterm calls outside WezTerm, got %v", f.calls)
+		}
+	})
+}
+
+func TestWeztermSelectEdgePane(t *testing.T) {
+	tests := []struct {
+		name    string
+		focused int
+		dir     Direction
+		want    int
+	}{
+		{"MovingRightLandsLeftmost", 4, DirRight, 3},
+		{"MovingLeftKeepsActiveOnEdge", 4, DirLeft, 4},
+		{"FocusNeverMoved", 0, DirRight, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeWezterm(t, nil)
+			from := weztermFocusedTab()
+			f.focused = tt.focused
+			weztermSelectEdgePane(tt.dir, 2*pollIntervalMs, from, nil)
+			if got := f.focused; got != tt.want {
+				t.Errorf("focused pane %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("LandsOnStackedColumn", func(t *testing.T) {
+		f := newFakeWezterm(t, nil)
+		weztermSelectEdgePane(DirLeft, 2*pollIntervalMs, 1, nil)
+		if got := f.active(0); got != 1 {
+			t.Errorf("active pane %d, want 1 (top of the right column)", got)
+		}
+	})
+
+	t.Run("AlignsWithOrigin", func(t *testing.T) {
+		// The hop left pane 4, in the lower part of its window; pane 2 is
+		// 25..48 of tab 0's 48 rows, so it overlaps 60..90 of 0..100 most.
+		f := newFakeWezterm(t, nil)
+		hint := &edgeHint{lo: 60, hi: 90, dest: neighbor.Rect{W: 100, H: 100}}
+		weztermSelectEdgePane(DirLeft, 2*pollIntervalMs, 1, hint)
+		if got := f.active(0); got != 2 {
+			t.Errorf("active pane %d, want 2 (bottom of the right column)", got)
+		}
+	})
+
+	t.Run("OffsetDestinationLandsClosest", func(t *testing.T) {
+		// The destination window sits wholly below the origin, so no pane
+		// overlaps it; pane 1, the top of the right column, is closest.
+		f := newFakeWezterm(t, nil)
+		hint := &edgeHint{lo: 0, hi: 50, dest: neighbor.Rect{Y: 500, W: 100, H: 100}}
+		weztermSelectEdgePane(DirLeft, 2*pollIntervalMs, 1, hint)
+		if got := f.active(0); got != 1 {
+			t.Errorf("active pane %d, want 1 (top of the right column)", got)
+		}
+	})
+}
+
+func TestWeztermOriginHint(t *testing.T) {
+	// Pane 1 is the top half of the right column: rows 0..24 of 48.
+	newFakeWezterm(t, func(panes []weztermPane) {
+		panes[0].IsActive, panes[1].IsActive = false, true
+	})
+	cur, dest := neighbor.Rect{X: 0, Y: 100, W: 800, H: 960}, neighbor.Rect{X: 800, Y: 0, W: 800, H: 960}
+	hint := weztermOriginHint(DirRight, cur, dest)
+	if hint.lo != 100 || hint.hi != 580 || hint.dest != dest {
+		t.Errorf("got hint %+v, want 100..580 in %+v", *hint, dest)
+	}
+
+	t.Setenv("WEZTERM_PANE", "")
+	if hint := weztermOriginHint(DirDown, cur, dest); hint.lo != 0 || hint.hi != 800 {
+		t.Errorf("expected the whole window outside WezTerm, got %v..%v", hint.lo, hint.hi)
+	}
+}
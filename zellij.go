@@ -0,0 +1,148 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/leejonesio/ttyhop/internal/neighbor"
+)
+
+// ---------- zellij ----------
+//
+// zellij has no "at the edge" query, so moves compare the focused pane
+// before and after "zellij action move-focus", the same way tmuxTryPaneMove
+// compares pane ids. The focused panes come from "zellij action
+// list-clients" (zellij 0.40 or later).
+
+// zellijMaxLandSteps bounds the moves landing makes to reach the edge pane.
+const zellijMaxLandSteps = 16
+
+// inZellij reports whether ttyhop runs inside a zellij session.
+func inZellij() bool {
+	return os.Getenv("ZELLIJ") != "" || os.Getenv("ZELLIJ_SESSION_NAME") != ""
+}
+
+// runZellij runs one zellij command against session, or against the
+// session ttyhop runs in when session is "".
+func runZellij(session string, args ...string) (string, error) {
+	if session != "" {
+		args = append([]string{"--session", session}, args...)
+	}
+	cmd := exec.Command("zellij", args...)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err := cmd.Run(); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out.String()), nil
+}
+
+// zellijFocus returns the panes session's clients have focused, e.g.
+// "terminal_3", one per client. It is "" when no client is attached.
+func zellijFocus(session string) (string, error) {
+	out, err := runZellij(session, "action", "list-clients")
+	if err != nil {
+		return "", err
+	}
+	var panes []string
+	for _, ln := range strings.Split(out, "\n") {
+		f := strings.Fields(ln)
+		if len(f) < 2 || f[0] == "CLIENT_ID" {
+			continue
+		}
+		panes = append(panes, f[1])
+	}
+	return strings.Join(panes, " "), nil
+}
+
+// zellijDirection is the move-focus argument for dir.
+func zellijDirection(dir Direction) string {
+	return map[Direction]string{DirLeft: "left", DirRight: "right", DirUp: "up", DirDown: "down"}[dir]
+}
+
+// zellijTryMove moves focus to the zellij pane next to the focused one in
+// dir; return true if moved. move-focus does nothing at the edge, so an
+// unchanged focus means the caller should hop windows.
+func zellijTryMove(dir Direction) bool {
+	if !inZellij() {
+		return false
+	}
+	before, err := zellijFocus("")
+	if err != nil || before == "" {
+		dbg("zellij: no focused pane (%v)", err)
+		return false
+	}
+	if _, err := runZellij("", "action", "move-focus", zellijDirection(dir)); err != nil {
+		return false
+	}
+	after, _ := zellijFocus("")
+	if after == "" || after == before {
+		dbg("zellij: at %s edge", dir)
+		return false
+	}
+	dbg("zellij: pane move %s (%s -> %s)", dir, before, after)
+	return true
+}
+
+// zellijTitleSession returns the session a window shows, from the title
+// zellij gives the terminal: "Zellij (NAME) - pane title". It is "" for
+// any other title.
+func zellijTitleSession(title string) string {
+	rest, ok := strings.CutPrefix(title, "Zellij (")
+	if !ok {
+		return ""
+	}
+	name, _, ok := strings.Cut(rest, ")")
+	if !ok {
+		return ""
+	}
+	return name
+}
+
+// zellijSelectEdgePane focuses the edge pane of the zellij session shown
+// in the window a hop landed on, named by its title: the LEFTMOST when
+// moving right, the TOPMOST when moving down, and so on. It moves back
+// until focus stops changing.
+func zellijSelectEdgePane(dir Direction, title string) {
+	session := zellijTitleSession(title)
+	if session == "" {
+		dbg("zellij: window title %q names no session; not landing", title)
+		return
+	}
+	prev, err := zellijFocus(session)
+	if err != nil || prev == "" {
+		dbg("zellij: session %s has no client (%v); not landing", session, err)
+		return
+	}
+	for i := 0; i < zellijMaxLandSteps; i++ {
+		if _, err := runZellij(session, "action", "move-focus", zellijDirection(dir.Opposite())); err != nil {
+			break
+		}
+		cur, err := zellijFocus(session)
+		if err != nil || cur == prev {
+			break
+		}
+		prev = cur
+	}
+	dbg("zellij: landed on %s edge pane %s (session %s)", dir.Opposite(), prev, session)
+}
+
+// zellijNavigator moves between zellij panes. Landing reads the session
+// from the title of the window hopped to.
+type zellijNavigator struct {
+	title string
+}
+
+func (n *zellijNavigator) Try(dir Direction) bool { return zellijTryMove(dir) }
+
+func (n *zellijNavigator) recordOrigin(_ Direction, _ neighbor.Rect, dest neighbor.Candidate) {
+	n.title = dest.Title
+}
+
+func (n *zellijNavigator) LandAtEdge(dir Direction) { zellijSelectEdgePane(dir, n.title) }
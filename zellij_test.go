@@ -0,0 +1,128 @@
+// Copyright (c) 2025 Lee Jones
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fakeZellijScript stands in for zellij, after fakeMuxShell. "action
+// list-clients" lists one client on the focused pane, or none for a
+// session with $dir/S.detached, and "action move-focus" moves it.
+const fakeZellijScript = `s=$ZELLIJ_SESSION_NAME
+if [ "$1" = "--session" ]; then s=$2; shift 2; fi
+[ "$1" = action ] || exit 1
+[ -f "$d/$s.focus" ] || { echo "There is no active session!" >&2; exit 1; }
+cur=$(cat "$d/$s.focus")
+case "$2" in
+list-clients)
+  echo "CLIENT_ID ZELLIJ_PANE_ID RUNNING_COMMAND"
+  [ -f "$d/$s.detached" ] || echo "1         $cur         zsh" ;;
+move-focus) move_focus "$3" ;;
+*) exit 1 ;;
+esac
+`
+
+// fakeZellij puts a fake zellij first on $PATH, running in session "main"
+// with panes terminal_0 | terminal_1 focused on focus. Session "side"
+// holds terminal_0 | terminal_1 | terminal_2 focused on its middle pane,
+// and "old" is detached. It returns the fake's directory.
+func fakeZellij(t *testing.T, focus string) string {
+	t.Helper()
+	dir := fakeMux(t, "zellij", fakeZellijScript,
+		fakeSession{"main", focus, []string{"terminal_0", "terminal_1"}},
+		fakeSession{"side", "terminal_1", []string{"terminal_0", "terminal_1", "terminal_2"}},
+		fakeSession{"old", "terminal_0", []string{"terminal_0", "terminal_1"}})
+	writeFile(t, filepath.Join(dir, "old.detached"), "", 0o644)
+	t.Setenv("ZELLIJ", "0")
+	t.Setenv("ZELLIJ_SESSION_NAME", "main")
+	return dir
+}
+
+func TestZellijTryMove(t *testing.T) {
+	tests := []struct {
+		name  string
+		focus string
+		dir   Direction
+		moved bool
+		want  string
+	}{
+		{"Right", "terminal_0", DirRight, true, "terminal_1"},
+		{"AtRightEdge", "terminal_1", DirRight, false, "terminal_1"},
+		{"Left", "terminal_1", DirLeft, true, "terminal_0"},
+		{"AtTopEdge", "terminal_0", DirUp, false, "terminal_0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := fakeZellij(t, tt.focus)
+			if moved := zellijTryMove(tt.dir); moved != tt.moved {
+				t.Errorf("zellijTryMove(%s) = %v, want %v", tt.dir, moved, tt.moved)
+			}
+			if got := readFile(t, filepath.Join(dir, "main.focus")); got != tt.want {
+				t.Errorf("focused pane %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("OutsideZellij", func(t *testing.T) {
+		dir := fakeZellij(t, "terminal_0")
+		t.Setenv("ZELLIJ", "")
+		t.Setenv("ZELLIJ_SESSION_NAME", "")
+		if zellijTryMove(DirRight) {
+			t.Error("expected no move outside zellij")
+		}
+		if _, err := os.Stat(filepath.Join(dir, "log")); err == nil {
+			t.Error("expected zellij not to be run outside zellij")
+		}
+	})
+}
+
+func TestZellijSelectEdgePane(t *testing.T) {
+	t.Run("MovingRightLandsLeftmost", func(t *testing.T) {
+		dir := fakeZellij(t, "terminal_1")
+		zellijSelectEdgePane(DirRight, "Zellij (side) - zsh")
+		if got := readFile(t, filepath.Join(dir, "side.focus")); got != "terminal_0" {
+			t.Errorf("side focused %s, want terminal_0", got)
+		}
+		if got := readFile(t, filepath.Join(dir, "main.focus")); got != "terminal_1" {
+			t.Errorf("expected ttyhop's own session to keep its focus, got %s", got)
+		}
+	})
+
+	t.Run("MovingLeftLandsRightmost", func(t *testing.T) {
+		dir := fakeZellij(t, "terminal_0")
+		zellijSelectEdgePane(DirLeft, "Zellij (side) - zsh")
+		if got := readFile(t, filepath.Join(dir, "side.focus")); got != "terminal_2" {
+			t.Errorf("side focused %s, want terminal_2", got)
+		}
+	})
+
+	t.Run("FromOutsideZellij", func(t *testing.T) {
+		dir := fakeZellij(t, "terminal_0")
+		t.Setenv("ZELLIJ", "")
+		t.Setenv("ZELLIJ_SESSION_NAME", "")
+		zellijSelectEdgePane(DirRight, "Zellij (side) - vim")
+		if got := readFile(t, filepath.Join(dir, "side.focus")); got != "terminal_0" {
+			t.Errorf("side focused %s, want terminal_0", got)
+		}
+	})
+
+	for _, tt := range []struct{ name, title string }{
+		{"OtherWindow", "zsh"},
+		{"DetachedSession", "Zellij (old) - zsh"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := fakeZellij(t, "terminal_0")
+			zellijSelectEdgePane(DirRight, tt.title)
+			if got := readFile(t, filepath.Join(dir, "side.focus")); got != "terminal_1" {
+				t.Errorf("expected no landing, side focused %s", got)
+			}
+			if got := readFile(t, filepath.Join(dir, "old.focus")); got != "terminal_0" {
+				t.Errorf("expected no landing, old focused %s", got)
+			}
+		})
+	}
+}